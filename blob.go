@@ -1,14 +1,27 @@
 package nuxeo
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"io"
 	"iter"
+	"mime"
 	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
 	"strconv"
 
 	"github.com/anselm94/nuxeo-go-client/internal"
 )
 
+// ErrBlobNotReopenable is returned when a blob's stream cannot be rewound to its beginning.
+var ErrBlobNotReopenable = errors.New("blob is not reopenable")
+
+// sniffLength is the maximum number of bytes considered by http.DetectContentType.
+const sniffLength = 512
+
 // blob represents a binary object in Nuxeo, typically used for file uploads and document properties.
 // Fields map to Nuxeo's blob JSON structure.
 //
@@ -35,6 +48,11 @@ type blob struct {
 	Data string `json:"data"`
 	// (Readonly) Blob URL
 	BlobUrl string `json:"blobUrl"`
+
+	// open returns a fresh stream positioned at the start of the blob, if supported
+	open func() (io.ReadCloser, error)
+	// cleanup releases resources backing the blob (e.g. spooled temp files)
+	cleanup func() error
 }
 
 // blobs reads blobs from a multipart.Reader and returns them as an iter.Seq[blob].
@@ -78,3 +96,275 @@ func (b *blob) Size() int64 {
 	}
 	return size
 }
+
+// NewBlobFromFile creates a new re-openable Blob from the file at the given path.
+// The MIME type is detected from the file extension, falling back to content sniffing, and the length from the file size.
+func NewBlobFromFile(path string) (*blob, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("failed to create blob: %s is a directory", path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	head, err := readHead(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to rewind file: %w", err)
+	}
+
+	blob := NewBlob(filepath.Base(path), detectMimeType(path, head), info.Size(), file)
+	blob.open = func() (io.ReadCloser, error) {
+		return os.Open(path)
+	}
+	return blob, nil
+}
+
+// NewBlobFromBytes creates a new re-openable Blob holding the given data.
+// The MIME type is detected from the name's extension, falling back to content sniffing.
+func NewBlobFromBytes(name string, data []byte) *blob {
+	blob := NewBlob(name, detectMimeType(name, data), int64(len(data)), io.NopCloser(bytes.NewReader(data)))
+	blob.open = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	return blob
+}
+
+// BlobReaderOptions configures how NewBlobFromReader consumes a reader.
+type BlobReaderOptions struct {
+	// MimeType overrides MIME type detection when set
+	MimeType string
+	// SpoolToTempFile copies readers of unknown length into a temporary file, so the length is known and the blob is re-openable
+	SpoolToTempFile bool
+	// TempDir is the directory used for spooling; defaults to os.TempDir()
+	TempDir string
+}
+
+// NewBlobFromReader creates a new Blob reading from r.
+//
+// The MIME type is detected from the name's extension, falling back to content sniffing. The length is computed
+// when r exposes it (e.g. *bytes.Reader, *strings.Reader, *os.File or any io.Seeker), otherwise it is reported as -1
+// unless options.SpoolToTempFile is set. Seekable readers and spooled readers produce re-openable blobs.
+// If r implements io.Closer, it is closed when the blob is closed.
+func NewBlobFromReader(name string, r io.Reader, options *BlobReaderOptions) (*blob, error) {
+	if options == nil {
+		options = &BlobReaderOptions{}
+	}
+
+	length := readerLength(r)
+
+	// spool readers of unknown length to a temp file
+	if length < 0 && options.SpoolToTempFile {
+		return spoolBlob(name, r, options)
+	}
+
+	// seekable readers can be rewound for sniffing and reopening
+	if seeker, ok := r.(io.ReadSeeker); ok {
+		if blob, err := seekableBlob(name, seeker, length, options); err == nil {
+			return blob, nil
+		}
+	}
+
+	// otherwise, sniff the head and replay it in front of the remaining stream
+	head, err := readHead(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reader: %w", err)
+	}
+	mimeType := options.MimeType
+	if mimeType == "" {
+		mimeType = detectMimeType(name, head)
+	}
+	stream := struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), r), readCloserOf(r)}
+	return NewBlob(name, mimeType, length, stream), nil
+}
+
+// seekableBlob creates a re-openable Blob from a seekable reader, rewinding it to its current offset on reopen.
+// The reader is closed when the blob is closed, if it implements io.Closer.
+func seekableBlob(name string, seeker io.ReadSeeker, length int64, options *BlobReaderOptions) (*blob, error) {
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to seek reader: %w", err)
+	}
+	head, err := readHead(seeker)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reader: %w", err)
+	}
+	if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to rewind reader: %w", err)
+	}
+
+	mimeType := options.MimeType
+	if mimeType == "" {
+		mimeType = detectMimeType(name, head)
+	}
+	blob := NewBlob(name, mimeType, length, io.NopCloser(seeker))
+	blob.open = func() (io.ReadCloser, error) {
+		if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(seeker), nil
+	}
+	if closer, ok := seeker.(io.Closer); ok {
+		blob.cleanup = closer.Close
+	}
+	return blob, nil
+}
+
+// spoolBlob copies r into a temporary file and returns a re-openable Blob backed by it.
+// The temporary file is removed when the blob is closed.
+func spoolBlob(name string, r io.Reader, options *BlobReaderOptions) (*blob, error) {
+	tempFile, err := os.CreateTemp(options.TempDir, "nuxeo-blob-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tempPath := tempFile.Name()
+	cleanup := func() error {
+		return os.Remove(tempPath)
+	}
+
+	length, err := io.Copy(tempFile, r)
+	if closer, ok := r.(io.Closer); ok {
+		closer.Close()
+	}
+	if err == nil {
+		_, err = tempFile.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		tempFile.Close()
+		cleanup()
+		return nil, fmt.Errorf("failed to spool reader: %w", err)
+	}
+
+	head, err := readHead(tempFile)
+	if err == nil {
+		_, err = tempFile.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		tempFile.Close()
+		cleanup()
+		return nil, fmt.Errorf("failed to read spooled file: %w", err)
+	}
+
+	mimeType := options.MimeType
+	if mimeType == "" {
+		mimeType = detectMimeType(name, head)
+	}
+	blob := NewBlob(name, mimeType, length, tempFile)
+	blob.open = func() (io.ReadCloser, error) {
+		return os.Open(tempPath)
+	}
+	blob.cleanup = cleanup
+	return blob, nil
+}
+
+// IsReopenable returns true if the blob stream can be rewound with Reopen.
+func (b *blob) IsReopenable() bool {
+	return b.open != nil
+}
+
+// Reopen closes the current stream and replaces it with a fresh one positioned at the start of the blob.
+// Returns ErrBlobNotReopenable if the blob was not created from a re-openable source.
+func (b *blob) Reopen() error {
+	if b.open == nil {
+		return ErrBlobNotReopenable
+	}
+	if b.ReadCloser != nil {
+		b.ReadCloser.Close()
+	}
+	stream, err := b.open()
+	if err != nil {
+		return fmt.Errorf("failed to reopen blob: %w", err)
+	}
+	b.ReadCloser = stream
+	return nil
+}
+
+// Close closes the blob stream and releases any resources backing the blob, such as spooled temp files.
+func (b *blob) Close() error {
+	var err error
+	if b.ReadCloser != nil {
+		err = b.ReadCloser.Close()
+	}
+	if b.cleanup != nil {
+		err = errors.Join(err, b.cleanup())
+		b.cleanup = nil
+	}
+	return err
+}
+
+// detectMimeType returns the MIME type for the given file name, falling back to sniffing the content head.
+func detectMimeType(name string, head []byte) string {
+	if mimeType := mime.TypeByExtension(filepath.Ext(name)); mimeType != "" {
+		if mediaType, _, err := mime.ParseMediaType(mimeType); err == nil {
+			return mediaType
+		}
+		return mimeType
+	}
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return internal.HeaderValueOctetStream
+	}
+	return mediaType
+}
+
+// readHead reads up to sniffLength bytes from r for content sniffing.
+func readHead(r io.Reader) ([]byte, error) {
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(r, head)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	return head[:n], err
+}
+
+// readerLength returns the number of remaining bytes in r, or -1 if it cannot be determined.
+func readerLength(r io.Reader) int64 {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len())
+	case *os.File:
+		info, err := v.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		offset, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return info.Size() - offset
+	case io.Seeker:
+		offset, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		end, err := v.Seek(0, io.SeekEnd)
+		if err != nil {
+			return -1
+		}
+		if _, err := v.Seek(offset, io.SeekStart); err != nil {
+			return -1
+		}
+		return end - offset
+	}
+	return -1
+}
+
+// readCloserOf returns r as an io.ReadCloser, closing r only if it implements io.Closer.
+func readCloserOf(r io.Reader) io.ReadCloser {
+	if rc, ok := r.(io.ReadCloser); ok {
+		return rc
+	}
+	return io.NopCloser(r)
+}
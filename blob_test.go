@@ -1,7 +1,11 @@
 package nuxeo
 
 import (
+	"bytes"
+	"errors"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -84,3 +88,176 @@ func TestNewBlob(t *testing.T) {
 		})
 	}
 }
+
+func TestNewBlobFromBytes(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		filename string
+		data     []byte
+		wantMime string
+	}{
+		{name: "extension", filename: "doc.pdf", data: []byte("not really a pdf"), wantMime: "application/pdf"},
+		{name: "sniffed png", filename: "image", data: []byte("\x89PNG\r\n\x1a\n0000"), wantMime: "image/png"},
+		{name: "sniffed text", filename: "notes", data: []byte("hello world"), wantMime: "text/plain"},
+		{name: "empty", filename: "empty", data: nil, wantMime: "text/plain"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b := NewBlobFromBytes(tc.filename, tc.data)
+			if b.Filename != tc.filename {
+				t.Errorf("Filename: got %q, want %q", b.Filename, tc.filename)
+			}
+			if b.MimeType != tc.wantMime {
+				t.Errorf("MimeType: got %q, want %q", b.MimeType, tc.wantMime)
+			}
+			if b.Size() != int64(len(tc.data)) {
+				t.Errorf("Size: got %d, want %d", b.Size(), len(tc.data))
+			}
+			if !b.IsReopenable() {
+				t.Errorf("expected blob to be reopenable")
+			}
+		})
+	}
+}
+
+func TestNewBlobFromFile(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "report.txt")
+	if err := os.WriteFile(path, []byte("file data"), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	b, err := NewBlobFromFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer b.Close()
+	if b.Filename != "report.txt" {
+		t.Errorf("Filename: got %q, want %q", b.Filename, "report.txt")
+	}
+	if b.MimeType != "text/plain" {
+		t.Errorf("MimeType: got %q, want %q", b.MimeType, "text/plain")
+	}
+	if b.Size() != 9 {
+		t.Errorf("Size: got %d, want 9", b.Size())
+	}
+	for i := range 2 {
+		data, err := io.ReadAll(b)
+		if err != nil {
+			t.Fatalf("read %d: unexpected error: %v", i, err)
+		}
+		if string(data) != "file data" {
+			t.Errorf("read %d: got %q, want %q", i, data, "file data")
+		}
+		if err := b.Reopen(); err != nil {
+			t.Fatalf("reopen %d: unexpected error: %v", i, err)
+		}
+	}
+
+	if _, err := NewBlobFromFile(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Errorf("expected error for missing file, got nil")
+	}
+	if _, err := NewBlobFromFile(t.TempDir()); err == nil {
+		t.Errorf("expected error for directory, got nil")
+	}
+}
+
+func TestNewBlobFromReader(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name           string
+		reader         func() io.Reader
+		options        *BlobReaderOptions
+		wantMime       string
+		wantSize       int64
+		wantReopenable bool
+	}{
+		{
+			name:           "strings reader",
+			reader:         func() io.Reader { return strings.NewReader("<html><body>hi</body></html>") },
+			wantMime:       "text/html",
+			wantSize:       28,
+			wantReopenable: true,
+		},
+		{
+			name:           "unknown length",
+			reader:         func() io.Reader { return &dummyReadCloser{io.LimitReader(strings.NewReader("streamed"), 100)} },
+			wantMime:       "text/plain",
+			wantSize:       -1,
+			wantReopenable: false,
+		},
+		{
+			name:           "unknown length spooled",
+			reader:         func() io.Reader { return &dummyReadCloser{io.LimitReader(strings.NewReader("streamed"), 100)} },
+			options:        &BlobReaderOptions{SpoolToTempFile: true},
+			wantMime:       "text/plain",
+			wantSize:       8,
+			wantReopenable: true,
+		},
+		{
+			name:           "mime override",
+			reader:         func() io.Reader { return strings.NewReader("{}") },
+			options:        &BlobReaderOptions{MimeType: "application/json"},
+			wantMime:       "application/json",
+			wantSize:       2,
+			wantReopenable: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := NewBlobFromReader("content", tc.reader(), tc.options)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer b.Close()
+			if b.MimeType != tc.wantMime {
+				t.Errorf("MimeType: got %q, want %q", b.MimeType, tc.wantMime)
+			}
+			if b.Size() != tc.wantSize {
+				t.Errorf("Size: got %d, want %d", b.Size(), tc.wantSize)
+			}
+			if b.IsReopenable() != tc.wantReopenable {
+				t.Errorf("IsReopenable: got %v, want %v", b.IsReopenable(), tc.wantReopenable)
+			}
+			first, err := io.ReadAll(b)
+			if err != nil {
+				t.Fatalf("unexpected read error: %v", err)
+			}
+			if err := b.Reopen(); tc.wantReopenable && err != nil {
+				t.Fatalf("unexpected reopen error: %v", err)
+			} else if !tc.wantReopenable {
+				if !errors.Is(err, ErrBlobNotReopenable) {
+					t.Errorf("expected ErrBlobNotReopenable, got %v", err)
+				}
+				return
+			}
+			second, _ := io.ReadAll(b)
+			if !bytes.Equal(first, second) {
+				t.Errorf("reopened content: got %q, want %q", second, first)
+			}
+		})
+	}
+}
+
+func TestBlob_CloseRemovesSpooledFile(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	b, err := NewBlobFromReader("content", &dummyReadCloser{io.LimitReader(strings.NewReader("data"), 10)}, &BlobReaderOptions{SpoolToTempFile: true, TempDir: dir})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 spooled file, got %d", len(entries))
+	}
+	if err := b.Close(); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+	entries, _ = os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("expected spooled file to be removed, got %d entries", len(entries))
+	}
+}
@@ -81,6 +81,26 @@ const (
 	AclInherit = "inherited"
 )
 
+const (
+	AceStatusEffective = "effective"
+	AceStatusPending   = "pending"
+	AceStatusArchived  = "archived"
+)
+
+const (
+	// GroupEveryone is the group every user implicitly belongs to
+	GroupEveryone = "Everyone"
+)
+
+const (
+	PermissionRead       = "Read"
+	PermissionWrite      = "Write"
+	PermissionReadWrite  = "ReadWrite"
+	PermissionReadRemove = "ReadRemove"
+	PermissionRemove     = "Remove"
+	PermissionEverything = "Everything"
+)
+
 //////////////////
 //// Document ////
 //////////////////
@@ -89,6 +109,15 @@ const (
 	DocumentStateDeleted = "deleted"
 )
 
+// Types
+
+const (
+	DocumentTypeFile          = "File"
+	DocumentTypeFolder        = "Folder"
+	DocumentTypeWorkspace     = "Workspace"
+	DocumentTypeWorkspaceRoot = "WorkspaceRoot"
+)
+
 // Properties: Dublincore
 
 const (
@@ -125,12 +154,84 @@ const (
 	DocumentPropertyFileContent = "file:content"
 )
 
+// Properties: Files
+
+const (
+	DocumentPropertyFilesFiles = "files:files"
+)
+
+// Properties: Notification
+
+const (
+	DocumentPropertyNotifNotifications = "notif:notifications"
+	// DocumentSchemaNotification is the schema holding the notif:notifications property
+	DocumentSchemaNotification = "notification"
+	// DocumentFacetNotifiable is the facet bearing the notification schema
+	DocumentFacetNotifiable = "Notifiable"
+)
+
+// Notifications
+
+const (
+	NotificationModification             = "Modification"
+	NotificationCreation                 = "Creation"
+	NotificationWorkflowChange           = "Workflow Change"
+	NotificationApprobationReviewStarted = "Approbation review started"
+)
+
+// Properties: Picture
+
+const (
+	DocumentPropertyPictureViews = "picture:views"
+	DocumentPropertyPictureInfo  = "picture:info"
+	// DocumentSchemaPicture is the schema holding the picture:views and picture:info properties
+	DocumentSchemaPicture = "picture"
+)
+
+// Picture Views
+
+const (
+	PictureViewThumbnail    = "Thumbnail"
+	PictureViewSmall        = "Small"
+	PictureViewMedium       = "Medium"
+	PictureViewFullHD       = "FullHD"
+	PictureViewOriginalJpeg = "OriginalJpeg"
+)
+
+// Properties: Image Metadata
+
+const (
+	// DocumentSchemaImageMetadata is the schema holding the EXIF/IPTC metadata extracted from pictures, with the
+	// "imd" prefix
+	DocumentSchemaImageMetadata = "image_metadata"
+	documentPrefixImageMetadata = "imd:"
+)
+
+// Properties: Tags
+
+const (
+	DocumentPropertyNxtagTags = "nxtag:tags"
+	// DocumentSchemaTags is the schema holding the nxtag:tags property
+	DocumentSchemaTags = "facetedTag"
+)
+
 // Properties: Thumb
 
 const (
 	DocumentPropertyThumbThumbnail = "thumb:thumbnail"
 )
 
+// Versioning Options
+
+// VersioningOption selects how a document version is incremented when it is saved or checked in.
+type VersioningOption string
+
+const (
+	VersioningOptionNone  VersioningOption = "none"
+	VersioningOptionMinor VersioningOption = "minor"
+	VersioningOptionMajor VersioningOption = "major"
+)
+
 ///////////////////
 //// Directory ////
 ///////////////////
@@ -142,26 +243,64 @@ const (
 	DirectoryPropertyObsolete = "obsolete"
 )
 
+//////////////////////
+//// Batch Upload ////
+//////////////////////
+
+const (
+	BatchHandlerDefault = "default"
+	BatchHandlerS3      = "s3"
+)
+
 ////////////////////
 //// Operations ////
 ////////////////////
 
 const (
-	OperationBlobAttachOnDocument       = "Blob.AttachOnDocument"
-	OperationDirectoryEntries           = "Directory.Entries"
-	OperationDocumentAddPermission      = "Document.AddPermission"
-	OperationDocumentRemovePermission   = "Document.RemovePermission"
-	OperationDocumentRemoveProxies      = "Document.RemoveProxies"
-	OperationDocumentCheckIn            = "Document.CheckIn"
-	OperationDocumentGetLastVersion     = "Document.GetLastVersion"
-	OperationDocumentGetBlob            = "Document.GetBlob"
-	OperationDocumentGetBlobs           = "Document.GetBlobs"
-	OperationDocumentGetBlobsByProperty = "Document.GetBlobsByProperty"
-	OperationDocumentTrash              = "Document.Trash"
-	OperationDocumentUntrash            = "Document.Untrash"
-	OperationDocumentUpdate             = "Document.Update"
-	OperationEsWaitForIndexing          = "Elasticsearch.WaitForIndexing"
-	OperationRepositoryGetDocument      = "Repository.GetDocument"
+	OperationBlobAttachOnDocument         = "Blob.AttachOnDocument"
+	OperationCollectionCreate             = "Collection.Create"
+	OperationDirectoryEntries             = "Directory.Entries"
+	OperationDocumentAddFacet             = "Document.AddFacet"
+	OperationDocumentAddPermission        = "Document.AddPermission"
+	OperationDocumentAddToCollection      = "Document.AddToCollection"
+	OperationDocumentAddToFavorites       = "Document.AddToFavorites"
+	OperationDocumentBlockInheritance     = "Document.BlockPermissionInheritance"
+	OperationDocumentRemoveACL            = "Document.RemoveACL"
+	OperationDocumentRemoveFromCollection = "Document.RemoveFromCollection"
+	OperationDocumentRemoveFromFavorites  = "Document.RemoveFromFavorites"
+	OperationDocumentRemovePermission     = "Document.RemovePermission"
+	OperationDocumentRemoveProxies        = "Document.RemoveProxies"
+	OperationDocumentRestoreVersion       = "Document.RestoreVersion"
+	OperationDocumentSetLegalHold         = "Document.SetLegalHold"
+	OperationDocumentSetRetention         = "Document.SetRetention"
+	OperationDocumentCheckIn              = "Document.CheckIn"
+	OperationDocumentCheckOut             = "Document.CheckOut"
+	OperationDocumentCopy                 = "Document.Copy"
+	OperationDocumentFollowTransition     = "Document.FollowLifecycleTransition"
+	OperationDocumentGetLastVersion       = "Document.GetLastVersion"
+	OperationDocumentGetBlob              = "Document.GetBlob"
+	OperationDocumentGetBlobs             = "Document.GetBlobs"
+	OperationDocumentGetBlobsByProperty   = "Document.GetBlobsByProperty"
+	OperationDocumentLock                 = "Document.Lock"
+	OperationDocumentMakeRecord           = "Document.MakeRecord"
+	OperationDocumentMove                 = "Document.Move"
+	OperationDocumentPublishToSection     = "Document.PublishToSection"
+	OperationDocumentSubscribe            = "Document.Subscribe"
+	OperationDocumentTrash                = "Document.Trash"
+	OperationDocumentUnblockInheritance   = "Document.UnblockPermissionInheritance"
+	OperationDocumentUnlock               = "Document.Unlock"
+	OperationDocumentUnsubscribe          = "Document.Unsubscribe"
+	OperationDocumentUntrash              = "Document.Untrash"
+	OperationDocumentUpdate               = "Document.Update"
+	OperationEsWaitForIndexing            = "Elasticsearch.WaitForIndexing"
+	OperationFavoriteFetch                = "Favorite.Fetch"
+	OperationFileManagerImport            = "FileManager.Import"
+	OperationRepositoryGetDocument        = "Repository.GetDocument"
+	OperationRepositoryResultSetQuery     = "Repository.ResultSetQuery"
+	OperationRetentionAttachRule          = "Retention.AttachRule"
+	OperationServicesTagDocument          = "Services.TagDocument"
+	OperationServicesUntagDocument        = "Services.UntagDocument"
+	OperationTagSuggestion                = "Tag.Suggestion"
 )
 
 /////////////////////////
@@ -170,6 +309,10 @@ const (
 
 // Fetch Properties
 
+const (
+	FetchPropertyCommentRepliesSummary = "repliesSummary"
+)
+
 const (
 	FetchPropertyDirectoryEntryParent = "parent"
 )
@@ -182,6 +325,7 @@ const (
 	FetchPropertyDocumentDCSubjects        = "dc:subjects"
 	FetchPropertyDocumentDCCoverage        = "dc:coverage"
 	FetchPropertyDocumentDCNature          = "dc:nature"
+	FetchPropertyDocumentLock              = "lock"
 )
 
 const (
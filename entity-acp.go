@@ -1,5 +1,11 @@
 package nuxeo
 
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
 // ACP represents an Access Control Policy (ACP) for a Nuxeo document.
 // It contains a list of ACLs (Access Control Lists) that define permissions for users and groups.
 type ACP struct {
@@ -17,6 +23,18 @@ func NewACP() *ACP {
 	}
 }
 
+// aclOf returns the name of the ACL holding the ACE with the given ID, or an empty string if none holds it.
+func (acp *ACP) aclOf(aceId string) string {
+	for _, acl := range acp.ACLs {
+		for _, ace := range acl.ACEs {
+			if ace.ID == aceId {
+				return acl.Name
+			}
+		}
+	}
+	return ""
+}
+
 // ACL represents an Access Control List (ACL) within an ACP.
 // An ACL contains a name and a list of ACEs (Access Control Entries).
 type ACL struct {
@@ -52,3 +70,96 @@ func NewACE(username, permission string, granted bool) *ACE {
 		Granted:    granted,
 	}
 }
+
+// PermissionHierarchy maps compound permissions to the permissions they include, e.g. ReadWrite to Read and Write.
+// Everything includes any permission. The hierarchy must be acyclic.
+type PermissionHierarchy map[string][]string
+
+// defaultPermissionHierarchy is the permission hierarchy of a default Nuxeo server.
+var defaultPermissionHierarchy = PermissionHierarchy{
+	PermissionReadWrite:  {PermissionRead, PermissionWrite},
+	PermissionReadRemove: {PermissionRead, PermissionRemove},
+	PermissionRead:       {"Browse", "ReadVersion", "ReadProperties", "ReadChildren", "ReadLifeCycle", "ReviewParticipant", "ReadSecurity"},
+	PermissionWrite:      {"AddChildren", "RemoveChildren", PermissionRemove, "WriteProperties", "WriteLifeCycle", "WriteVersion", "Version"},
+}
+
+// DefaultPermissionHierarchy returns a copy of the permission hierarchy of a default Nuxeo server.
+func DefaultPermissionHierarchy() PermissionHierarchy {
+	hierarchy := make(PermissionHierarchy, len(defaultPermissionHierarchy))
+	for permission, included := range defaultPermissionHierarchy {
+		hierarchy[permission] = slices.Clone(included)
+	}
+	return hierarchy
+}
+
+// Implies returns true if holding the held permission grants the requested one, directly or through the hierarchy.
+func (h PermissionHierarchy) Implies(held string, requested string) bool {
+	if held == requested || held == PermissionEverything {
+		return true
+	}
+	for _, included := range h[held] {
+		if h.Implies(included, requested) {
+			return true
+		}
+	}
+	return false
+}
+
+// PermissionDecision is the outcome of the evaluation of a permission against an ACP, with the ACE which decided it.
+type PermissionDecision struct {
+	Permission string
+	Granted    bool
+	// ACL is the name of the ACL holding the deciding ACE, if any
+	ACL string
+	// ACE is the deciding ACE, or nil when no ACE applies, which denies the permission
+	ACE *ACE
+	// Reason explains the decision
+	Reason string
+}
+
+// Evaluate decides whether the principals, a username along with all its groups, hold the permission at the given time.
+//
+// Like the server, the ACLs are walked in order, local ACLs before inherited ones, and the first ACE applying to one
+// of the principals with a permission implying the requested one decides, be it a grant or a denial. ACEs outside of
+// their Begin/End window, or pending or archived when they have no window, do not apply. A denial of Everything to
+// Everyone blocks the inheritance of the following ACLs. The Everyone group is always among the principals. A nil
+// hierarchy uses DefaultPermissionHierarchy.
+func (acp *ACP) Evaluate(principals []string, permission string, hierarchy PermissionHierarchy, at time.Time) *PermissionDecision {
+	if hierarchy == nil {
+		hierarchy = defaultPermissionHierarchy
+	}
+	for _, acl := range acp.ACLs {
+		for i := range acl.ACEs {
+			ace := &acl.ACEs[i]
+			if !ace.isEffective(at) || !hierarchy.Implies(ace.Permission, permission) {
+				continue
+			}
+			if ace.Username != GroupEveryone && !slices.Contains(principals, ace.Username) {
+				continue
+			}
+			decision := &PermissionDecision{Permission: permission, Granted: ace.Granted, ACL: acl.Name, ACE: ace}
+			switch {
+			case ace.Granted:
+				decision.Reason = fmt.Sprintf("%s granted to %s in ACL %s", ace.Permission, ace.Username, acl.Name)
+			case ace.Username == GroupEveryone && ace.Permission == PermissionEverything:
+				decision.Reason = fmt.Sprintf("inheritance blocked in ACL %s", acl.Name)
+			default:
+				decision.Reason = fmt.Sprintf("%s denied to %s in ACL %s", ace.Permission, ace.Username, acl.Name)
+			}
+			return decision
+		}
+	}
+	return &PermissionDecision{Permission: permission, Reason: fmt.Sprintf("no ACE grants %s", permission)}
+}
+
+// isEffective returns true if the ACE applies at the given time, according to its Begin/End window if any, or else to
+// its status.
+func (ace *ACE) isEffective(at time.Time) bool {
+	if ace.Begin == nil && ace.End == nil {
+		return ace.Status == "" || ace.Status == AceStatusEffective
+	}
+	if ace.Begin != nil && at.Before(time.Time(*ace.Begin)) {
+		return false
+	}
+	return ace.End == nil || at.Before(time.Time(*ace.End))
+}
@@ -2,6 +2,7 @@ package nuxeo
 
 import (
 	"testing"
+	"time"
 )
 
 func TestNewACP(t *testing.T) {
@@ -64,3 +65,88 @@ func TestNewACE(t *testing.T) {
 		t.Errorf("Status: got %q, want empty", ace.Status)
 	}
 }
+
+func TestPermissionHierarchy_Implies(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		held, requested string
+		want            bool
+	}{
+		{held: PermissionRead, requested: PermissionRead, want: true},
+		{held: PermissionEverything, requested: "AnyCustomPermission", want: true},
+		{held: PermissionReadWrite, requested: "ReadChildren", want: true},
+		{held: PermissionReadWrite, requested: PermissionRemove, want: true},
+		{held: PermissionReadRemove, requested: PermissionWrite, want: false},
+		{held: PermissionRead, requested: PermissionWrite, want: false},
+		{held: PermissionWrite, requested: PermissionReadWrite, want: false},
+	}
+	for _, tt := range tests {
+		if got := DefaultPermissionHierarchy().Implies(tt.held, tt.requested); got != tt.want {
+			t.Errorf("Implies(%s, %s) = %t, want %t", tt.held, tt.requested, got, tt.want)
+		}
+	}
+}
+
+func TestACP_Evaluate(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC)
+	at := func(year int) *ISO8601Time {
+		date := ISO8601Time(time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC))
+		return &date
+	}
+	acp := &ACP{ACLs: []ACL{
+		{Name: AclLocal, ACEs: []ACE{
+			{ID: "jdoe-denied", Username: "jdoe", Permission: PermissionWrite},
+			{ID: "expired", Username: "temps", Permission: PermissionEverything, Granted: true, End: at(2030)},
+			{ID: "upcoming", Username: "temps", Permission: PermissionRead, Granted: true, Begin: at(2031)},
+			{ID: "archived", Username: "auditors", Permission: PermissionRead, Granted: true, Status: AceStatusArchived},
+			{ID: "window", Username: "auditors", Permission: PermissionRead, Granted: true, Begin: at(2030), End: at(2031), Status: AceStatusPending},
+			{ID: "editors", Username: "editors", Permission: PermissionReadWrite, Granted: true},
+		}},
+		{Name: "project", ACEs: []ACE{
+			{ID: "admins", Username: "Administrator", Permission: PermissionEverything, Granted: true},
+			{ID: "block", Username: GroupEveryone, Permission: PermissionEverything},
+		}},
+		{Name: AclInherit, ACEs: []ACE{
+			{ID: "members", Username: "members", Permission: PermissionRead, Granted: true},
+		}},
+	}}
+	tests := []struct {
+		name       string
+		principals []string
+		permission string
+		wantACE    string
+		wantGrant  bool
+	}{
+		{name: "group grant", principals: []string{"jdoe", "editors"}, permission: PermissionRead, wantACE: "editors", wantGrant: true},
+		{name: "deny before grant", principals: []string{"jdoe", "editors"}, permission: "WriteProperties", wantACE: "jdoe-denied"},
+		{name: "deny of an including permission", principals: []string{"jdoe", "editors"}, permission: PermissionRemove, wantACE: "jdoe-denied"},
+		{name: "expired and upcoming", principals: []string{"temp", "temps"}, permission: PermissionRead, wantACE: "block"},
+		{name: "time window over status", principals: []string{"audit", "auditors"}, permission: "Browse", wantACE: "window", wantGrant: true},
+		{name: "blocked inheritance", principals: []string{"alice", "members"}, permission: PermissionRead, wantACE: "block"},
+		{name: "grant before block", principals: []string{"Administrator"}, permission: PermissionRemove, wantACE: "admins", wantGrant: true},
+		{name: "no ACE", principals: []string{"bob"}, permission: "Custom", wantACE: "block"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := acp.Evaluate(tt.principals, tt.permission, nil, now)
+			if decision.Granted != tt.wantGrant || decision.ACE == nil || decision.ACE.ID != tt.wantACE {
+				t.Errorf("Evaluate() = %+v, want ACE %s granting %t", decision, tt.wantACE, tt.wantGrant)
+			}
+			if decision.Reason == "" {
+				t.Error("Evaluate() has no reason")
+			}
+		})
+	}
+
+	inherited := &ACP{ACLs: acp.ACLs[2:]}
+	if decision := inherited.Evaluate([]string{"alice", "members"}, PermissionRead, nil, now); !decision.Granted || decision.ACL != AclInherit {
+		t.Errorf("Evaluate() inherited = %+v", decision)
+	}
+	if decision := inherited.Evaluate([]string{"bob"}, PermissionRead, nil, now); decision.Granted || decision.ACE != nil {
+		t.Errorf("Evaluate() without ACE = %+v", decision)
+	}
+	if decision := inherited.Evaluate([]string{"alice", "members"}, "Browse", PermissionHierarchy{}, now); decision.Granted {
+		t.Errorf("Evaluate() with a flat hierarchy = %+v", decision)
+	}
+}
@@ -0,0 +1,42 @@
+package nuxeo
+
+// Annotation represents an annotation on a blob of a document, such as a highlight or a note on a PDF page.
+//
+// See: https://doc.nuxeo.com/rest-api/1/annotation-endpoint/
+// Annotation models the REST API 'annotation' entity-type.
+// Fields map directly to Nuxeo's JSON representation.
+type Annotation struct {
+	entity
+	Id string `json:"id,omitempty"`
+	// ParentId is the ID of the annotated document
+	ParentId string `json:"parentId,omitempty"`
+	// XPath is the xpath of the annotated blob, e.g. "file:content"
+	XPath            string       `json:"xpath"`
+	Author           string       `json:"author,omitempty"`
+	Text             string       `json:"text,omitempty"`
+	CreationDate     *ISO8601Time `json:"creationDate,omitempty"`
+	ModificationDate *ISO8601Time `json:"modificationDate,omitempty"`
+	// EntityId is the ID of the annotation in the client which created it, e.g. a PDF viewer
+	EntityId string `json:"entityId,omitempty"`
+	// Origin is the client which created the annotation
+	Origin string `json:"origin,omitempty"`
+	// Entity is the payload of the annotation in the format of its origin, e.g. XFDF or JSON
+	Entity string `json:"entity,omitempty"`
+}
+
+// NewAnnotation creates a new Annotation of the blob at the xpath of a document, carrying the entity payload of the
+// given origin, and sets the EntityType to 'annotation'.
+func NewAnnotation(documentId string, xpath string, origin string, payload string) *Annotation {
+	return &Annotation{
+		entity: entity{
+			EntityType: EntityTypeAnnotation,
+		},
+		ParentId: documentId,
+		XPath:    xpath,
+		Origin:   origin,
+		Entity:   payload,
+	}
+}
+
+// Annotations is a slice wrapper for multiple Annotation objects, as returned by the annotation endpoint.
+type Annotations entities[Annotation]
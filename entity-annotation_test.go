@@ -0,0 +1,18 @@
+package nuxeo
+
+import "testing"
+
+func TestNewAnnotation(t *testing.T) {
+	t.Parallel()
+
+	annotation := NewAnnotation("doc-123", "file:content", "pdfjs", "<xfdf/>")
+	if annotation.entity.EntityType != EntityTypeAnnotation {
+		t.Errorf("EntityType mismatch: got %q, want %q", annotation.entity.EntityType, EntityTypeAnnotation)
+	}
+	if annotation.ParentId != "doc-123" || annotation.XPath != "file:content" || annotation.Origin != "pdfjs" || annotation.Entity != "<xfdf/>" {
+		t.Errorf("unexpected annotation %+v", annotation)
+	}
+	if annotation.Id != "" || annotation.Author != "" || annotation.CreationDate != nil {
+		t.Errorf("expected server managed fields to be empty")
+	}
+}
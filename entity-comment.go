@@ -0,0 +1,42 @@
+package nuxeo
+
+// Comment represents a comment on a document, or a reply to another comment.
+//
+// See: https://doc.nuxeo.com/rest-api/1/comment-endpoint/
+// Comment models the REST API 'comment' entity-type.
+// Fields map directly to Nuxeo's JSON representation.
+type Comment struct {
+	entity
+	Id string `json:"id,omitempty"`
+	// ParentId is the ID of the commented document, or of the comment replied to
+	ParentId string `json:"parentId,omitempty"`
+	// AncestorIds are the IDs of the comments replied to, up to the commented document
+	AncestorIds      []string     `json:"ancestorIds,omitempty"`
+	Author           string       `json:"author,omitempty"`
+	Text             string       `json:"text"`
+	CreationDate     *ISO8601Time `json:"creationDate,omitempty"`
+	ModificationDate *ISO8601Time `json:"modificationDate,omitempty"`
+	NumberOfReplies  int          `json:"numberOfReplies,omitempty"`
+	LastReplyDate    *ISO8601Time `json:"lastReplyDate,omitempty"`
+}
+
+// NewComment creates a new Comment with the given text, replying to the parent document or comment, and sets the
+// EntityType to 'comment'.
+func NewComment(parentId string, text string) *Comment {
+	return &Comment{
+		entity: entity{
+			EntityType: EntityTypeComment,
+		},
+		ParentId: parentId,
+		Text:     text,
+	}
+}
+
+// Comments is a paginated list of Comment objects, as returned by the comment endpoint.
+type Comments paginableEntities[Comment]
+
+// CommentThread is a comment along with its replies, recursively.
+type CommentThread struct {
+	*Comment
+	Replies []*CommentThread
+}
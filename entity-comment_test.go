@@ -0,0 +1,25 @@
+package nuxeo
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNewComment(t *testing.T) {
+	t.Parallel()
+
+	comment := NewComment("doc-123", "looks good")
+	if comment.entity.EntityType != EntityTypeComment {
+		t.Errorf("EntityType mismatch: got %q, want %q", comment.entity.EntityType, EntityTypeComment)
+	}
+	if comment.ParentId != "doc-123" || comment.Text != "looks good" {
+		t.Errorf("unexpected comment %+v", comment)
+	}
+	data, err := json.Marshal(comment)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	if got := string(data); got != `{"entity-type":"comment","contextParameters":null,"parentId":"doc-123","text":"looks good"}` {
+		t.Errorf("Marshal = %s", got)
+	}
+}
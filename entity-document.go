@@ -117,6 +117,17 @@ func (d *Document) SetUploadInfoProperty(key string, infos ...UploadInfo) {
 	}
 }
 
+// SetUploadInfoListProperty sets the upload information for a list of files property such as "files:files",
+// wrapping each upload in a {"file": ...} item, even when a single upload is given.
+func (d *Document) SetUploadInfoListProperty(key string, infos ...UploadInfo) {
+	uploadInfos := make([]uploadFileInfo, len(infos))
+	for i, bi := range infos {
+		uploadInfos[i] = uploadFileInfo{File: bi}
+	}
+	uploadInfoFld, _ := NewComplexField(uploadInfos)
+	d.SetProperty(key, uploadInfoFld)
+}
+
 // Thumbnail returns the thumbnail Blob of the document, if present.
 func (d *Document) Thumbnail() *blob {
 	if fieldBlob, ok := d.Properties[DocumentPropertyThumbThumbnail]; ok {
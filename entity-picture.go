@@ -0,0 +1,202 @@
+package nuxeo
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PictureInfo describes the dimensions and format of a picture, or of one of its views.
+type PictureInfo struct {
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+	Depth      int    `json:"depth"`
+	Format     string `json:"format"`
+	ColorSpace string `json:"colorSpace"`
+}
+
+// PictureView is one of the resized views of a Picture document (e.g. "Thumbnail", "Small", "Medium", "FullHD"),
+// as stored in the picture:views property.
+type PictureView struct {
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	Tag         string      `json:"tag"`
+	Width       int         `json:"width"`
+	Height      int         `json:"height"`
+	Filename    string      `json:"filename"`
+	Content     *blob       `json:"content"`
+	Info        PictureInfo `json:"info"`
+}
+
+// ImageMetadata holds the EXIF/IPTC metadata extracted from a picture, as stored in the "image_metadata" schema.
+type ImageMetadata struct {
+	PixelXDimension   int          `json:"pixel_xdimension"`
+	PixelYDimension   int          `json:"pixel_ydimension"`
+	XResolution       int          `json:"xresolution"`
+	YResolution       int          `json:"yresolution"`
+	ColorSpace        string       `json:"color_space"`
+	Orientation       string       `json:"orientation"`
+	Equipment         string       `json:"equipment"`
+	ExposureTime      float64      `json:"exposure_time"`
+	FNumber           float64      `json:"fnumber"`
+	FocalLength       float64      `json:"focal_length"`
+	ISOSpeedRatings   int          `json:"iso_speed_ratings"`
+	WhiteBalance      string       `json:"white_balance"`
+	ImageDescription  string       `json:"image_description"`
+	UserComment       string       `json:"user_comment"`
+	DateTimeOriginal  *ISO8601Time `json:"date_time_original"`
+	DateTimeDigitized *ISO8601Time `json:"date_time_digitized"`
+}
+
+// PictureViews returns the views of a Picture document, if present.
+func (d *Document) PictureViews() []PictureView {
+	if fieldViews, ok := d.Properties[DocumentPropertyPictureViews]; ok {
+		var views []PictureView
+		if err := fieldViews.ComplexList(&views); err == nil {
+			return views
+		}
+	}
+	return nil
+}
+
+// PictureView returns the view of a Picture document with the given title (e.g. "Medium"), if present.
+func (d *Document) PictureView(title string) *PictureView {
+	views := d.PictureViews()
+	for i := range views {
+		if views[i].Title == title {
+			return &views[i]
+		}
+	}
+	return nil
+}
+
+// PictureViewFor returns the smallest view of a Picture document at least as large as the given dimensions, or the
+// largest view when none is large enough. A zero dimension is not constrained.
+func (d *Document) PictureViewFor(width int, height int) *PictureView {
+	covers := func(view *PictureView) bool { return view.Width >= width && view.Height >= height }
+	area := func(view *PictureView) int { return view.Width * view.Height }
+	views := d.PictureViews()
+	var best *PictureView
+	for i := range views {
+		view := &views[i]
+		switch {
+		case best == nil,
+			covers(view) && (!covers(best) || area(view) < area(best)),
+			!covers(view) && !covers(best) && area(view) > area(best):
+			best = view
+		}
+	}
+	return best
+}
+
+// PictureInfo returns the dimensions and format of the original picture of a Picture document, if present.
+func (d *Document) PictureInfo() *PictureInfo {
+	if fieldInfo, ok := d.Properties[DocumentPropertyPictureInfo]; ok {
+		var info PictureInfo
+		if err := fieldInfo.Complex(&info); err == nil {
+			return &info
+		}
+	}
+	return nil
+}
+
+// ImageMetadata returns the EXIF/IPTC metadata of a picture document, if any "imd:" property is present.
+// Each property is decoded on its own, so that a property of an unexpected type is left unset without losing the others.
+// Numbers sent as strings are parsed, including fractions such as an exposure time of "1/250".
+func (d *Document) ImageMetadata() *ImageMetadata {
+	var metadata ImageMetadata
+	fields := map[string]func(Field){
+		"pixel_xdimension":    imageMetadataInteger(&metadata.PixelXDimension),
+		"pixel_ydimension":    imageMetadataInteger(&metadata.PixelYDimension),
+		"xresolution":         imageMetadataInteger(&metadata.XResolution),
+		"yresolution":         imageMetadataInteger(&metadata.YResolution),
+		"color_space":         imageMetadataString(&metadata.ColorSpace),
+		"orientation":         imageMetadataString(&metadata.Orientation),
+		"equipment":           imageMetadataString(&metadata.Equipment),
+		"exposure_time":       imageMetadataFloat(&metadata.ExposureTime),
+		"fnumber":             imageMetadataFloat(&metadata.FNumber),
+		"focal_length":        imageMetadataFloat(&metadata.FocalLength),
+		"iso_speed_ratings":   imageMetadataInteger(&metadata.ISOSpeedRatings),
+		"white_balance":       imageMetadataString(&metadata.WhiteBalance),
+		"image_description":   imageMetadataString(&metadata.ImageDescription),
+		"user_comment":        imageMetadataString(&metadata.UserComment),
+		"date_time_original":  imageMetadataTime(&metadata.DateTimeOriginal),
+		"date_time_digitized": imageMetadataTime(&metadata.DateTimeDigitized),
+	}
+	found := false
+	for key, value := range d.Properties {
+		name, isMetadata := strings.CutPrefix(key, documentPrefixImageMetadata)
+		if !isMetadata || value.IsNull() {
+			continue
+		}
+		found = true
+		if decode, known := fields[name]; known {
+			decode(value)
+		}
+	}
+	if !found {
+		return nil
+	}
+	return &metadata
+}
+
+// imageMetadataString decodes a string metadata, keeping numbers as they are written.
+func imageMetadataString(out *string) func(Field) {
+	return func(field Field) {
+		if value, err := field.String(); err == nil && value != nil {
+			*out = *value
+		} else if number, err := field.Float(); err == nil && number != nil {
+			*out = string(field)
+		}
+	}
+}
+
+// imageMetadataFloat decodes a number metadata, either a number or a string holding a number or a fraction.
+func imageMetadataFloat(out *float64) func(Field) {
+	return func(field Field) {
+		if value, ok := imageMetadataNumber(field); ok {
+			*out = value
+		}
+	}
+}
+
+// imageMetadataInteger decodes an integer metadata like imageMetadataFloat, truncating the fractional part.
+func imageMetadataInteger(out *int) func(Field) {
+	return func(field Field) {
+		if value, ok := imageMetadataNumber(field); ok {
+			*out = int(value)
+		}
+	}
+}
+
+// imageMetadataTime decodes a date metadata.
+func imageMetadataTime(out **ISO8601Time) func(Field) {
+	return func(field Field) {
+		if value, err := field.Time(); err == nil {
+			*out = value
+		}
+	}
+}
+
+// imageMetadataNumber returns the value of a number field, or of a string field holding a number or a fraction.
+func imageMetadataNumber(field Field) (float64, bool) {
+	if value, err := field.Float(); err == nil && value != nil {
+		return *value, true
+	}
+	text, err := field.String()
+	if err != nil || text == nil {
+		return 0, false
+	}
+	numerator, denominator, isFraction := strings.Cut(strings.TrimSpace(*text), "/")
+	value, err := strconv.ParseFloat(strings.TrimSpace(numerator), 64)
+	if err != nil {
+		return 0, false
+	}
+	if isFraction {
+		divisor, err := strconv.ParseFloat(strings.TrimSpace(denominator), 64)
+		if err != nil || divisor == 0 {
+			return 0, false
+		}
+		value /= divisor
+	}
+	return value, true
+}
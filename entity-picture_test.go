@@ -0,0 +1,139 @@
+package nuxeo
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// testPictureDocument returns a Picture document with the default views, and EXIF metadata.
+func testPictureDocument(t *testing.T) *Document {
+	t.Helper()
+	var doc Document
+	data := `{
+		"entity-type": "document",
+		"uid": "pic",
+		"type": "Picture",
+		"properties": {
+			"picture:info": {"width": 4000, "height": 3000, "depth": 8, "format": "JPEG", "colorSpace": "sRGB"},
+			"picture:views": [
+				{"title": "Thumbnail", "width": 350, "height": 263, "filename": "Thumbnail_pic.jpg", "content": {"name": "Thumbnail_pic.jpg", "mime-type": "image/jpeg", "length": "12000"}},
+				{"title": "Small", "width": 560, "height": 420},
+				{"title": "Medium", "width": 1200, "height": 900},
+				{"title": "FullHD", "width": 1920, "height": 1440, "info": {"width": 1920, "height": 1440, "format": "JPEG"}}
+			],
+			"imd:pixel_xdimension": 4000,
+			"imd:pixel_ydimension": 3000,
+			"imd:equipment": "Canon EOS 5D",
+			"imd:exposure_time": 0.004,
+			"imd:fnumber": 2.8,
+			"imd:iso_speed_ratings": 200,
+			"imd:date_time_original": "2024-05-01T10:30:00.000Z",
+			"imd:user_comment": null,
+			"dc:title": "pic"
+		}
+	}`
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		t.Fatalf("failed to unmarshal picture: %v", err)
+	}
+	return &doc
+}
+
+func TestDocument_PictureViews(t *testing.T) {
+	doc := testPictureDocument(t)
+
+	views := doc.PictureViews()
+	if len(views) != 4 {
+		t.Fatalf("PictureViews() = %d views, want 4", len(views))
+	}
+	thumbnail := doc.PictureView(PictureViewThumbnail)
+	if thumbnail == nil || thumbnail.Width != 350 || thumbnail.Content == nil || thumbnail.Content.MimeType != "image/jpeg" {
+		t.Errorf("PictureView(Thumbnail) = %+v", thumbnail)
+	}
+	if fullHD := doc.PictureView(PictureViewFullHD); fullHD == nil || fullHD.Info.Format != "JPEG" {
+		t.Errorf("PictureView(FullHD) = %+v", fullHD)
+	}
+	if view := doc.PictureView(PictureViewOriginalJpeg); view != nil {
+		t.Errorf("PictureView(OriginalJpeg) = %+v, want nil", view)
+	}
+	if info := doc.PictureInfo(); info == nil || info.Width != 4000 || info.ColorSpace != "sRGB" {
+		t.Errorf("PictureInfo() = %+v", info)
+	}
+
+	empty := NewDocument("File", "file")
+	if empty.PictureViews() != nil || empty.PictureInfo() != nil || empty.PictureViewFor(100, 100) != nil || empty.ImageMetadata() != nil {
+		t.Error("expected no picture views, info nor metadata for a File")
+	}
+}
+
+func TestDocument_PictureViewFor(t *testing.T) {
+	doc := testPictureDocument(t)
+	tests := []struct {
+		width, height int
+		want          string
+	}{
+		{width: 0, height: 0, want: PictureViewThumbnail},
+		{width: 400, height: 0, want: PictureViewSmall},
+		{width: 0, height: 500, want: PictureViewMedium},
+		{width: 1200, height: 900, want: PictureViewMedium},
+		{width: 1280, height: 720, want: PictureViewFullHD},
+		{width: 3840, height: 2160, want: PictureViewFullHD},
+	}
+	for _, tt := range tests {
+		if view := doc.PictureViewFor(tt.width, tt.height); view == nil || view.Title != tt.want {
+			t.Errorf("PictureViewFor(%d, %d) = %+v, want %s", tt.width, tt.height, view, tt.want)
+		}
+	}
+}
+
+func TestDocument_ImageMetadata(t *testing.T) {
+	metadata := testPictureDocument(t).ImageMetadata()
+	if metadata == nil {
+		t.Fatal("ImageMetadata() = nil")
+	}
+	if metadata.PixelXDimension != 4000 || metadata.PixelYDimension != 3000 || metadata.Equipment != "Canon EOS 5D" {
+		t.Errorf("ImageMetadata() = %+v", metadata)
+	}
+	if metadata.ExposureTime != 0.004 || metadata.FNumber != 2.8 || metadata.ISOSpeedRatings != 200 || metadata.UserComment != "" {
+		t.Errorf("ImageMetadata() exposure = %+v", metadata)
+	}
+	if metadata.DateTimeOriginal == nil || time.Time(*metadata.DateTimeOriginal).Year() != 2024 {
+		t.Errorf("ImageMetadata().DateTimeOriginal = %v", metadata.DateTimeOriginal)
+	}
+}
+
+func TestDocument_ImageMetadata_StringValues(t *testing.T) {
+	var doc Document
+	data := `{
+		"entity-type": "document",
+		"uid": "pic",
+		"type": "Picture",
+		"properties": {
+			"imd:pixel_xdimension": "4000",
+			"imd:xresolution": "72.0",
+			"imd:exposure_time": "1/250",
+			"imd:fnumber": "f/2.8",
+			"imd:iso_speed_ratings": "200",
+			"imd:orientation": 1,
+			"imd:equipment": "Canon EOS 5D",
+			"imd:date_time_original": "2024:05:01 10:30:00"
+		}
+	}`
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		t.Fatalf("failed to unmarshal picture: %v", err)
+	}
+	metadata := doc.ImageMetadata()
+	if metadata == nil {
+		t.Fatal("ImageMetadata() = nil")
+	}
+	if metadata.PixelXDimension != 4000 || metadata.XResolution != 72 || metadata.ExposureTime != 0.004 || metadata.ISOSpeedRatings != 200 {
+		t.Errorf("ImageMetadata() numbers = %+v", metadata)
+	}
+	if metadata.Orientation != "1" || metadata.Equipment != "Canon EOS 5D" {
+		t.Errorf("ImageMetadata() strings = %+v", metadata)
+	}
+	// values which cannot be decoded are left unset
+	if metadata.FNumber != 0 || metadata.DateTimeOriginal != nil {
+		t.Errorf("ImageMetadata() mismatched values = %+v", metadata)
+	}
+}
@@ -1,7 +1,10 @@
 package nuxeo
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"resty.dev/v3"
 )
@@ -20,6 +23,27 @@ func (e *NuxeoError) Error() string {
 	return fmt.Sprintf("Nuxeo Exception: %d - %s", e.Status, e.Message)
 }
 
+// DocumentLockedError is returned when locking a document already locked by someone else.
+type DocumentLockedError struct {
+	DocumentId string
+	// Owner is the user holding the lock
+	Owner string
+	// Created is the date the lock was acquired, if known
+	Created time.Time
+	// Err is the error returned by the server
+	Err error
+}
+
+// Error returns a formatted string describing the lock conflict.
+func (e *DocumentLockedError) Error() string {
+	return fmt.Sprintf("document %s is locked by %s", e.DocumentId, e.Owner)
+}
+
+// Unwrap returns the error returned by the server.
+func (e *DocumentLockedError) Unwrap() error {
+	return e.Err
+}
+
 // handleNuxeoError inspects the error and HTTP response, returning a nuxeoError if the response indicates an error.
 // Returns nil if no error is present.
 func handleNuxeoError(err error, res *resty.Response) error {
@@ -46,3 +70,21 @@ func handleNuxeoError(err error, res *resty.Response) error {
 	}
 	return nil
 }
+
+// handleNuxeoStreamError behaves like handleNuxeoError for requests whose response body is not parsed by resty.
+// On error responses, the Nuxeo error payload is decoded from the body and the body is closed.
+func handleNuxeoStreamError(err error, res *resty.Response) error {
+	if err == nil && res != nil && res.IsError() && res.Body != nil {
+		if nuxeoErr, ok := res.Error().(*NuxeoError); ok {
+			json.NewDecoder(res.Body).Decode(nuxeoErr)
+		}
+		res.Body.Close()
+	}
+	return handleNuxeoError(err, res)
+}
+
+// hasErrorStatus returns true if the error is a Nuxeo error with the given HTTP status.
+func hasErrorStatus(err error, status int) bool {
+	var nuxeoErr *NuxeoError
+	return errors.As(err, &nuxeoErr) && nuxeoErr.Status == status
+}
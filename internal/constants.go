@@ -24,6 +24,7 @@ const (
 	HeaderNxEsSync             = "nx_es_sync"
 	HeaderUserAgent            = "User-Agent"
 	HeaderXAuthenticationToken = "X-Authentication-Token"
+	HeaderXBatchNoDrop         = "X-Batch-No-Drop"
 	HeaderXRepository          = "X-NXRepository"
 	HeaderXProperties          = "X-NXproperties"
 	HeaderXVoidOperation       = "X-NXVoidOperation"
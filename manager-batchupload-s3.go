@@ -0,0 +1,462 @@
+package nuxeo
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anselm94/nuxeo-go-client/internal"
+)
+
+///////////////////////
+//// DIRECT UPLOAD ////
+///////////////////////
+
+// BatchBlobUploader uploads blob content directly to the storage of a batch handler, bypassing the Nuxeo server.
+// Implementations return the information required to complete the upload on the server.
+type BatchBlobUploader interface {
+	// UploadBlob uploads the blob content for the given batch and returns the completion information.
+	UploadBlob(ctx context.Context, batch *batchUpload, fileIdx int, blob *blob) (*BatchUploadCompletion, error)
+}
+
+// BatchUploadCompletion is the payload sent to complete a direct upload on the server.
+// See: https://doc.nuxeo.com/nxdoc/amazon-s3-direct-upload/
+type BatchUploadCompletion struct {
+	Name     string `json:"name"`
+	FileSize int64  `json:"fileSize"`
+	Key      string `json:"key"`
+	MimeType string `json:"mimeType"`
+	ETag     string `json:"etag"`
+	Bucket   string `json:"bucket,omitempty"`
+}
+
+// CompleteUpload completes a direct upload of a file in a batch, once its content was uploaded to the handler storage.
+// Maps to POST /upload/{batchId}/{fileIdx}/complete.
+// See: https://doc.nuxeo.com/nxdoc/amazon-s3-direct-upload/
+func (bum *batchUploadManager) CompleteUpload(ctx context.Context, batchId string, fileIdx int, completion BatchUploadCompletion, options *nuxeoRequestOptions) (*batchUpload, error) {
+	path := internal.PathApiV1 + "/upload/" + batchId + "/" + strconv.Itoa(fileIdx) + "/complete"
+	res, err := bum.client.NewRequest(ctx, options).SetBody(completion).SetResult(&batchUpload{}).SetError(&NuxeoError{}).Post(path)
+
+	if err := handleNuxeoError(err, res); err != nil {
+		bum.logger.Error("Failed to complete upload", "error", err, "status", res.StatusCode())
+		return nil, err
+	}
+	return res.Result().(*batchUpload), nil
+}
+
+// UploadDirect uploads a blob with the given uploader (e.g. directly to S3) and completes the upload on the server.
+func (bum *batchUploadManager) UploadDirect(ctx context.Context, batch *batchUpload, fileIdx int, blob *blob, uploader BatchBlobUploader, options *nuxeoRequestOptions) (*batchUpload, error) {
+	completion, err := uploader.UploadBlob(ctx, batch, fileIdx, blob)
+	if err != nil {
+		bum.logger.Error("Failed to upload blob directly", "error", err, "batchId", batch.BatchId)
+		return nil, err
+	}
+	return bum.CompleteUpload(ctx, batch.BatchId, fileIdx, *completion, options)
+}
+
+//////////////////////////
+//// S3 BATCH HANDLER ////
+//////////////////////////
+
+// S3BatchExtraInfo is the configuration returned by the "s3" batch handler for direct uploads.
+// See: https://doc.nuxeo.com/nxdoc/amazon-s3-direct-upload/
+type S3BatchExtraInfo struct {
+	Bucket             string `json:"bucket"`
+	BaseKey            string `json:"baseKey"`
+	Region             string `json:"region"`
+	Endpoint           string `json:"endpoint"`
+	UseS3Accelerate    bool   `json:"useS3Accelerate"`
+	AwsSecretKeyId     string `json:"awsSecretKeyId"`
+	AwsSecretAccessKey string `json:"awsSecretAccessKey"`
+	AwsSessionToken    string `json:"awsSessionToken"`
+	Expiration         int64  `json:"expiration"`
+}
+
+// S3ExtraInfo decodes the batch ExtraInfo returned by the "s3" batch handler.
+func (b *batchUpload) S3ExtraInfo() (*S3BatchExtraInfo, error) {
+	if len(b.ExtraInfo) == 0 {
+		return nil, fmt.Errorf("batch %s has no extra info", b.BatchId)
+	}
+	field, err := NewComplexField(b.ExtraInfo)
+	if err != nil {
+		return nil, err
+	}
+	extraInfo := &S3BatchExtraInfo{}
+	if err := field.Complex(extraInfo); err != nil {
+		return nil, fmt.Errorf("failed to decode s3 extra info: %w", err)
+	}
+	return extraInfo, nil
+}
+
+// DefaultS3PartSize is the default part size of S3 multipart uploads (the S3 minimum part size).
+const DefaultS3PartSize = 5 * 1024 * 1024
+
+// S3UploaderOptions configures the built-in S3 multipart uploader.
+type S3UploaderOptions struct {
+	// Endpoint overrides the S3 endpoint from the batch extra info (e.g. an S3-compatible storage)
+	Endpoint string
+	// PathStyle addresses the bucket in the URL path rather than the host name; always used with custom endpoints
+	PathStyle bool
+	// PartSize is the size of each uploaded part; defaults to DefaultS3PartSize
+	PartSize int64
+	// HttpClient is the HTTP client used to talk to S3; defaults to http.DefaultClient
+	HttpClient *http.Client
+}
+
+// s3BatchUploader uploads blobs to S3 with multipart uploads, using the temporary credentials of an "s3" batch.
+type s3BatchUploader struct {
+	options S3UploaderOptions
+	now     func() time.Time
+}
+
+// NewS3BatchUploader creates a BatchBlobUploader uploading blobs directly to S3 for batches created with the "s3" handler.
+func NewS3BatchUploader(options *S3UploaderOptions) *s3BatchUploader {
+	uploader := &s3BatchUploader{
+		now: time.Now,
+	}
+	if options != nil {
+		uploader.options = *options
+	}
+	if uploader.options.PartSize <= 0 {
+		uploader.options.PartSize = DefaultS3PartSize
+	}
+	if uploader.options.HttpClient == nil {
+		uploader.options.HttpClient = http.DefaultClient
+	}
+	return uploader
+}
+
+// UploadBlob uploads the blob with an S3 multipart upload under the batch base key.
+// The multipart upload is aborted if any part fails.
+func (u *s3BatchUploader) UploadBlob(ctx context.Context, batch *batchUpload, fileIdx int, blob *blob) (*BatchUploadCompletion, error) {
+	extraInfo, err := batch.S3ExtraInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	key := extraInfo.BaseKey + randomHex(16)
+	objectUrl, err := u.objectUrl(extraInfo, key)
+	if err != nil {
+		return nil, err
+	}
+
+	uploadId, err := u.createMultipartUpload(ctx, extraInfo, objectUrl, blob.MimeType)
+	if err != nil {
+		return nil, err
+	}
+
+	etag, size, err := u.uploadParts(ctx, extraInfo, objectUrl, uploadId, blob)
+	if err != nil {
+		u.abortMultipartUpload(context.WithoutCancel(ctx), extraInfo, objectUrl, uploadId)
+		return nil, err
+	}
+
+	return &BatchUploadCompletion{
+		Name:     blob.Filename,
+		FileSize: size,
+		Key:      key,
+		MimeType: blob.MimeType,
+		ETag:     etag,
+		Bucket:   extraInfo.Bucket,
+	}, nil
+}
+
+// objectUrl returns the URL of the object key in the bucket.
+func (u *s3BatchUploader) objectUrl(extraInfo *S3BatchExtraInfo, key string) (*url.URL, error) {
+	endpoint := u.options.Endpoint
+	if endpoint == "" {
+		endpoint = extraInfo.Endpoint
+	}
+	pathStyle := u.options.PathStyle || endpoint != ""
+
+	switch {
+	case endpoint != "":
+	case extraInfo.UseS3Accelerate:
+		endpoint = "https://s3-accelerate.amazonaws.com"
+	default:
+		endpoint = "https://s3." + s3Region(extraInfo) + ".amazonaws.com"
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+
+	objectUrl, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid s3 endpoint: %w", err)
+	}
+	if pathStyle {
+		objectUrl.Path += "/" + extraInfo.Bucket + "/" + key
+	} else {
+		objectUrl.Host = extraInfo.Bucket + "." + objectUrl.Host
+		objectUrl.Path += "/" + key
+	}
+	return objectUrl, nil
+}
+
+// createMultipartUpload starts a multipart upload and returns its upload ID.
+func (u *s3BatchUploader) createMultipartUpload(ctx context.Context, extraInfo *S3BatchExtraInfo, objectUrl *url.URL, mimeType string) (string, error) {
+	headers := http.Header{}
+	if mimeType != "" {
+		headers.Set(internal.HeaderContentType, mimeType)
+	}
+	res, err := u.do(ctx, extraInfo, http.MethodPost, objectUrl, url.Values{"uploads": {""}}, headers, nil)
+	if err != nil {
+		return "", err
+	}
+	result := struct {
+		UploadId string `xml:"UploadId"`
+	}{}
+	if err := decodeS3Response(res, &result); err != nil {
+		return "", fmt.Errorf("failed to create multipart upload: %w", err)
+	}
+	return result.UploadId, nil
+}
+
+// s3CompletedPart is a part listed in a CompleteMultipartUpload request.
+type s3CompletedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// uploadParts uploads the blob content part by part and completes the multipart upload.
+// Returns the ETag of the object and the number of bytes uploaded.
+func (u *s3BatchUploader) uploadParts(ctx context.Context, extraInfo *S3BatchExtraInfo, objectUrl *url.URL, uploadId string, blob *blob) (string, int64, error) {
+	parts := []s3CompletedPart{}
+	buffer := make([]byte, u.options.PartSize)
+	var size int64
+	for partNumber := 1; ; partNumber++ {
+		n, readErr := io.ReadFull(blob, buffer)
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			return "", 0, fmt.Errorf("failed to read blob: %w", readErr)
+		}
+		// S3 requires at least one part, even for empty blobs
+		if n == 0 && partNumber > 1 {
+			break
+		}
+
+		params := url.Values{
+			"partNumber": {strconv.Itoa(partNumber)},
+			"uploadId":   {uploadId},
+		}
+		res, err := u.do(ctx, extraInfo, http.MethodPut, objectUrl, params, nil, buffer[:n])
+		if err != nil {
+			return "", 0, err
+		}
+		if err := decodeS3Response(res, nil); err != nil {
+			return "", 0, fmt.Errorf("failed to upload part %d: %w", partNumber, err)
+		}
+		parts = append(parts, s3CompletedPart{PartNumber: partNumber, ETag: res.Header.Get("ETag")})
+		size += int64(n)
+
+		if readErr != nil {
+			break
+		}
+	}
+
+	payload, _ := xml.Marshal(struct {
+		XMLName xml.Name          `xml:"CompleteMultipartUpload"`
+		Parts   []s3CompletedPart `xml:"Part"`
+	}{Parts: parts})
+	res, err := u.do(ctx, extraInfo, http.MethodPost, objectUrl, url.Values{"uploadId": {uploadId}}, http.Header{internal.HeaderContentType: {"application/xml"}}, payload)
+	if err != nil {
+		return "", 0, err
+	}
+	result := struct {
+		ETag string `xml:"ETag"`
+	}{}
+	if err := decodeS3Response(res, &result); err != nil {
+		return "", 0, fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	return result.ETag, size, nil
+}
+
+// abortMultipartUpload aborts a multipart upload, discarding the uploaded parts.
+func (u *s3BatchUploader) abortMultipartUpload(ctx context.Context, extraInfo *S3BatchExtraInfo, objectUrl *url.URL, uploadId string) {
+	res, err := u.do(ctx, extraInfo, http.MethodDelete, objectUrl, url.Values{"uploadId": {uploadId}}, nil, nil)
+	if err == nil {
+		res.Body.Close()
+	}
+}
+
+// do sends a SigV4 signed request to S3.
+func (u *s3BatchUploader) do(ctx context.Context, extraInfo *S3BatchExtraInfo, method string, objectUrl *url.URL, params url.Values, headers http.Header, body []byte) (*http.Response, error) {
+	requestUrl := *objectUrl
+	requestUrl.RawQuery = s3CanonicalQuery(params)
+
+	req, err := http.NewRequestWithContext(ctx, method, requestUrl.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	for key, values := range headers {
+		req.Header[key] = values
+	}
+	signS3Request(req, extraInfo, body, u.now().UTC())
+
+	res, err := u.options.HttpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 request failed: %w", err)
+	}
+	return res, nil
+}
+
+// s3Error is the error payload returned by S3.
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// decodeS3Response checks the S3 response for errors and decodes its XML body into out, if not nil.
+// S3 may report errors with a 200 status for CompleteMultipartUpload, hence the body is always inspected.
+func decodeS3Response(res *http.Response, out any) error {
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	var errPayload struct {
+		XMLName xml.Name
+		s3Error
+	}
+	if len(data) > 0 && xml.Unmarshal(data, &errPayload) == nil && errPayload.XMLName.Local == "Error" {
+		return fmt.Errorf("s3 error %d: %s - %s", res.StatusCode, errPayload.Code, errPayload.Message)
+	}
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("s3 error %d: %s", res.StatusCode, res.Status)
+	}
+	if out != nil {
+		return xml.Unmarshal(data, out)
+	}
+	return nil
+}
+
+///////////////
+//// SIGV4 ////
+///////////////
+
+const s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// signS3Request signs the request with AWS Signature Version 4 using the batch temporary credentials.
+// Part uploads are sent with an unsigned payload to avoid hashing them twice.
+func signS3Request(req *http.Request, extraInfo *S3BatchExtraInfo, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	region := s3Region(extraInfo)
+
+	payloadHash := s3UnsignedPayload
+	if req.Method != http.MethodPut {
+		sum := sha256.Sum256(body)
+		payloadHash = hex.EncodeToString(sum[:])
+	}
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if extraInfo.AwsSessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", extraInfo.AwsSessionToken)
+	}
+
+	// canonical headers
+	signed := map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		lowerKey := strings.ToLower(key)
+		if strings.HasPrefix(lowerKey, "x-amz-") || lowerKey == "content-type" {
+			signed[lowerKey] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	headerNames := make([]string, 0, len(signed))
+	for name := range signed {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+	var canonicalHeaders strings.Builder
+	for _, name := range headerNames {
+		canonicalHeaders.WriteString(name + ":" + signed[name] + "\n")
+	}
+	signedHeaders := strings.Join(headerNames, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EscapePath(req.URL.Path),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	signingKey := hmacSha256([]byte("AWS4"+extraInfo.AwsSecretAccessKey), date)
+	signingKey = hmacSha256(signingKey, region)
+	signingKey = hmacSha256(signingKey, "s3")
+	signingKey = hmacSha256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSha256(signingKey, stringToSign))
+
+	req.Header.Set(internal.HeaderAuthorization, "AWS4-HMAC-SHA256 Credential="+extraInfo.AwsSecretKeyId+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// s3Region returns the bucket region, defaulting to us-east-1.
+func s3Region(extraInfo *S3BatchExtraInfo) string {
+	if extraInfo.Region == "" {
+		return "us-east-1"
+	}
+	return extraInfo.Region
+}
+
+// s3CanonicalQuery encodes query parameters sorted by key, with RFC 3986 escaping as required by SigV4.
+func s3CanonicalQuery(params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := []string{}
+	for _, key := range keys {
+		for _, value := range params[key] {
+			pairs = append(pairs, s3Escape(key)+"="+s3Escape(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// s3EscapePath escapes each segment of the path with RFC 3986 escaping.
+func s3EscapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = s3Escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// s3Escape escapes a string with RFC 3986 unreserved characters left as is.
+func s3Escape(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// randomHex returns a random hex string of n bytes.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,286 @@
+package nuxeo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// s3Stub is a minimal S3-compatible server supporting multipart uploads.
+type s3Stub struct {
+	mu        sync.Mutex
+	parts     map[string]map[int][]byte
+	objects   map[string][]byte
+	aborted   []string
+	failParts bool
+}
+
+func newS3Stub() *s3Stub {
+	return &s3Stub{
+		parts:   make(map[string]map[int][]byte),
+		objects: make(map[string][]byte),
+	}
+}
+
+func (s *s3Stub) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !strings.HasPrefix(req.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") || req.Header.Get("X-Amz-Security-Token") != "session" {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>bad signature</Message></Error>`)
+		return
+	}
+
+	query := req.URL.Query()
+	switch {
+	case req.Method == http.MethodPost && query.Has("uploads"):
+		uploadId := fmt.Sprintf("upload-%d", len(s.parts)+1)
+		s.parts[uploadId] = make(map[int][]byte)
+		fmt.Fprintf(w, `<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>`, uploadId)
+	case req.Method == http.MethodPut:
+		if s.failParts {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `<Error><Code>InternalError</Code><Message>boom</Message></Error>`)
+			return
+		}
+		partNumber, _ := strconv.Atoi(query.Get("partNumber"))
+		data, _ := io.ReadAll(req.Body)
+		s.parts[query.Get("uploadId")][partNumber] = data
+		w.Header().Set("ETag", fmt.Sprintf(`"etag-%d"`, partNumber))
+	case req.Method == http.MethodPost && query.Has("uploadId"):
+		var complete struct {
+			Parts []s3CompletedPart `xml:"Part"`
+		}
+		xml.NewDecoder(req.Body).Decode(&complete)
+		parts := s.parts[query.Get("uploadId")]
+		numbers := []int{}
+		for _, part := range complete.Parts {
+			numbers = append(numbers, part.PartNumber)
+		}
+		sort.Ints(numbers)
+		var object bytes.Buffer
+		for _, number := range numbers {
+			object.Write(parts[number])
+		}
+		s.objects[req.URL.EscapedPath()] = object.Bytes()
+		fmt.Fprint(w, `<CompleteMultipartUploadResult><ETag>"final-etag"</ETag></CompleteMultipartUploadResult>`)
+	case req.Method == http.MethodDelete:
+		s.aborted = append(s.aborted, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+// newS3TestBatch returns a batch as created by the "s3" batch handler.
+func newS3TestBatch(t *testing.T) *batchUpload {
+	extraInfo := map[string]Field{}
+	data, _ := json.Marshal(S3BatchExtraInfo{
+		Bucket:             "bucket",
+		BaseKey:            "uploads/",
+		Region:             "eu-west-1",
+		AwsSecretKeyId:     "AKID",
+		AwsSecretAccessKey: "secret",
+		AwsSessionToken:    "session",
+	})
+	if err := json.Unmarshal(data, &extraInfo); err != nil {
+		t.Fatalf("failed to build extra info: %v", err)
+	}
+	return &batchUpload{BatchId: "batch1", Provider: BatchHandlerS3, ExtraInfo: extraInfo}
+}
+
+func TestBatchUploadManager_CreateBatchWithHandler(t *testing.T) {
+	t.Parallel()
+	client := newMockNuxeoClient(func(req *http.Request) (*http.Response, error) {
+		if req.URL.EscapedPath() != "/api/v1/upload/new/s3" {
+			return nil, fmt.Errorf("unexpected path %s", req.URL.EscapedPath())
+		}
+		body := `{"batchId":"batch1","provider":"s3","extraInfo":{"bucket":"bucket","baseKey":"base/","region":"eu-west-1","awsSecretKeyId":"AKID","awsSecretAccessKey":"secret","awsSessionToken":"session","expiration":1700000000000,"useS3Accelerate":true}}`
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+		}, nil
+	})
+	bum := &batchUploadManager{client: client, logger: slog.Default()}
+	batch, err := bum.CreateBatchWithHandler(context.Background(), BatchHandlerS3, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if batch.BatchId != "batch1" || batch.Provider != BatchHandlerS3 {
+		t.Errorf("unexpected batch: %+v", batch)
+	}
+	extraInfo, err := batch.S3ExtraInfo()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := S3BatchExtraInfo{
+		Bucket:             "bucket",
+		BaseKey:            "base/",
+		Region:             "eu-west-1",
+		UseS3Accelerate:    true,
+		AwsSecretKeyId:     "AKID",
+		AwsSecretAccessKey: "secret",
+		AwsSessionToken:    "session",
+		Expiration:         1700000000000,
+	}
+	if *extraInfo != want {
+		t.Errorf("S3ExtraInfo() = %+v, want %+v", *extraInfo, want)
+	}
+
+	if _, err := (&batchUpload{BatchId: "default"}).S3ExtraInfo(); err == nil {
+		t.Errorf("expected error for batch without extra info")
+	}
+}
+
+func TestBatchUploadManager_FetchBatchHandlers(t *testing.T) {
+	t.Parallel()
+	client := newMockNuxeoClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(`{"handlers":[{"name":"default"},{"name":"s3"}]}`)),
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+		}, nil
+	})
+	bum := &batchUploadManager{client: client, logger: slog.Default()}
+	handlers, err := bum.FetchBatchHandlers(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(handlers) != 2 || handlers[1].Name != BatchHandlerS3 {
+		t.Errorf("unexpected handlers: %+v", handlers)
+	}
+}
+
+func TestS3BatchUploader_UploadBlob(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		data      []byte
+		failParts bool
+		wantErr   bool
+		wantParts int
+	}{
+		{name: "multiple parts", data: bytes.Repeat([]byte("abcdefghij"), 25), wantParts: 3},
+		{name: "single part", data: []byte("small"), wantParts: 1},
+		{name: "empty blob", data: []byte{}, wantParts: 1},
+		{name: "failed part aborts upload", data: []byte("data"), failParts: true, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			stub := newS3Stub()
+			stub.failParts = tc.failParts
+			server := httptest.NewServer(stub)
+			defer server.Close()
+
+			uploader := NewS3BatchUploader(&S3UploaderOptions{Endpoint: server.URL, PartSize: 100})
+			completion, err := uploader.UploadBlob(context.Background(), newS3TestBatch(t), 0, NewBlobFromBytes("data.txt", tc.data))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("UploadBlob() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				if len(stub.aborted) != 1 {
+					t.Errorf("expected multipart upload to be aborted, got %v", stub.aborted)
+				}
+				return
+			}
+
+			if !strings.HasPrefix(completion.Key, "uploads/") || completion.Bucket != "bucket" || completion.ETag != `"final-etag"` {
+				t.Errorf("unexpected completion: %+v", completion)
+			}
+			if completion.FileSize != int64(len(tc.data)) || completion.Name != "data.txt" || completion.MimeType != "text/plain" {
+				t.Errorf("unexpected completion: %+v", completion)
+			}
+			if got := len(stub.parts["upload-1"]); got != tc.wantParts {
+				t.Errorf("parts: got %d, want %d", got, tc.wantParts)
+			}
+			if got := stub.objects["/bucket/"+completion.Key]; !bytes.Equal(got, tc.data) {
+				t.Errorf("object content: got %d bytes, want %d", len(got), len(tc.data))
+			}
+		})
+	}
+}
+
+func TestBatchUploadManager_UploadDirect(t *testing.T) {
+	t.Parallel()
+	stub := newS3Stub()
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	var completed BatchUploadCompletion
+	client := newMockNuxeoClient(func(req *http.Request) (*http.Response, error) {
+		if req.URL.EscapedPath() != "/api/v1/upload/batch1/0/complete" {
+			return nil, fmt.Errorf("unexpected path %s", req.URL.EscapedPath())
+		}
+		if err := json.NewDecoder(req.Body).Decode(&completed); err != nil {
+			return nil, err
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(`{"name":"data.txt","batchId":"batch1","fileIdx":"0"}`)),
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+		}, nil
+	})
+	bum := &batchUploadManager{client: client, logger: slog.Default()}
+	uploader := NewS3BatchUploader(&S3UploaderOptions{Endpoint: server.URL})
+
+	upload, err := bum.UploadDirect(context.Background(), newS3TestBatch(t), 0, NewBlobFromBytes("data.txt", []byte("hello")), uploader, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if upload.FileIdx != "0" || upload.BatchId != "batch1" {
+		t.Errorf("unexpected upload: %+v", upload)
+	}
+	if completed.FileSize != 5 || completed.Bucket != "bucket" || completed.Key == "" {
+		t.Errorf("unexpected completion payload: %+v", completed)
+	}
+
+	failing := &batchUploadManager{client: newMockNuxeoClient(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("unexpected call")
+	}), logger: slog.Default()}
+	if _, err := failing.UploadDirect(context.Background(), &batchUpload{BatchId: "batch1"}, 0, NewBlobFromBytes("a", nil), uploader, nil); err == nil {
+		t.Errorf("expected error for batch without s3 extra info")
+	}
+}
+
+func TestS3BatchUploader_ObjectUrl(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		options   *S3UploaderOptions
+		extraInfo S3BatchExtraInfo
+		want      string
+	}{
+		{name: "virtual hosted", extraInfo: S3BatchExtraInfo{Bucket: "b", Region: "eu-west-1"}, want: "https://b.s3.eu-west-1.amazonaws.com/k"},
+		{name: "default region", extraInfo: S3BatchExtraInfo{Bucket: "b"}, want: "https://b.s3.us-east-1.amazonaws.com/k"},
+		{name: "accelerate", extraInfo: S3BatchExtraInfo{Bucket: "b", UseS3Accelerate: true}, want: "https://b.s3-accelerate.amazonaws.com/k"},
+		{name: "path style", options: &S3UploaderOptions{PathStyle: true}, extraInfo: S3BatchExtraInfo{Bucket: "b", Region: "eu-west-1"}, want: "https://s3.eu-west-1.amazonaws.com/b/k"},
+		{name: "custom endpoint", extraInfo: S3BatchExtraInfo{Bucket: "b", Endpoint: "minio:9000"}, want: "https://minio:9000/b/k"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := NewS3BatchUploader(tc.options).objectUrl(&tc.extraInfo, "k")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.String() != tc.want {
+				t.Errorf("objectUrl() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -2,8 +2,11 @@ package nuxeo
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net/url"
 	"strconv"
 
 	"github.com/anselm94/nuxeo-go-client/internal"
@@ -28,11 +31,47 @@ type batchUpload struct {
 	UploadedSize     string `json:"uploadedSize"`
 	UploadedChunkIds []int  `json:"uploadedChunkIds"`
 	ChunkCount       int    `json:"chunkCount"`
+
+	// (Readonly) Provider is the batch handler name which created the batch (e.g. "default", "s3")
+	Provider string `json:"provider,omitempty"`
+	// (Readonly) ExtraInfo holds handler specific information, such as direct upload credentials
+	ExtraInfo map[string]Field `json:"extraInfo,omitempty"`
+}
+
+// batchHandler represents a batch upload handler registered on the server.
+type batchHandler struct {
+	Name string `json:"name"`
+}
+
+// batchHandlers is the list of batch upload handlers returned by the server.
+type batchHandlers struct {
+	Handlers []batchHandler `json:"handlers"`
+}
+
+// FetchBatchHandlers lists the batch upload handlers available on the server.
+// Maps to GET /upload/handlers.
+// See: https://doc.nuxeo.com/nxdoc/batch-upload-endpoint/
+func (bum *batchUploadManager) FetchBatchHandlers(ctx context.Context, options *nuxeoRequestOptions) ([]batchHandler, error) {
+	path := internal.PathApiV1 + "/upload/handlers"
+	res, err := bum.client.NewRequest(ctx, options).SetResult(&batchHandlers{}).SetError(&NuxeoError{}).Get(path)
+
+	if err := handleNuxeoError(err, res); err != nil {
+		bum.logger.Error("Failed to fetch batch handlers", "error", err, "status", res.StatusCode())
+		return nil, err
+	}
+	return res.Result().(*batchHandlers).Handlers, nil
 }
 
 // CreateBatch initializes a new batch upload session with the default handler.
 func (bum *batchUploadManager) CreateBatch(ctx context.Context, options *nuxeoRequestOptions) (*batchUpload, error) {
-	path := internal.PathApiV1 + "/upload/new/default"
+	return bum.CreateBatchWithHandler(ctx, BatchHandlerDefault, options)
+}
+
+// CreateBatchWithHandler initializes a new batch upload session with the given batch handler (e.g. "s3").
+// Maps to POST /upload/new/{handlerName}. Handlers for direct uploads return their configuration in ExtraInfo.
+// See: https://doc.nuxeo.com/nxdoc/batch-upload-endpoint/
+func (bum *batchUploadManager) CreateBatchWithHandler(ctx context.Context, handlerName string, options *nuxeoRequestOptions) (*batchUpload, error) {
+	path := internal.PathApiV1 + "/upload/new/" + url.PathEscape(handlerName)
 	res, err := bum.client.NewRequest(ctx, options).SetResult(&batchUpload{}).SetError(&NuxeoError{}).Post(path)
 
 	if err := handleNuxeoError(err, res); err != nil {
@@ -130,13 +169,18 @@ func (bum *batchUploadManager) Upload(ctx context.Context, batchId string, fileI
 
 // Upload uploads a chunk to a batch, setting all required headers.
 func (bum *batchUploadManager) UploadAsChunk(ctx context.Context, batchId string, fileIdx int, chunkIdx int, totalChunks int, blob *blob, options *nuxeoRequestOptions) (*batchUpload, error) {
+	return bum.uploadChunk(ctx, batchId, fileIdx, chunkIdx, totalChunks, blob.Size(), blob, options)
+}
+
+// uploadChunk uploads a chunk of a file of fileSize bytes to a batch, setting all required headers.
+func (bum *batchUploadManager) uploadChunk(ctx context.Context, batchId string, fileIdx int, chunkIdx int, totalChunks int, fileSize int64, blob *blob, options *nuxeoRequestOptions) (*batchUpload, error) {
 	path := internal.PathApiV1 + "/upload/" + batchId + "/" + strconv.Itoa(fileIdx)
 
 	request := bum.client.NewRequest(ctx, options).
 		SetHeader("X-Upload-Type", "chunked").
 		SetHeader("X-File-Name", blob.Filename).
 		SetHeader("X-File-Type", blob.MimeType).
-		SetHeader("X-File-Size", fmt.Sprintf("%d", blob.Size())).
+		SetHeader("X-File-Size", fmt.Sprintf("%d", fileSize)).
 		SetHeader("X-Upload-Chunk-Index", fmt.Sprintf("%d", chunkIdx)).
 		SetHeader("X-Upload-Chunk-Count", fmt.Sprintf("%d", totalChunks)).
 		SetContentLength(true).
@@ -151,3 +195,100 @@ func (bum *batchUploadManager) UploadAsChunk(ctx context.Context, batchId string
 	}
 	return res.Result().(*batchUpload), nil
 }
+
+// DefaultUploadChunkSize is the default chunk size of chunked uploads.
+const DefaultUploadChunkSize = 5 * 1024 * 1024
+
+// BlobUploadOptions configures how blobs are uploaded by the higher level upload helpers.
+type BlobUploadOptions struct {
+	// ChunkSize is the chunk size of chunked uploads; blobs larger than ChunkSize are uploaded in chunks.
+	// Defaults to DefaultUploadChunkSize.
+	ChunkSize int64
+	// Retries is the number of times a failed upload is retried; re-openable blobs are rewound before retrying.
+	Retries int
+	// BatchHandler is the batch handler used to create batches; defaults to "default"
+	BatchHandler string
+	// Uploader uploads blobs directly to the batch handler storage (e.g. NewS3BatchUploader) instead of the server
+	Uploader BatchBlobUploader
+}
+
+// chunkSize returns the configured chunk size, or the default one.
+func (o *BlobUploadOptions) chunkSize() int64 {
+	if o == nil || o.ChunkSize <= 0 {
+		return DefaultUploadChunkSize
+	}
+	return o.ChunkSize
+}
+
+// batchHandler returns the configured batch handler, or the default one.
+func (o *BlobUploadOptions) batchHandler() string {
+	if o == nil || o.BatchHandler == "" {
+		return BatchHandlerDefault
+	}
+	return o.BatchHandler
+}
+
+// UploadBlob uploads a blob to a batch, picking the upload strategy from the upload options.
+//
+// Blobs are uploaded with the direct uploader when set, in chunks when larger than the chunk size, or in a single
+// request otherwise. Failed uploads are retried according to uploadOptions.Retries.
+func (bum *batchUploadManager) UploadBlob(ctx context.Context, batch *batchUpload, fileIdx int, blob *blob, uploadOptions *BlobUploadOptions, options *nuxeoRequestOptions) (*batchUpload, error) {
+	if blob.Size() < 0 || blob.Length == "" {
+		return nil, fmt.Errorf("failed to upload blob %s: unknown length, spool it with NewBlobFromReader", blob.Filename)
+	}
+
+	retries := 0
+	if uploadOptions != nil {
+		retries = uploadOptions.Retries
+	}
+
+	switch {
+	case uploadOptions != nil && uploadOptions.Uploader != nil:
+		return withUploadRetries(blob, retries, func() (*batchUpload, error) {
+			return bum.UploadDirect(ctx, batch, fileIdx, blob, uploadOptions.Uploader, options)
+		})
+	case blob.Size() > uploadOptions.chunkSize():
+		return bum.uploadInChunks(ctx, batch.BatchId, fileIdx, blob, uploadOptions.chunkSize(), retries, options)
+	default:
+		return withUploadRetries(blob, retries, func() (*batchUpload, error) {
+			return bum.Upload(ctx, batch.BatchId, fileIdx, blob, options)
+		})
+	}
+}
+
+// uploadInChunks reads the blob sequentially and uploads it chunk by chunk, retrying failed chunks.
+func (bum *batchUploadManager) uploadInChunks(ctx context.Context, batchId string, fileIdx int, blob *blob, chunkSize int64, retries int, options *nuxeoRequestOptions) (*batchUpload, error) {
+	fileSize := blob.Size()
+	totalChunks := int((fileSize + chunkSize - 1) / chunkSize)
+	buffer := make([]byte, chunkSize)
+
+	var upload *batchUpload
+	for chunkIdx := range totalChunks {
+		n, err := io.ReadFull(blob, buffer)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("failed to read chunk %d of blob %s: %w", chunkIdx, blob.Filename, err)
+		}
+		chunk := NewBlobFromBytes(blob.Filename, buffer[:n])
+		chunk.MimeType = blob.MimeType
+
+		upload, err = withUploadRetries(chunk, retries, func() (*batchUpload, error) {
+			return bum.uploadChunk(ctx, batchId, fileIdx, chunkIdx, totalChunks, fileSize, chunk, options)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return upload, nil
+}
+
+// withUploadRetries runs upload, rewinding the blob and retrying up to retries times on failure.
+func withUploadRetries(blob *blob, retries int, upload func() (*batchUpload, error)) (*batchUpload, error) {
+	result, err := upload()
+	for attempt := 0; err != nil && attempt < retries && blob.IsReopenable(); attempt++ {
+		if reopenErr := blob.Reopen(); reopenErr != nil {
+			return nil, errors.Join(err, reopenErr)
+		}
+		result, err = upload()
+	}
+	return result, err
+}
@@ -9,6 +9,7 @@ import (
 	"log/slog"
 	"net/http"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -517,3 +518,87 @@ func TestBatchUploadManager_UploadAsChunk(t *testing.T) {
 		})
 	}
 }
+
+func TestBatchUploadManager_UploadBlob(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		blob      func() *blob
+		options   *BlobUploadOptions
+		failures  int
+		wantErr   bool
+		wantCalls int
+	}{
+		{
+			name:      "single request",
+			blob:      func() *blob { return NewBlobFromBytes("a.txt", []byte("data")) },
+			wantCalls: 1,
+		},
+		{
+			name:      "chunked",
+			blob:      func() *blob { return NewBlobFromBytes("a.txt", []byte("0123456789")) },
+			options:   &BlobUploadOptions{ChunkSize: 3},
+			wantCalls: 4,
+		},
+		{
+			name:      "retried after failure",
+			blob:      func() *blob { return NewBlobFromBytes("a.txt", []byte("data")) },
+			options:   &BlobUploadOptions{Retries: 2},
+			failures:  2,
+			wantCalls: 3,
+		},
+		{
+			name:      "retries exhausted",
+			blob:      func() *blob { return NewBlobFromBytes("a.txt", []byte("data")) },
+			options:   &BlobUploadOptions{Retries: 1},
+			failures:  2,
+			wantErr:   true,
+			wantCalls: 2,
+		},
+		{
+			name: "unknown length",
+			blob: func() *blob {
+				b, _ := NewBlobFromReader("a.txt", io.LimitReader(strings.NewReader("data"), 10), nil)
+				return b
+			},
+			wantErr:   true,
+			wantCalls: 0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var mu sync.Mutex
+			calls := 0
+			client := newMockNuxeoClient(func(req *http.Request) (*http.Response, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				calls++
+				body, _ := io.ReadAll(req.Body)
+				if calls <= tc.failures {
+					return nil, errors.New("network error")
+				}
+				if req.Header.Get("X-Upload-Type") == "normal" && string(body) != "data" {
+					return nil, fmt.Errorf("unexpected body %q", body)
+				}
+				return &http.Response{
+					StatusCode: 201,
+					Body:       io.NopCloser(strings.NewReader(`{"batchId":"batch1","fileIdx":"0"}`)),
+					Header:     http.Header{"Content-Type": []string{"application/json"}},
+				}, nil
+			})
+			bum := &batchUploadManager{client: client, logger: slog.Default()}
+			upload, err := bum.UploadBlob(context.Background(), &batchUpload{BatchId: "batch1"}, 0, tc.blob(), tc.options, nil)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("UploadBlob() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !tc.wantErr && upload.FileIdx != "0" {
+				t.Errorf("unexpected upload: %+v", upload)
+			}
+			if calls != tc.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tc.wantCalls)
+			}
+		})
+	}
+}
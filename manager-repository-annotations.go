@@ -0,0 +1,111 @@
+package nuxeo
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+
+	"github.com/anselm94/nuxeo-go-client/internal"
+)
+
+/////////////////////
+//// ANNOTATIONS ////
+/////////////////////
+
+// annotationsPath returns the API path of the annotations of a document.
+func (r *repository) annotationsPath(documentId string) string {
+	return internal.PathApiV1 + "/repo/" + url.PathEscape(r.name) + "/id/" + url.PathEscape(documentId) + "/@annotation"
+}
+
+// FetchAnnotations retrieves the annotations of the blob at the xpath of a document.
+// Maps to GET /api/v1/repo/{repo}/id/{id}/@annotation?xpath={xpath}
+// Returns entityAnnotations or error.
+func (r *repository) FetchAnnotations(ctx context.Context, documentId string, xpath string, options *nuxeoRequestOptions) (*Annotations, error) {
+	path := r.annotationsPath(documentId) + "?" + url.Values{"xpath": {xpath}}.Encode()
+	res, err := r.client.NewRequest(ctx, options).SetResult(&Annotations{}).SetError(&NuxeoError{}).Get(path)
+
+	if err := handleNuxeoError(err, res); err != nil {
+		r.logger.Error("Failed to fetch annotations", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return res.Result().(*Annotations), nil
+}
+
+// FetchAnnotation retrieves an annotation of a document by its ID.
+// Maps to GET /api/v1/repo/{repo}/id/{id}/@annotation/{annotationId}
+// Returns entityAnnotation or error.
+func (r *repository) FetchAnnotation(ctx context.Context, documentId string, annotationId string, options *nuxeoRequestOptions) (*Annotation, error) {
+	path := r.annotationsPath(documentId) + "/" + url.PathEscape(annotationId)
+	res, err := r.client.NewRequest(ctx, options).SetResult(&Annotation{}).SetError(&NuxeoError{}).Get(path)
+
+	if err := handleNuxeoError(err, res); err != nil {
+		r.logger.Error("Failed to fetch annotation", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return res.Result().(*Annotation), nil
+}
+
+// CreateAnnotation adds an annotation to a blob of a document. The annotation ParentId defaults to the document ID.
+// Maps to POST /api/v1/repo/{repo}/id/{id}/@annotation
+// Returns the created entityAnnotation or error.
+func (r *repository) CreateAnnotation(ctx context.Context, documentId string, annotation Annotation, options *nuxeoRequestOptions) (*Annotation, error) {
+	annotation.EntityType = EntityTypeAnnotation
+	if annotation.ParentId == "" {
+		annotation.ParentId = documentId
+	}
+	res, err := r.client.NewRequest(ctx, options).SetBody(annotation).SetResult(&Annotation{}).SetError(&NuxeoError{}).Post(r.annotationsPath(documentId))
+
+	if err := handleNuxeoError(err, res); err != nil {
+		r.logger.Error("Failed to create annotation", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return res.Result().(*Annotation), nil
+}
+
+// UpdateAnnotation updates an annotation of a document, identified by the annotation ID.
+// Maps to PUT /api/v1/repo/{repo}/id/{id}/@annotation/{annotationId}
+// Returns the updated entityAnnotation or error.
+func (r *repository) UpdateAnnotation(ctx context.Context, documentId string, annotation Annotation, options *nuxeoRequestOptions) (*Annotation, error) {
+	annotation.EntityType = EntityTypeAnnotation
+	path := r.annotationsPath(documentId) + "/" + url.PathEscape(annotation.Id)
+	res, err := r.client.NewRequest(ctx, options).SetBody(annotation).SetResult(&Annotation{}).SetError(&NuxeoError{}).Put(path)
+
+	if err := handleNuxeoError(err, res); err != nil {
+		r.logger.Error("Failed to update annotation", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return res.Result().(*Annotation), nil
+}
+
+// DeleteAnnotation deletes an annotation of a document by its ID.
+// Maps to DELETE /api/v1/repo/{repo}/id/{id}/@annotation/{annotationId}
+// Returns error if deletion fails.
+func (r *repository) DeleteAnnotation(ctx context.Context, documentId string, annotationId string) error {
+	path := r.annotationsPath(documentId) + "/" + url.PathEscape(annotationId)
+	res, err := r.client.NewRequest(ctx, nil).SetError(&NuxeoError{}).Delete(path)
+
+	if err := handleNuxeoError(err, res); err != nil {
+		r.logger.Error("Failed to delete annotation", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// FetchAnnotationsByQuery retrieves the annotations of the blob at the xpath of every document matching the NXQL query.
+// Returns the annotations by document ID, leaving out the documents without annotations, or the first error met.
+func (r *repository) FetchAnnotationsByQuery(ctx context.Context, query string, queryParams []string, xpath string) (map[string][]Annotation, error) {
+	annotations := map[string][]Annotation{}
+	for doc, err := range r.QueryAll(ctx, query, queryParams, 0, NewNuxeoRequestOptions().SetRepositoryName(r.name)) {
+		if err != nil {
+			return nil, err
+		}
+		docAnnotations, err := r.FetchAnnotations(ctx, doc.ID, xpath, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(docAnnotations.Entries) > 0 {
+			annotations[doc.ID] = docAnnotations.Entries
+		}
+	}
+	return annotations, nil
+}
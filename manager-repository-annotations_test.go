@@ -0,0 +1,133 @@
+package nuxeo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// annotationsTestServer stores annotations in memory, numbered a1, a2... in creation order, and serves the documents
+// "doc1" and "doc2" as query result.
+type annotationsTestServer struct {
+	t           *testing.T
+	mu          sync.Mutex
+	annotations []*Annotation
+}
+
+func (srv *annotationsTestServer) respond(req *http.Request) (*http.Response, error) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if req.URL.EscapedPath() == "/api/v1/query" {
+		if req.Header.Get("X-NXRepository") != "default" {
+			srv.t.Errorf("query sent to repository %q", req.Header.Get("X-NXRepository"))
+		}
+		return testJsonResponse(srv.t, 200, map[string]any{"entity-type": "documents", "entries": []any{
+			map[string]any{"entity-type": "document", "uid": "doc1"},
+			map[string]any{"entity-type": "document", "uid": "doc2"},
+		}})
+	}
+
+	documentId, annotationId, found := strings.Cut(strings.TrimPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/id/"), "/@annotation")
+	if !found {
+		return nil, errors.New("unexpected request " + req.URL.String())
+	}
+	annotationId = strings.TrimPrefix(annotationId, "/")
+	switch {
+	case annotationId == "" && req.Method == http.MethodGet:
+		entries := []Annotation{}
+		for _, annotation := range srv.annotations {
+			if annotation.ParentId == documentId && annotation.XPath == req.URL.Query().Get("xpath") {
+				entries = append(entries, *annotation)
+			}
+		}
+		return testJsonResponse(srv.t, 200, map[string]any{"entity-type": "annotations", "entries": entries})
+	case annotationId == "" && req.Method == http.MethodPost:
+		var annotation Annotation
+		if err := json.NewDecoder(req.Body).Decode(&annotation); err != nil {
+			return nil, err
+		}
+		if annotation.EntityType != EntityTypeAnnotation || annotation.ParentId != documentId {
+			srv.t.Errorf("posted %+v on %s", annotation, documentId)
+		}
+		annotation.Id, annotation.Author = fmt.Sprintf("a%d", len(srv.annotations)+1), "Administrator"
+		srv.annotations = append(srv.annotations, &annotation)
+		return testJsonResponse(srv.t, 201, annotation)
+	}
+	for i, annotation := range srv.annotations {
+		if annotation.Id != annotationId || annotation.ParentId != documentId {
+			continue
+		}
+		switch req.Method {
+		case http.MethodPut:
+			if err := json.NewDecoder(req.Body).Decode(annotation); err != nil {
+				return nil, err
+			}
+		case http.MethodDelete:
+			srv.annotations = append(srv.annotations[:i], srv.annotations[i+1:]...)
+			return testNoContentResponse()
+		}
+		return testJsonResponse(srv.t, 200, annotation)
+	}
+	return testNotFoundResponse(srv.t)
+}
+
+func TestRepository_Annotations(t *testing.T) {
+	t.Parallel()
+	srv := &annotationsTestServer{t: t}
+	repo := newTestRepository(srv.respond)
+	ctx := context.Background()
+
+	created, err := repo.CreateAnnotation(ctx, "doc1", *NewAnnotation("", "file:content", "pdfjs", `<xfdf><highlight page="1"/></xfdf>`), nil)
+	if err != nil {
+		t.Fatalf("CreateAnnotation() error = %v", err)
+	}
+	if created.Id != "a1" || created.ParentId != "doc1" || created.Author != "Administrator" {
+		t.Errorf("CreateAnnotation() = %+v", created)
+	}
+	if _, err := repo.CreateAnnotation(ctx, "doc1", *NewAnnotation("doc1", "files:files/0/file", "pdfjs", "{}"), nil); err != nil {
+		t.Fatalf("CreateAnnotation() error = %v", err)
+	}
+
+	created.Entity = `<xfdf><highlight page="2"/></xfdf>`
+	if updated, err := repo.UpdateAnnotation(ctx, "doc1", *created, nil); err != nil || updated.Entity != created.Entity {
+		t.Fatalf("UpdateAnnotation() = %+v, %v", updated, err)
+	}
+	fetched, err := repo.FetchAnnotation(ctx, "doc1", "a1", nil)
+	if err != nil || fetched.Entity != created.Entity || fetched.Origin != "pdfjs" {
+		t.Errorf("FetchAnnotation() = %+v, %v", fetched, err)
+	}
+
+	annotations, err := repo.FetchAnnotations(ctx, "doc1", "file:content", nil)
+	if err != nil || len(annotations.Entries) != 1 || annotations.Entries[0].Id != "a1" {
+		t.Errorf("FetchAnnotations() = %+v, %v", annotations, err)
+	}
+	if err := repo.DeleteAnnotation(ctx, "doc1", "a1"); err != nil {
+		t.Fatalf("DeleteAnnotation() error = %v", err)
+	}
+	if _, err := repo.FetchAnnotation(ctx, "doc1", "a1", nil); !hasErrorStatus(err, http.StatusNotFound) {
+		t.Errorf("FetchAnnotation() of a deleted annotation error = %v, want not found", err)
+	}
+}
+
+func TestRepository_FetchAnnotationsByQuery(t *testing.T) {
+	t.Parallel()
+	srv := &annotationsTestServer{t: t, annotations: []*Annotation{
+		{Id: "a1", ParentId: "doc2", XPath: "file:content"},
+		{Id: "a2", ParentId: "doc2", XPath: "file:content"},
+		{Id: "a3", ParentId: "doc1", XPath: "files:files/0/file"},
+	}}
+	repo := newTestRepository(srv.respond)
+
+	annotations, err := repo.FetchAnnotationsByQuery(context.Background(), "SELECT * FROM File", nil, "file:content")
+	if err != nil {
+		t.Fatalf("FetchAnnotationsByQuery() error = %v", err)
+	}
+	if len(annotations) != 1 || len(annotations["doc2"]) != 2 {
+		t.Errorf("FetchAnnotationsByQuery() = %+v, want the 2 annotations of doc2", annotations)
+	}
+}
@@ -0,0 +1,127 @@
+package nuxeo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+
+	"github.com/anselm94/nuxeo-go-client/internal"
+)
+
+///////////////////////////
+//// DOCUMENTS & BLOBS ////
+///////////////////////////
+
+// CreateDocumentWithBlobs creates a document under the parent (a repository path or a document ID) along with its blobs.
+//
+// The blobs are uploaded into a new batch, chunked when large, and referenced from the document properties keyed by
+// xpath (e.g. "file:content", "files:files"). List properties such as "files:files", or any xpath given more than one
+// blob, are set as lists of {"file": blob} items. The batch is cancelled if any step fails.
+func (r *repository) CreateDocumentWithBlobs(ctx context.Context, parentRef string, doc Document, blobs map[string][]*blob, uploadOptions *BlobUploadOptions, options *nuxeoRequestOptions) (*Document, error) {
+	batchUploadManager := r.client.BatchUploadManager()
+	batch, err := batchUploadManager.CreateBatchWithHandler(ctx, uploadOptions.batchHandler(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// copy the properties to keep the caller's document untouched
+	properties := make(map[string]Field, len(doc.Properties)+len(blobs))
+	for key, value := range doc.Properties {
+		properties[key] = value
+	}
+	doc.Properties = properties
+
+	// upload the blobs in a stable xpath order
+	xpaths := make([]string, 0, len(blobs))
+	for xpath := range blobs {
+		xpaths = append(xpaths, xpath)
+	}
+	sort.Strings(xpaths)
+
+	fileIdx := 0
+	for _, xpath := range xpaths {
+		infos := make([]UploadInfo, 0, len(blobs[xpath]))
+		for _, blob := range blobs[xpath] {
+			upload, err := batchUploadManager.UploadBlob(ctx, batch, fileIdx, blob, uploadOptions, nil)
+			if err != nil {
+				r.logger.Error("Failed to upload blob for document", slog.String("error", err.Error()), slog.String("xpath", xpath))
+				return nil, r.cancelBatchOnError(ctx, batch.BatchId, err)
+			}
+			infos = append(infos, UploadInfo{Batch: batch.BatchId, FileId: uploadedFileIdx(upload, fileIdx)})
+			fileIdx++
+		}
+		if len(infos) > 1 || xpath == DocumentPropertyFilesFiles {
+			doc.SetUploadInfoListProperty(xpath, infos...)
+		} else {
+			doc.SetUploadInfoProperty(xpath, infos...)
+		}
+	}
+
+	created, err := r.createDocument(ctx, parentRef, doc, options)
+	if err != nil {
+		return nil, r.cancelBatchOnError(ctx, batch.BatchId, err)
+	}
+	return created, nil
+}
+
+// AttachBlobs uploads the blobs and attaches them to the document property at xpath with default upload options.
+// See AttachBlobsWithOptions.
+func (r *repository) AttachBlobs(ctx context.Context, documentRef string, xpath string, blobs ...*blob) (*Document, error) {
+	return r.AttachBlobsWithOptions(ctx, documentRef, xpath, blobs, nil, nil)
+}
+
+// AttachBlobsWithOptions uploads the blobs into a new batch and attaches them to the document (a repository path or a
+// document ID) at xpath using the Blob.AttachOnDocument operation, then returns the updated document.
+//
+// Blobs are appended to list properties such as "files:files" and replace the value of single blob properties.
+// The batch is cancelled if any step fails.
+func (r *repository) AttachBlobsWithOptions(ctx context.Context, documentRef string, xpath string, blobs []*blob, uploadOptions *BlobUploadOptions, options *nuxeoRequestOptions) (*Document, error) {
+	if len(blobs) == 0 {
+		return r.fetchDocument(ctx, documentRef, options)
+	}
+
+	batchUploadManager := r.client.BatchUploadManager()
+	batch, err := batchUploadManager.CreateBatchWithHandler(ctx, uploadOptions.batchHandler(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for fileIdx, blob := range blobs {
+		if _, err := batchUploadManager.UploadBlob(ctx, batch, fileIdx, blob, uploadOptions, nil); err != nil {
+			r.logger.Error("Failed to upload blob for document", slog.String("error", err.Error()), slog.String("xpath", xpath))
+			return nil, r.cancelBatchOnError(ctx, batch.BatchId, err)
+		}
+	}
+
+	operation := NewOperation(OperationBlobAttachOnDocument).
+		SetParam("document", documentRef).
+		SetParam("xpath", xpath).
+		SetParam("save", true)
+	voidOptions := NewNuxeoRequestOptions().
+		SetRepositoryName(r.name).
+		SetHeader(internal.HeaderXVoidOperation, "true")
+
+	// executing on the whole batch drops it once done
+	if _, err := batchUploadManager.ExecuteBatchUploads(ctx, batch.BatchId, *operation, nil, voidOptions); err != nil {
+		return nil, r.cancelBatchOnError(ctx, batch.BatchId, err)
+	}
+	return r.fetchDocument(ctx, documentRef, options)
+}
+
+// cancelBatchOnError cancels the batch after a failure, returning the original error joined with any cancellation error.
+func (r *repository) cancelBatchOnError(ctx context.Context, batchId string, err error) error {
+	if cancelErr := r.client.BatchUploadManager().CancelBatch(context.WithoutCancel(ctx), batchId, nil); cancelErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to cancel batch %s: %w", batchId, cancelErr))
+	}
+	return err
+}
+
+// uploadedFileIdx returns the file index reported by the server, or the requested one if missing.
+func uploadedFileIdx(upload *batchUpload, fileIdx int) string {
+	if upload != nil && upload.FileIdx != "" {
+		return upload.FileIdx
+	}
+	return strconv.Itoa(fileIdx)
+}
@@ -0,0 +1,178 @@
+package nuxeo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// blobFlowRecorder records the requests of a create/attach blobs flow and serves canned responses.
+type blobFlowRecorder struct {
+	mu        sync.Mutex
+	requests  []string
+	docBody   map[string]any
+	failOn    string
+	chunkHdrs []string
+}
+
+func (b *blobFlowRecorder) respond(req *http.Request) (*http.Response, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	call := req.Method + " " + req.URL.EscapedPath()
+	b.requests = append(b.requests, call)
+	if b.failOn != "" && strings.HasPrefix(call, b.failOn) {
+		return &http.Response{
+			StatusCode: 500,
+			Body:       io.NopCloser(strings.NewReader(`{"entity-type":"exception","message":"boom"}`)),
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+		}, nil
+	}
+
+	jsonResponse := func(body string) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+		}, nil
+	}
+
+	switch {
+	case call == "POST /api/v1/upload/new/default":
+		return jsonResponse(`{"batchId":"batch1"}`)
+	case req.Method == http.MethodPost && strings.Contains(req.URL.EscapedPath(), "/execute/"):
+		if req.Header.Get("X-NXVoidOperation") != "true" {
+			return nil, errors.New("expected void operation")
+		}
+		return testNoContentResponse()
+	case req.Method == http.MethodPost && strings.HasPrefix(req.URL.EscapedPath(), "/api/v1/upload/batch1/"):
+		if req.Header.Get("X-Upload-Type") == "chunked" {
+			b.chunkHdrs = append(b.chunkHdrs, req.Header.Get("X-Upload-Chunk-Index")+"/"+req.Header.Get("X-Upload-Chunk-Count")+"/"+req.Header.Get("X-File-Size"))
+		}
+		io.Copy(io.Discard, req.Body)
+		fileIdx := strings.TrimPrefix(req.URL.EscapedPath(), "/api/v1/upload/batch1/")
+		return jsonResponse(fmt.Sprintf(`{"batchId":"batch1","fileIdx":"%s"}`, fileIdx))
+	case call == "DELETE /api/v1/upload/batch1":
+		return jsonResponse(`{}`)
+	case req.Method == http.MethodPost && strings.HasPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/"):
+		json.NewDecoder(req.Body).Decode(&b.docBody)
+		return jsonResponse(`{"entity-type":"document","uid":"new-doc","title":"doc"}`)
+	case req.Method == http.MethodGet && strings.HasPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/"):
+		return jsonResponse(`{"entity-type":"document","uid":"doc1","title":"doc"}`)
+	}
+	return nil, fmt.Errorf("unexpected request %s", call)
+}
+
+func (b *blobFlowRecorder) called(prefix string) bool {
+	for _, call := range b.requests {
+		if strings.HasPrefix(call, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRepository_CreateDocumentWithBlobs(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		failOn        string
+		wantErr       bool
+		wantCancelled bool
+	}{
+		{name: "success"},
+		{name: "upload failure cancels batch", failOn: "POST /api/v1/upload/batch1/1", wantErr: true, wantCancelled: true},
+		{name: "creation failure cancels batch", failOn: "POST /api/v1/repo/default/path", wantErr: true, wantCancelled: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			recorder := &blobFlowRecorder{failOn: tc.failOn}
+			repo := newTestRepository(recorder.respond)
+			doc := NewDocument("File", "doc")
+			blobs := map[string][]*blob{
+				DocumentPropertyFileContent: {NewBlobFromBytes("main.txt", []byte("0123456789"))},
+				DocumentPropertyFilesFiles:  {NewBlobFromBytes("attachment.txt", []byte("abc"))},
+			}
+
+			created, err := repo.CreateDocumentWithBlobs(context.Background(), "/default-domain/workspaces/ws", *doc, blobs, &BlobUploadOptions{ChunkSize: 4}, nil)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("CreateDocumentWithBlobs() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if cancelled := recorder.called("DELETE /api/v1/upload/batch1"); cancelled != tc.wantCancelled {
+				t.Errorf("batch cancelled = %v, want %v (calls %v)", cancelled, tc.wantCancelled, recorder.requests)
+			}
+			if tc.wantErr {
+				return
+			}
+			if created.ID != "new-doc" {
+				t.Errorf("unexpected document: %+v", created)
+			}
+			if _, found := doc.Properties[DocumentPropertyFileContent]; found {
+				t.Errorf("caller document properties were modified")
+			}
+
+			properties := recorder.docBody["properties"].(map[string]any)
+			fileContent := properties[DocumentPropertyFileContent].(map[string]any)
+			if fileContent["upload-batch"] != "batch1" || fileContent["upload-fileId"] != "0" {
+				t.Errorf("unexpected file:content upload info: %v", fileContent)
+			}
+			files := properties[DocumentPropertyFilesFiles].([]any)
+			fileInfo := files[0].(map[string]any)["file"].(map[string]any)
+			if len(files) != 1 || fileInfo["upload-fileId"] != "1" {
+				t.Errorf("unexpected files:files upload info: %v", files)
+			}
+			wantChunks := []string{"0/3/10", "1/3/10", "2/3/10"}
+			if fmt.Sprint(recorder.chunkHdrs) != fmt.Sprint(wantChunks) {
+				t.Errorf("chunk headers = %v, want %v", recorder.chunkHdrs, wantChunks)
+			}
+		})
+	}
+}
+
+func TestRepository_AttachBlobs(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		failOn        string
+		wantErr       bool
+		wantCancelled bool
+	}{
+		{name: "success"},
+		{name: "operation failure cancels batch", failOn: "POST /api/v1/upload/batch1/execute", wantErr: true, wantCancelled: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			recorder := &blobFlowRecorder{failOn: tc.failOn}
+			repo := newTestRepository(recorder.respond)
+
+			doc, err := repo.AttachBlobs(context.Background(), "doc1", DocumentPropertyFilesFiles, NewBlobFromBytes("a.txt", []byte("a")), NewBlobFromBytes("b.txt", []byte("b")))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("AttachBlobs() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if cancelled := recorder.called("DELETE /api/v1/upload/batch1"); cancelled != tc.wantCancelled {
+				t.Errorf("batch cancelled = %v, want %v (calls %v)", cancelled, tc.wantCancelled, recorder.requests)
+			}
+			if tc.wantErr {
+				return
+			}
+			if doc.ID != "doc1" {
+				t.Errorf("unexpected document: %+v", doc)
+			}
+			for _, call := range []string{"POST /api/v1/upload/batch1/0", "POST /api/v1/upload/batch1/1", "POST /api/v1/upload/batch1/execute/Blob.AttachOnDocument", "GET /api/v1/repo/default/id/doc1"} {
+				if !recorder.called(call) {
+					t.Errorf("expected call %q, got %v", call, recorder.requests)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package nuxeo
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+///////////////////////
+//// BULK DOWNLOAD ////
+///////////////////////
+
+// BulkDownloadManifestName is the default name of the manifest entry written into bulk download archives.
+const BulkDownloadManifestName = "manifest.json"
+
+// BulkDownloadOptions configures a bulk blob download.
+type BulkDownloadOptions struct {
+	// XPaths lists the blob properties to download; defaults to "file:content" and "files:files"
+	XPaths []string
+	// ManifestName is the name of the manifest entry; defaults to BulkDownloadManifestName
+	ManifestName string
+	// PageSize is the page size used when paging through NXQL query results
+	PageSize int
+}
+
+// BulkDownloadManifest lists the blobs written into a bulk download archive.
+type BulkDownloadManifest struct {
+	CreatedAt ISO8601Time                 `json:"createdAt"`
+	Entries   []BulkDownloadManifestEntry `json:"entries"`
+}
+
+// BulkDownloadManifestEntry describes a single blob written into a bulk download archive.
+type BulkDownloadManifestEntry struct {
+	DocumentId      string `json:"uid"`
+	DocumentPath    string `json:"path"`
+	XPath           string `json:"xpath"`
+	Entry           string `json:"entry"`
+	Filename        string `json:"filename"`
+	MimeType        string `json:"mimeType"`
+	Length          int64  `json:"length"`
+	DigestAlgorithm string `json:"digestAlgorithm"`
+	Digest          string `json:"digest"`
+}
+
+// BulkDownloadByRefs writes all blobs of the given documents into a zip archive streamed to w.
+// Each document reference is either a repository path or a document ID.
+//
+// Archive entries are named after the blob filename, with " (n)" suffixes on collisions, and a JSON manifest listing
+// the document UID, path and digest of each blob is written as the last entry. Blobs are streamed one at a time.
+func (r *repository) BulkDownloadByRefs(ctx context.Context, documentRefs []string, w io.Writer, options *BulkDownloadOptions) (*BulkDownloadManifest, error) {
+	requestOptions := bulkDownloadRequestOptions(options)
+	return r.bulkDownload(ctx, w, options, func(yield func(*Document, error) bool) {
+		for _, documentRef := range documentRefs {
+			doc, err := r.fetchDocument(ctx, documentRef, requestOptions)
+			if !yield(doc, err) || err != nil {
+				return
+			}
+		}
+	})
+}
+
+// BulkDownloadByQuery writes all blobs of the documents matching the NXQL query into a zip archive streamed to w.
+//
+// See BulkDownloadByRefs for the archive layout.
+func (r *repository) BulkDownloadByQuery(ctx context.Context, query string, queryParams []string, w io.Writer, options *BulkDownloadOptions) (*BulkDownloadManifest, error) {
+	pageSize := 0
+	if options != nil {
+		pageSize = options.PageSize
+	}
+	return r.bulkDownload(ctx, w, options, r.QueryAll(ctx, query, queryParams, pageSize, bulkDownloadRequestOptions(options).SetRepositoryName(r.name)))
+}
+
+// bulkDownload streams the blobs of all documents yielded by docs into a zip archive written to w.
+func (r *repository) bulkDownload(ctx context.Context, w io.Writer, options *BulkDownloadOptions, docs func(yield func(*Document, error) bool)) (*BulkDownloadManifest, error) {
+	xpaths := []string{DocumentPropertyFileContent, DocumentPropertyFilesFiles}
+	manifestName := BulkDownloadManifestName
+	if options != nil {
+		if len(options.XPaths) > 0 {
+			xpaths = options.XPaths
+		}
+		if options.ManifestName != "" {
+			manifestName = options.ManifestName
+		}
+	}
+
+	manifest := &BulkDownloadManifest{
+		CreatedAt: ISO8601Time(time.Now().UTC()),
+		Entries:   []BulkDownloadManifestEntry{},
+	}
+	names := newUniqueNames()
+	names.reserve(manifestName)
+	archive := zip.NewWriter(w)
+
+	for doc, err := range docs {
+		if err != nil {
+			r.logger.Error("Failed to list documents for bulk download", slog.String("error", err.Error()))
+			return nil, err
+		}
+		for _, xpath := range xpaths {
+			for _, docBlob := range documentBlobs(doc, xpath) {
+				entry := BulkDownloadManifestEntry{
+					DocumentId:      doc.ID,
+					DocumentPath:    doc.Path,
+					XPath:           docBlob.xpath,
+					Entry:           names.next(docBlob.blob.Filename),
+					Filename:        docBlob.blob.Filename,
+					MimeType:        docBlob.blob.MimeType,
+					DigestAlgorithm: docBlob.blob.DigestAlgorithm,
+					Digest:          docBlob.blob.Digest,
+				}
+				length, err := r.writeBlobEntry(ctx, archive, doc, entry)
+				if err != nil {
+					r.logger.Error("Failed to write blob into bulk download", slog.String("error", err.Error()), slog.String("uid", doc.ID), slog.String("xpath", docBlob.xpath))
+					return nil, err
+				}
+				entry.Length = length
+				manifest.Entries = append(manifest.Entries, entry)
+			}
+		}
+	}
+
+	manifestWriter, err := archive.Create(manifestName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create manifest entry: %w", err)
+	}
+	encoder := json.NewEncoder(manifestWriter)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close archive: %w", err)
+	}
+	return manifest, nil
+}
+
+// writeBlobEntry streams a single document blob into the archive, returning the number of bytes written.
+func (r *repository) writeBlobEntry(ctx context.Context, archive *zip.Writer, doc *Document, entry BulkDownloadManifestEntry) (int64, error) {
+	stream, err := r.StreamBlobById(ctx, doc.ID, entry.XPath, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer stream.Close()
+
+	header := &zip.FileHeader{
+		Name:   entry.Entry,
+		Method: zip.Deflate,
+	}
+	if doc.LastModified != nil {
+		header.Modified = time.Time(*doc.LastModified)
+	}
+	entryWriter, err := archive.CreateHeader(header)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create archive entry: %w", err)
+	}
+	return io.Copy(entryWriter, stream)
+}
+
+// bulkDownloadRequestOptions returns the request options fetching the schemas holding the downloaded blobs.
+func bulkDownloadRequestOptions(options *BulkDownloadOptions) *nuxeoRequestOptions {
+	xpaths := []string{DocumentPropertyFileContent, DocumentPropertyFilesFiles}
+	if options != nil && len(options.XPaths) > 0 {
+		xpaths = options.XPaths
+	}
+	schemas := []string{}
+	for _, xpath := range xpaths {
+		if schema, _, found := strings.Cut(xpath, ":"); found && !strings.Contains(schema, "/") {
+			schemas = append(schemas, schema)
+		}
+	}
+	if len(schemas) == 0 {
+		schemas = []string{"*"}
+	}
+	return NewNuxeoRequestOptions().SetSchemas(schemas)
+}
+
+//////////////////////
+//// BLOB HELPERS ////
+//////////////////////
+
+// documentBlob is a blob property value of a document along with its xpath.
+type documentBlob struct {
+	xpath string
+	blob  blob
+}
+
+// documentBlobs returns the blobs stored in the document property at xpath.
+// Supports single blob properties (e.g. "file:content"), blob lists, and lists of complex items holding a "file" blob (e.g. "files:files").
+func documentBlobs(doc *Document, xpath string) []documentBlob {
+	field, found := doc.Property(xpath)
+	if !found || field.IsNull() {
+		return nil
+	}
+
+	var single blob
+	if err := field.Complex(&single); err == nil {
+		if isBlobValue(single) {
+			return []documentBlob{{xpath: xpath, blob: single}}
+		}
+		return nil
+	}
+
+	var items []map[string]Field
+	if err := field.ComplexList(&items); err != nil {
+		return nil
+	}
+	docBlobs := []documentBlob{}
+	for i, item := range items {
+		itemXPath := xpath + "/" + strconv.Itoa(i)
+		if fileField, ok := item["file"]; ok {
+			var itemBlob blob
+			if err := fileField.Complex(&itemBlob); err == nil && isBlobValue(itemBlob) {
+				docBlobs = append(docBlobs, documentBlob{xpath: itemXPath + "/file", blob: itemBlob})
+			}
+			continue
+		}
+		itemBytes, _ := json.Marshal(item)
+		var itemBlob blob
+		if err := json.Unmarshal(itemBytes, &itemBlob); err == nil && isBlobValue(itemBlob) {
+			docBlobs = append(docBlobs, documentBlob{xpath: itemXPath, blob: itemBlob})
+		}
+	}
+	return docBlobs
+}
+
+// isBlobValue returns true if the decoded blob carries blob metadata.
+func isBlobValue(b blob) bool {
+	return b.Filename != "" || b.Data != "" || b.Digest != ""
+}
+
+// uniqueNames hands out file names that do not collide with previously handed out ones (case-insensitively).
+type uniqueNames struct {
+	taken map[string]bool
+}
+
+func newUniqueNames() *uniqueNames {
+	return &uniqueNames{
+		taken: make(map[string]bool),
+	}
+}
+
+// reserve marks a name as taken.
+func (u *uniqueNames) reserve(name string) {
+	u.taken[strings.ToLower(name)] = true
+}
+
+// next returns a sanitized, unique variant of name, appending " (n)" before the extension on collisions.
+func (u *uniqueNames) next(name string) string {
+	name = sanitizeFilename(name)
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	candidate := name
+	for i := 1; u.taken[strings.ToLower(candidate)]; i++ {
+		candidate = fmt.Sprintf("%s (%d)%s", base, i, ext)
+	}
+	u.reserve(candidate)
+	return candidate
+}
+
+// sanitizeFilename strips path separators and reserved characters from a file name.
+func sanitizeFilename(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		if r < 0x20 {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == ".." {
+		return "blob"
+	}
+	return name
+}
@@ -0,0 +1,188 @@
+package nuxeo
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// bulkDownloadTestResponder serves documents with blobs and their blob streams.
+func bulkDownloadTestResponder(t *testing.T) func(req *http.Request) (*http.Response, error) {
+	docs := map[string]any{
+		"doc1": map[string]any{
+			"entity-type": "document",
+			"uid":         "doc1",
+			"path":        "/ws/doc1",
+			"properties": map[string]any{
+				"file:content": map[string]any{"name": "report.pdf", "mime-type": "application/pdf", "digestAlgorithm": "MD5", "digest": "d1"},
+				"files:files": []any{
+					map[string]any{"file": map[string]any{"name": "report.pdf", "mime-type": "application/pdf", "digest": "d2"}},
+					map[string]any{"file": map[string]any{"name": "notes.txt", "mime-type": "text/plain", "digest": "d3"}},
+				},
+			},
+		},
+		"doc2": map[string]any{
+			"entity-type": "document",
+			"uid":         "doc2",
+			"path":        "/ws/doc2",
+			"properties": map[string]any{
+				"file:content": map[string]any{"name": "Report.pdf", "mime-type": "application/pdf", "digest": "d4"},
+				"files:files":  []any{},
+			},
+		},
+	}
+	return func(req *http.Request) (*http.Response, error) {
+		path := req.URL.EscapedPath()
+		switch {
+		case strings.Contains(path, "/@blob/"):
+			return &http.Response{
+				StatusCode: 200,
+				Body:       io.NopCloser(strings.NewReader("content of " + path[strings.Index(path, "/@blob/")+7:])),
+				Header:     http.Header{"Content-Type": []string{"application/octet-stream"}},
+			}, nil
+		case path == "/api/v1/query":
+			if req.Header.Get("X-NXRepository") != "default" {
+				t.Errorf("query sent to repository %q", req.Header.Get("X-NXRepository"))
+			}
+			return testJsonResponse(t, 200, map[string]any{"entity-type": "documents", "entries": []any{docs["doc1"], docs["doc2"]}})
+		case strings.HasPrefix(path, "/api/v1/repo/default/id/"):
+			doc, ok := docs[strings.TrimPrefix(path, "/api/v1/repo/default/id/")]
+			if !ok {
+				return testJsonResponse(t, 404, map[string]any{"entity-type": "exception", "message": "not found"})
+			}
+			return testJsonResponse(t, 200, doc)
+		}
+		return nil, errors.New("unexpected request: " + path)
+	}
+}
+
+// readTestZip returns the content of each zip entry by name.
+func readTestZip(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("failed to read zip: %v", err)
+	}
+	entries := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open zip entry: %v", err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		entries[f.Name] = string(content)
+	}
+	return entries
+}
+
+func TestRepository_BulkDownloadByRefs(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		refs        []string
+		options     *BulkDownloadOptions
+		wantErr     bool
+		wantEntries map[string]string
+	}{
+		{
+			name: "success with collisions",
+			refs: []string{"doc1", "doc2"},
+			wantEntries: map[string]string{
+				"report.pdf":     "content of file:content",
+				"report (1).pdf": "content of files:files/0/file",
+				"notes.txt":      "content of files:files/1/file",
+				"Report (2).pdf": "content of file:content",
+			},
+		},
+		{
+			name:    "only file content",
+			refs:    []string{"doc1"},
+			options: &BulkDownloadOptions{XPaths: []string{DocumentPropertyFileContent}, ManifestName: "index.json"},
+			wantEntries: map[string]string{
+				"report.pdf": "content of file:content",
+			},
+		},
+		{
+			name:    "missing document",
+			refs:    []string{"doc1", "missing"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			repo := newTestRepository(bulkDownloadTestResponder(t))
+			var buf bytes.Buffer
+			manifest, err := repo.BulkDownloadByRefs(context.Background(), tc.refs, &buf, tc.options)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("BulkDownloadByRefs() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if len(manifest.Entries) != len(tc.wantEntries) {
+				t.Errorf("manifest entries: got %d, want %d", len(manifest.Entries), len(tc.wantEntries))
+			}
+
+			manifestName := BulkDownloadManifestName
+			if tc.options != nil && tc.options.ManifestName != "" {
+				manifestName = tc.options.ManifestName
+			}
+			entries := readTestZip(t, buf.Bytes())
+			for name, want := range tc.wantEntries {
+				if got := entries[name]; got != want {
+					t.Errorf("entry %q: got %q, want %q", name, got, want)
+				}
+			}
+			var written BulkDownloadManifest
+			if err := json.Unmarshal([]byte(entries[manifestName]), &written); err != nil {
+				t.Fatalf("failed to decode manifest: %v", err)
+			}
+			for i, entry := range written.Entries {
+				if entry.Entry != manifest.Entries[i].Entry || entry.DocumentId == "" || entry.Digest == "" || entry.Length == 0 {
+					t.Errorf("unexpected manifest entry: %+v", entry)
+				}
+			}
+		})
+	}
+}
+
+func TestRepository_BulkDownloadByQuery(t *testing.T) {
+	t.Parallel()
+	repo := newTestRepository(bulkDownloadTestResponder(t))
+	var buf bytes.Buffer
+	manifest, err := repo.BulkDownloadByQuery(context.Background(), "SELECT * FROM File", nil, &buf, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(manifest.Entries) != 4 {
+		t.Errorf("manifest entries: got %d, want 4", len(manifest.Entries))
+	}
+	if manifest.Entries[3].DocumentPath != "/ws/doc2" || manifest.Entries[3].Digest != "d4" {
+		t.Errorf("unexpected manifest entry: %+v", manifest.Entries[3])
+	}
+	if entries := readTestZip(t, buf.Bytes()); len(entries) != 5 {
+		t.Errorf("zip entries: got %d, want 5", len(entries))
+	}
+}
+
+func TestUniqueNames_Next(t *testing.T) {
+	t.Parallel()
+	names := newUniqueNames()
+	names.reserve("manifest.json")
+	inputs := []string{"a.txt", "A.txt", "a.txt", "dir/b.txt", "", "manifest.json"}
+	want := []string{"a.txt", "A (1).txt", "a (2).txt", "dir_b.txt", "blob", "manifest (1).json"}
+	for i, input := range inputs {
+		if got := names.next(input); got != want[i] {
+			t.Errorf("next(%q): got %q, want %q", input, got, want[i])
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package nuxeo
+
+import (
+	"context"
+)
+
+/////////////////////
+//// COLLECTIONS ////
+/////////////////////
+
+// collectionMembersQuery lists the live members of a collection.
+const collectionMembersQuery = "SELECT * FROM Document WHERE collectionMember:collectionIds/* = ? AND ecm:isVersion = 0 AND ecm:isTrashed = 0"
+
+// documentCollectionsQuery lists the collections holding a document.
+const documentCollectionsQuery = "SELECT * FROM Document WHERE ecm:mixinType = 'Collection' AND collection:documentIds/* = ? AND ecm:isTrashed = 0"
+
+// CreateCollection creates a collection in the collections folder of the current user.
+// Uses the Collection.Create operation.
+// Returns the created collection Document or error.
+func (r *repository) CreateCollection(ctx context.Context, name string, description string) (*Document, error) {
+	operation := NewOperation(OperationCollectionCreate).SetParam("name", name)
+	if description != "" {
+		operation.SetParam("description", description)
+	}
+	return r.executeWithoutInput(ctx, operation)
+}
+
+// AddToCollection adds documents (repository paths or document IDs) to a collection.
+// Uses the Document.AddToCollection operation.
+// Returns the added Documents or error.
+func (r *repository) AddToCollection(ctx context.Context, collectionRef string, documentRefs ...string) (*Documents, error) {
+	operation := NewOperation(OperationDocumentAddToCollection).SetParam("collection", collectionRef)
+	return r.executeForDocuments(ctx, operation, documentRefs)
+}
+
+// RemoveFromCollection removes documents (repository paths or document IDs) from a collection.
+// Uses the Document.RemoveFromCollection operation.
+// Returns the removed Documents or error.
+func (r *repository) RemoveFromCollection(ctx context.Context, collectionRef string, documentRefs ...string) (*Documents, error) {
+	operation := NewOperation(OperationDocumentRemoveFromCollection).SetParam("collection", collectionRef)
+	return r.executeForDocuments(ctx, operation, documentRefs)
+}
+
+// FetchCollectionMembers retrieves a page of the documents of a collection (a repository path or a document ID).
+// Returns the member Documents or error.
+func (r *repository) FetchCollectionMembers(ctx context.Context, collectionRef string, paginationOptions *SortedPaginationOptions, options *nuxeoRequestOptions) (*Documents, error) {
+	collection, err := r.fetchDocument(ctx, collectionRef, nil)
+	if err != nil {
+		return nil, err
+	}
+	return r.Query(ctx, collectionMembersQuery, []string{collection.ID}, paginationOptions, r.requestOptions(options))
+}
+
+// FetchDocumentCollections retrieves a page of the collections a document (a repository path or a document ID) belongs to.
+// Returns the collection Documents or error.
+func (r *repository) FetchDocumentCollections(ctx context.Context, documentRef string, paginationOptions *SortedPaginationOptions, options *nuxeoRequestOptions) (*Documents, error) {
+	doc, err := r.fetchDocument(ctx, documentRef, nil)
+	if err != nil {
+		return nil, err
+	}
+	return r.Query(ctx, documentCollectionsQuery, []string{doc.ID}, paginationOptions, r.requestOptions(options))
+}
+
+///////////////////
+//// FAVORITES ////
+///////////////////
+
+// AddToFavorites adds documents (repository paths or document IDs) to the favorites of the current user.
+// Uses the Document.AddToFavorites operation.
+// Returns the added Documents or error.
+func (r *repository) AddToFavorites(ctx context.Context, documentRefs ...string) (*Documents, error) {
+	return r.executeForDocuments(ctx, NewOperation(OperationDocumentAddToFavorites), documentRefs)
+}
+
+// RemoveFromFavorites removes documents (repository paths or document IDs) from the favorites of the current user.
+// Uses the Document.RemoveFromFavorites operation.
+// Returns the removed Documents or error.
+func (r *repository) RemoveFromFavorites(ctx context.Context, documentRefs ...string) (*Documents, error) {
+	return r.executeForDocuments(ctx, NewOperation(OperationDocumentRemoveFromFavorites), documentRefs)
+}
+
+// FetchFavorites retrieves a page of the favorite documents of the current user.
+// Uses the Favorite.Fetch operation to find the favorites collection of the user.
+// Returns the favorite Documents or error.
+func (r *repository) FetchFavorites(ctx context.Context, paginationOptions *SortedPaginationOptions, options *nuxeoRequestOptions) (*Documents, error) {
+	favorites, err := r.executeWithoutInput(ctx, NewOperation(OperationFavoriteFetch))
+	if err != nil {
+		return nil, err
+	}
+	return r.Query(ctx, collectionMembersQuery, []string{favorites.ID}, paginationOptions, r.requestOptions(options))
+}
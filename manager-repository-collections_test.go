@@ -0,0 +1,152 @@
+package nuxeo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// collectionsTestServer serves the documents "a", "b" and "c" at /ws/{id}, and the collections of members, among
+// which "favorites" is the favorites collection of the current user.
+type collectionsTestServer struct {
+	t       *testing.T
+	mu      sync.Mutex
+	members map[string][]string
+}
+
+func (srv *collectionsTestServer) respond(req *http.Request) (*http.Response, error) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	doc := func(id string) map[string]any {
+		return map[string]any{"entity-type": "document", "uid": id, "path": "/ws/" + id}
+	}
+	docs := func(ids []string) (*http.Response, error) {
+		entries := []any{}
+		for _, id := range ids {
+			entries = append(entries, doc(id))
+		}
+		return testJsonResponse(srv.t, 200, map[string]any{"entity-type": "documents", "entries": entries})
+	}
+
+	if id, found := strings.CutPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/path/ws/"); found {
+		return testJsonResponse(srv.t, 200, doc(id))
+	}
+	if req.URL.EscapedPath() == "/api/v1/query" {
+		if req.Header.Get("X-NXRepository") != "default" {
+			srv.t.Errorf("query sent to repository %q", req.Header.Get("X-NXRepository"))
+		}
+		query, param := req.URL.Query().Get("query"), req.URL.Query().Get("queryParams")
+		if strings.Contains(query, "collection:documentIds") {
+			collections := []string{}
+			for _, collection := range slices.Sorted(maps.Keys(srv.members)) {
+				if slices.Contains(srv.members[collection], param) {
+					collections = append(collections, collection)
+				}
+			}
+			return docs(collections)
+		}
+		return docs(srv.members[param])
+	}
+
+	operationId, found := strings.CutPrefix(req.URL.EscapedPath(), "/site/automation/")
+	if !found {
+		return nil, errors.New("unexpected request " + req.URL.String())
+	}
+	var payload operationPayload
+	if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+		return nil, err
+	}
+	collection := strings.TrimPrefix(payload.Params["collection"], "/ws/")
+	if strings.HasSuffix(operationId, "Favorites") {
+		collection = "favorites"
+	}
+	ids := strings.Split(payload.Input[strings.Index(payload.Input, ":")+1:], ",")
+	for i := range ids {
+		ids[i] = strings.TrimPrefix(ids[i], "/ws/")
+	}
+	switch operationId {
+	case OperationCollectionCreate:
+		srv.members[payload.Params["name"]] = []string{}
+		return testJsonResponse(srv.t, 200, doc(payload.Params["name"]))
+	case OperationFavoriteFetch:
+		return testJsonResponse(srv.t, 200, doc("favorites"))
+	case OperationDocumentAddToCollection, OperationDocumentAddToFavorites:
+		srv.members[collection] = append(srv.members[collection], ids...)
+	case OperationDocumentRemoveFromCollection, OperationDocumentRemoveFromFavorites:
+		srv.members[collection] = slices.DeleteFunc(srv.members[collection], func(id string) bool { return slices.Contains(ids, id) })
+	}
+	if len(ids) == 1 {
+		return testJsonResponse(srv.t, 200, doc(ids[0]))
+	}
+	return docs(ids)
+}
+
+func TestRepository_Collections(t *testing.T) {
+	t.Parallel()
+	srv := &collectionsTestServer{t: t, members: map[string][]string{"other": {"a"}}}
+	repo := newTestRepository(srv.respond)
+	ctx := context.Background()
+
+	collection, err := repo.CreateCollection(ctx, "reading", "to read")
+	if err != nil || collection.ID != "reading" {
+		t.Fatalf("CreateCollection() = %+v, %v", collection, err)
+	}
+	added, err := repo.AddToCollection(ctx, "reading", "/ws/a", "/ws/b", "/ws/c")
+	if err != nil || len(added.Entries) != 3 {
+		t.Fatalf("AddToCollection() = %+v, %v", added, err)
+	}
+	if _, err := repo.RemoveFromCollection(ctx, "/ws/reading", "/ws/b"); err != nil {
+		t.Fatalf("RemoveFromCollection() error = %v", err)
+	}
+
+	members, err := repo.FetchCollectionMembers(ctx, "/ws/reading", nil, nil)
+	if err != nil {
+		t.Fatalf("FetchCollectionMembers() error = %v", err)
+	}
+	if got := documentIds(members); got != "a,c" {
+		t.Errorf("FetchCollectionMembers() = %s, want a,c", got)
+	}
+	collections, err := repo.FetchDocumentCollections(ctx, "/ws/a", nil, nil)
+	if err != nil {
+		t.Fatalf("FetchDocumentCollections() error = %v", err)
+	}
+	if got := documentIds(collections); got != "other,reading" {
+		t.Errorf("FetchDocumentCollections() = %s, want other,reading", got)
+	}
+}
+
+func TestRepository_Favorites(t *testing.T) {
+	t.Parallel()
+	srv := &collectionsTestServer{t: t, members: map[string][]string{}}
+	repo := newTestRepository(srv.respond)
+	ctx := context.Background()
+
+	if _, err := repo.AddToFavorites(ctx, "/ws/a", "/ws/b"); err != nil {
+		t.Fatalf("AddToFavorites() error = %v", err)
+	}
+	if _, err := repo.RemoveFromFavorites(ctx, "/ws/a"); err != nil {
+		t.Fatalf("RemoveFromFavorites() error = %v", err)
+	}
+	favorites, err := repo.FetchFavorites(ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("FetchFavorites() error = %v", err)
+	}
+	if got := documentIds(favorites); got != "b" {
+		t.Errorf("FetchFavorites() = %s, want b", got)
+	}
+}
+
+// documentIds joins the IDs of the documents.
+func documentIds(docs *Documents) string {
+	ids := make([]string, len(docs.Entries))
+	for i, doc := range docs.Entries {
+		ids[i] = doc.ID
+	}
+	return strings.Join(ids, ",")
+}
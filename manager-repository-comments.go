@@ -0,0 +1,160 @@
+package nuxeo
+
+import (
+	"context"
+	"iter"
+	"log/slog"
+	"net/url"
+
+	"github.com/anselm94/nuxeo-go-client/internal"
+)
+
+//////////////////
+//// COMMENTS ////
+//////////////////
+
+// commentsPath returns the API path of the comments of a document or of the replies to a comment.
+func (r *repository) commentsPath(parentId string) string {
+	return internal.PathApiV1 + "/repo/" + url.PathEscape(r.name) + "/id/" + url.PathEscape(parentId) + "/@comment"
+}
+
+// FetchComments retrieves a page of the comments on a document, or of the replies to a comment.
+// Maps to GET /api/v1/repo/{repo}/id/{id}/@comment
+// Returns entityComments or error.
+func (r *repository) FetchComments(ctx context.Context, parentId string, paginationOptions *PaginationOptions, options *nuxeoRequestOptions) (*Comments, error) {
+	path := r.commentsPath(parentId)
+	if params := paginationOptions.QueryParams(); len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+	res, err := r.client.NewRequest(ctx, options).SetResult(&Comments{}).SetError(&NuxeoError{}).Get(path)
+
+	if err := handleNuxeoError(err, res); err != nil {
+		r.logger.Error("Failed to fetch comments", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return res.Result().(*Comments), nil
+}
+
+// FetchComment retrieves a comment on a document by its ID.
+// Maps to GET /api/v1/repo/{repo}/id/{id}/@comment/{commentId}
+// Returns entityComment or error.
+func (r *repository) FetchComment(ctx context.Context, documentId string, commentId string, options *nuxeoRequestOptions) (*Comment, error) {
+	path := r.commentsPath(documentId) + "/" + url.PathEscape(commentId)
+	res, err := r.client.NewRequest(ctx, options).SetResult(&Comment{}).SetError(&NuxeoError{}).Get(path)
+
+	if err := handleNuxeoError(err, res); err != nil {
+		r.logger.Error("Failed to fetch comment", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return res.Result().(*Comment), nil
+}
+
+// CreateComment adds a comment on a document.
+// Maps to POST /api/v1/repo/{repo}/id/{id}/@comment
+// Returns the created entityComment or error.
+func (r *repository) CreateComment(ctx context.Context, documentId string, text string, options *nuxeoRequestOptions) (*Comment, error) {
+	res, err := r.client.NewRequest(ctx, options).SetBody(NewComment(documentId, text)).SetResult(&Comment{}).SetError(&NuxeoError{}).Post(r.commentsPath(documentId))
+
+	if err := handleNuxeoError(err, res); err != nil {
+		r.logger.Error("Failed to create comment", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return res.Result().(*Comment), nil
+}
+
+// ReplyToComment adds a reply to a comment, or to another reply, forming a thread.
+// Maps to POST /api/v1/repo/{repo}/id/{commentId}/@comment
+// Returns the created reply entityComment or error.
+func (r *repository) ReplyToComment(ctx context.Context, commentId string, text string, options *nuxeoRequestOptions) (*Comment, error) {
+	return r.CreateComment(ctx, commentId, text, options)
+}
+
+// UpdateComment changes the text of a comment on a document.
+// Maps to PUT /api/v1/repo/{repo}/id/{id}/@comment/{commentId}
+// Returns the updated entityComment or error.
+func (r *repository) UpdateComment(ctx context.Context, documentId string, commentId string, text string, options *nuxeoRequestOptions) (*Comment, error) {
+	path := r.commentsPath(documentId) + "/" + url.PathEscape(commentId)
+	comment := NewComment(documentId, text)
+	comment.Id = commentId
+	res, err := r.client.NewRequest(ctx, options).SetBody(comment).SetResult(&Comment{}).SetError(&NuxeoError{}).Put(path)
+
+	if err := handleNuxeoError(err, res); err != nil {
+		r.logger.Error("Failed to update comment", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return res.Result().(*Comment), nil
+}
+
+// DeleteComment deletes a comment on a document, along with its replies.
+// Maps to DELETE /api/v1/repo/{repo}/id/{id}/@comment/{commentId}
+// Returns error if deletion fails.
+func (r *repository) DeleteComment(ctx context.Context, documentId string, commentId string) error {
+	path := r.commentsPath(documentId) + "/" + url.PathEscape(commentId)
+	res, err := r.client.NewRequest(ctx, nil).SetError(&NuxeoError{}).Delete(path)
+
+	if err := handleNuxeoError(err, res); err != nil {
+		r.logger.Error("Failed to delete comment", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// FetchCommentThread retrieves a comment on a document along with all its replies, recursively, oldest first.
+// Returns the CommentThread rooted at the comment, or error.
+func (r *repository) FetchCommentThread(ctx context.Context, documentId string, commentId string) (*CommentThread, error) {
+	comment, err := r.FetchComment(ctx, documentId, commentId, repliesSummaryOptions())
+	if err != nil {
+		return nil, err
+	}
+	thread := &CommentThread{Comment: comment}
+	if err := r.fetchReplies(ctx, thread); err != nil {
+		return nil, err
+	}
+	return thread, nil
+}
+
+// fetchReplies fills the replies of the thread, recursively.
+func (r *repository) fetchReplies(ctx context.Context, thread *CommentThread) error {
+	if thread.NumberOfReplies == 0 {
+		return nil
+	}
+	for reply, err := range r.allComments(ctx, thread.Id) {
+		if err != nil {
+			return err
+		}
+		replyThread := &CommentThread{Comment: reply}
+		if err := r.fetchReplies(ctx, replyThread); err != nil {
+			return err
+		}
+		thread.Replies = append(thread.Replies, replyThread)
+	}
+	return nil
+}
+
+// repliesSummaryOptions returns the request options fetching the number of replies of comments, which the server only
+// fills in on request.
+func repliesSummaryOptions() *nuxeoRequestOptions {
+	return NewNuxeoRequestOptions().SetFetchPropertiesForComment([]string{FetchPropertyCommentRepliesSummary})
+}
+
+// allComments iterates over all comments on the document or replies to the comment, paging through them lazily, along
+// with their number of replies.
+func (r *repository) allComments(ctx context.Context, parentId string) iter.Seq2[*Comment, error] {
+	return func(yield func(*Comment, error) bool) {
+		for pageIndex := 0; ; pageIndex++ {
+			comments, err := r.FetchComments(ctx, parentId, &PaginationOptions{CurrentPageIndex: pageIndex}, repliesSummaryOptions())
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for i := range comments.Entries {
+				if !yield(&comments.Entries[i], nil) {
+					return
+				}
+			}
+			if !comments.IsNextPageAvailable || len(comments.Entries) == 0 {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package nuxeo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// commentsTestServer stores comments in memory, numbered c1, c2... in creation order, and pages them two at a time.
+// Like the server, it only counts the replies of the comments it returns when asked for the replies summary.
+type commentsTestServer struct {
+	t        *testing.T
+	mu       sync.Mutex
+	comments []*Comment
+}
+
+func (srv *commentsTestServer) respond(req *http.Request) (*http.Response, error) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	find := func(id string) (int, *Comment) {
+		for i, comment := range srv.comments {
+			if comment.Id == id {
+				return i, comment
+			}
+		}
+		return -1, nil
+	}
+	summarize := func(comment Comment) Comment {
+		if req.Header.Get("fetch-"+EntityTypeComment) == FetchPropertyCommentRepliesSummary {
+			for _, reply := range srv.comments {
+				if reply.ParentId == comment.Id {
+					comment.NumberOfReplies++
+				}
+			}
+		}
+		return comment
+	}
+	replies := func(parentId string) []Comment {
+		replies := []Comment{}
+		for _, comment := range srv.comments {
+			if comment.ParentId == parentId {
+				replies = append(replies, summarize(*comment))
+			}
+		}
+		return replies
+	}
+
+	parentId, commentId, found := strings.Cut(strings.TrimPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/id/"), "/@comment")
+	if !found {
+		return nil, errors.New("unexpected request " + req.URL.String())
+	}
+	commentId = strings.TrimPrefix(commentId, "/")
+	switch {
+	case commentId == "" && req.Method == http.MethodGet:
+		pageIndex, _ := strconv.Atoi(req.URL.Query().Get("currentPageIndex"))
+		all := replies(parentId)
+		entries := all[min(2*pageIndex, len(all)):min(2*pageIndex+2, len(all))]
+		return testJsonResponse(srv.t, 200, map[string]any{"entity-type": "comments", "entries": entries, "isNextPageAvailable": 2*pageIndex+2 < len(all)})
+	case commentId == "" && req.Method == http.MethodPost:
+		var comment Comment
+		if err := json.NewDecoder(req.Body).Decode(&comment); err != nil {
+			return nil, err
+		}
+		if comment.ParentId != parentId || comment.EntityType != EntityTypeComment {
+			srv.t.Errorf("posted %+v on %s", comment, parentId)
+		}
+		comment.Id, comment.Author = fmt.Sprintf("c%d", len(srv.comments)+1), "Administrator"
+		srv.comments = append(srv.comments, &comment)
+		return testJsonResponse(srv.t, 201, comment)
+	}
+	i, comment := find(commentId)
+	if comment == nil {
+		return testNotFoundResponse(srv.t)
+	}
+	switch req.Method {
+	case http.MethodPut:
+		var update Comment
+		if err := json.NewDecoder(req.Body).Decode(&update); err != nil {
+			return nil, err
+		}
+		comment.Text = update.Text
+	case http.MethodDelete:
+		srv.comments = append(srv.comments[:i], srv.comments[i+1:]...)
+		return testNoContentResponse()
+	}
+	return testJsonResponse(srv.t, 200, summarize(*comment))
+}
+
+func TestRepository_Comments(t *testing.T) {
+	t.Parallel()
+	srv := &commentsTestServer{t: t}
+	repo := newTestRepository(srv.respond)
+	ctx := context.Background()
+
+	for _, text := range []string{"first", "second", "third"} {
+		if _, err := repo.CreateComment(ctx, "doc", text, nil); err != nil {
+			t.Fatalf("CreateComment() error = %v", err)
+		}
+	}
+	reply, err := repo.ReplyToComment(ctx, "c1", "reply", nil)
+	if err != nil || reply.ParentId != "c1" || reply.Author != "Administrator" {
+		t.Fatalf("ReplyToComment() = %+v, %v", reply, err)
+	}
+	updated, err := repo.UpdateComment(ctx, "doc", "c2", "second, edited", nil)
+	if err != nil || updated.Text != "second, edited" {
+		t.Fatalf("UpdateComment() = %+v, %v", updated, err)
+	}
+	if err := repo.DeleteComment(ctx, "doc", "c3"); err != nil {
+		t.Fatalf("DeleteComment() error = %v", err)
+	}
+
+	page, err := repo.FetchComments(ctx, "doc", &PaginationOptions{CurrentPageIndex: 0}, nil)
+	if err != nil {
+		t.Fatalf("FetchComments() error = %v", err)
+	}
+	texts := []string{}
+	for _, comment := range page.Entries {
+		texts = append(texts, comment.Text)
+	}
+	if got := strings.Join(texts, "|"); got != "first|second, edited" || page.IsNextPageAvailable {
+		t.Errorf("FetchComments() = %s, next page %v", got, page.IsNextPageAvailable)
+	}
+	if _, err := repo.FetchComment(ctx, "doc", "c3", nil); !hasErrorStatus(err, http.StatusNotFound) {
+		t.Errorf("FetchComment() of a deleted comment error = %v, want not found", err)
+	}
+}
+
+func TestRepository_FetchCommentThread(t *testing.T) {
+	t.Parallel()
+	srv := &commentsTestServer{t: t}
+	repo := newTestRepository(srv.respond)
+	ctx := context.Background()
+
+	// c1 <- c2, c3, c4, c5 (paged) ; c3 <- c6 <- c7
+	steps := [][2]string{{"doc", "root"}, {"c1", "r1"}, {"c1", "r2"}, {"c1", "r3"}, {"c1", "r4"}, {"c3", "r2.1"}, {"c6", "r2.1.1"}}
+	for _, step := range steps {
+		if _, err := repo.CreateComment(ctx, step[0], step[1], nil); err != nil {
+			t.Fatalf("CreateComment() error = %v", err)
+		}
+	}
+
+	thread, err := repo.FetchCommentThread(ctx, "doc", "c1")
+	if err != nil {
+		t.Fatalf("FetchCommentThread() error = %v", err)
+	}
+	var format func(thread *CommentThread) string
+	format = func(thread *CommentThread) string {
+		replies := []string{}
+		for _, reply := range thread.Replies {
+			replies = append(replies, format(reply))
+		}
+		if len(replies) == 0 {
+			return thread.Text
+		}
+		return thread.Text + "(" + strings.Join(replies, ",") + ")"
+	}
+	if got := format(thread); got != "root(r1,r2(r2.1(r2.1.1)),r3,r4)" {
+		t.Errorf("FetchCommentThread() = %s", got)
+	}
+}
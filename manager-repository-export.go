@@ -0,0 +1,359 @@
+package nuxeo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+/////////////////////
+//// TREE EXPORT ////
+/////////////////////
+
+// ExportSidecarName is the name of the sidecar file describing a folderish document inside its exported directory.
+// The sidecar of any other document is named after its first exported blob (or its title) with this name as suffix.
+const ExportSidecarName = ".nuxeo.json"
+
+// ExportTreeOptions configures a tree export.
+type ExportTreeOptions struct {
+	// XPaths lists the blob properties to export; defaults to "file:content" and "files:files"
+	XPaths []string
+	// Incremental skips documents whose sidecar records the same "dc:modified" date as the document, keeping their files
+	Incremental bool
+}
+
+// ExportSidecar is the content of the sidecar file written for each exported document.
+type ExportSidecar struct {
+	DocumentId   string              `json:"uid"`
+	DocumentPath string              `json:"path"`
+	Type         string              `json:"type"`
+	Name         string              `json:"name"`
+	Title        string              `json:"title"`
+	State        string              `json:"state"`
+	Modified     *ISO8601Time        `json:"modified,omitempty"`
+	Facets       []string            `json:"facets"`
+	Properties   map[string]Field    `json:"properties"`
+	ACLs         []ACL               `json:"acls"`
+	Blobs        []ExportSidecarBlob `json:"blobs"`
+}
+
+// ExportSidecarBlob maps a blob property of an exported document to its file.
+type ExportSidecarBlob struct {
+	XPath           string `json:"xpath"`
+	File            string `json:"file"`
+	MimeType        string `json:"mimeType"`
+	DigestAlgorithm string `json:"digestAlgorithm"`
+	Digest          string `json:"digest"`
+}
+
+// ExportReport lists the documents written or skipped by a tree export.
+type ExportReport struct {
+	Exported []ExportReportEntry
+	Skipped  []ExportReportEntry
+}
+
+// ExportReportEntry describes a single exported document.
+type ExportReportEntry struct {
+	DocumentId   string
+	DocumentPath string
+	// LocalPath is the slash separated path of the directory, first blob file or sidecar relative to the export directory
+	LocalPath string
+}
+
+// ExportTree writes the document tree rooted at rootRef (a repository path or a document ID) into the local directory.
+//
+// Folderish documents are written as directories named after their title, other documents as their blob files, and
+// the properties, facets and ACLs of each document into a sidecar (see ExportSidecarName). Files and directories get
+// the "dc:modified" date of their document as modification time. Blobs are streamed to disk one at a time.
+func (r *repository) ExportTree(ctx context.Context, rootRef string, localDir string, options *ExportTreeOptions) (*ExportReport, error) {
+	if options == nil {
+		options = &ExportTreeOptions{}
+	}
+	xpaths := []string{DocumentPropertyFileContent, DocumentPropertyFilesFiles}
+	if len(options.XPaths) > 0 {
+		xpaths = options.XPaths
+	}
+	if err := os.MkdirAll(localDir, 0o755); err != nil {
+		return nil, err
+	}
+	exp := &treeExport{
+		repo:    r,
+		root:    localDir,
+		xpaths:  xpaths,
+		options: options,
+		report:  &ExportReport{},
+		dirs:    map[string]string{},
+		names:   map[string]*uniqueNames{},
+	}
+
+	requestOptions := NewNuxeoRequestOptions().
+		SetRepositoryName(r.name).
+		SetSchemas([]string{"*"}).
+		SetEnricherForDocument([]string{EnricherDocumentACLs})
+	err := r.WalkTree(ctx, rootRef, func(doc *Document, depth int) error {
+		dir := localDir
+		if depth > 0 {
+			parentDir, found := exp.dirs[doc.ParentRef]
+			if !found {
+				return fmt.Errorf("no exported directory for parent %s of %s", doc.ParentRef, doc.ID)
+			}
+			dir = parentDir
+		}
+		if doc.IsFolder() {
+			return exp.exportFolder(doc, dir, depth == 0)
+		}
+		return exp.exportDocument(ctx, doc, dir)
+	}, requestOptions)
+	if err != nil {
+		r.logger.Error("Failed to export tree", slog.String("error", err.Error()), slog.String("root", rootRef))
+		return nil, err
+	}
+
+	// directory times are set last, as writing their content updates them
+	for _, folder := range exp.folders {
+		if err := os.Chtimes(folder.dir, folder.modified, folder.modified); err != nil {
+			return nil, err
+		}
+	}
+	return exp.report, nil
+}
+
+// treeExport holds the state of a running tree export.
+type treeExport struct {
+	repo    *repository
+	root    string
+	xpaths  []string
+	options *ExportTreeOptions
+	report  *ExportReport
+
+	// dirs maps the exported folderish document IDs to their directory
+	dirs map[string]string
+	// names hands out the unique entry names of each directory
+	names   map[string]*uniqueNames
+	folders []exportedFolder
+}
+
+// exportedFolder is a directory to timestamp once the export is done.
+type exportedFolder struct {
+	dir      string
+	modified time.Time
+}
+
+// exportFolder creates the directory of the folderish document and writes its sidecar. The root is exported into dir itself.
+func (exp *treeExport) exportFolder(doc *Document, dir string, isRoot bool) error {
+	if !isRoot {
+		dir = filepath.Join(dir, exp.namesOf(dir).next(documentTitle(doc)))
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	exp.dirs[doc.ID] = dir
+	exp.namesOf(dir).reserve(ExportSidecarName)
+
+	modified := documentModified(doc)
+	if !modified.IsZero() {
+		exp.folders = append(exp.folders, exportedFolder{dir: dir, modified: modified})
+	}
+	sidecarPath := filepath.Join(dir, ExportSidecarName)
+	if exp.isUpToDate(sidecarPath, doc, nil) {
+		exp.record(&exp.report.Skipped, doc, dir)
+		return nil
+	}
+	if err := writeSidecar(sidecarPath, newExportSidecar(doc, nil), modified); err != nil {
+		return err
+	}
+	exp.record(&exp.report.Exported, doc, dir)
+	return nil
+}
+
+// exportDocument writes the blobs and sidecar of the document into dir.
+func (exp *treeExport) exportDocument(ctx context.Context, doc *Document, dir string) error {
+	names := exp.namesOf(dir)
+	sidecarBlobs := []ExportSidecarBlob{}
+	for _, xpath := range exp.xpaths {
+		for _, docBlob := range documentBlobs(doc, xpath) {
+			sidecarBlobs = append(sidecarBlobs, ExportSidecarBlob{
+				XPath:           docBlob.xpath,
+				File:            names.next(docBlob.blob.Filename),
+				MimeType:        docBlob.blob.MimeType,
+				DigestAlgorithm: docBlob.blob.DigestAlgorithm,
+				Digest:          docBlob.blob.Digest,
+			})
+		}
+	}
+	var entryName string
+	if len(sidecarBlobs) > 0 {
+		entryName = sidecarBlobs[0].File
+	} else {
+		entryName = names.next(documentTitle(doc))
+	}
+	sidecarPath := filepath.Join(dir, entryName+ExportSidecarName)
+	entryPath := filepath.Join(dir, entryName)
+	if len(sidecarBlobs) == 0 {
+		entryPath = sidecarPath
+	}
+
+	if exp.isUpToDate(sidecarPath, doc, sidecarBlobs) {
+		exp.record(&exp.report.Skipped, doc, entryPath)
+		return nil
+	}
+	modified := documentModified(doc)
+	for _, sidecarBlob := range sidecarBlobs {
+		if err := exp.writeBlob(ctx, doc, sidecarBlob, filepath.Join(dir, sidecarBlob.File), modified); err != nil {
+			exp.repo.logger.Error("Failed to export blob", slog.String("error", err.Error()), slog.String("uid", doc.ID), slog.String("xpath", sidecarBlob.XPath))
+			return err
+		}
+	}
+	if err := writeSidecar(sidecarPath, newExportSidecar(doc, sidecarBlobs), modified); err != nil {
+		return err
+	}
+	exp.record(&exp.report.Exported, doc, entryPath)
+	return nil
+}
+
+// writeBlob streams the document blob into a temporary file, then moves it to filePath.
+func (exp *treeExport) writeBlob(ctx context.Context, doc *Document, sidecarBlob ExportSidecarBlob, filePath string, modified time.Time) error {
+	stream, err := exp.repo.StreamBlobById(ctx, doc.ID, sidecarBlob.XPath, nil)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".nuxeo-export-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, stream); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return err
+	}
+	if modified.IsZero() {
+		return nil
+	}
+	return os.Chtimes(filePath, modified, modified)
+}
+
+// isUpToDate returns true for incremental exports if the sidecar records the document's modification date and all blob files exist.
+func (exp *treeExport) isUpToDate(sidecarPath string, doc *Document, sidecarBlobs []ExportSidecarBlob) bool {
+	if !exp.options.Incremental {
+		return false
+	}
+	sidecar, err := ReadExportSidecar(sidecarPath)
+	if err != nil || sidecar.Modified == nil {
+		return false
+	}
+	modified := documentModified(doc)
+	if modified.IsZero() || !time.Time(*sidecar.Modified).Equal(modified.Truncate(time.Millisecond)) {
+		return false
+	}
+	for _, sidecarBlob := range sidecarBlobs {
+		if _, err := os.Stat(filepath.Join(filepath.Dir(sidecarPath), sidecarBlob.File)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// namesOf returns the unique names handed out in dir.
+func (exp *treeExport) namesOf(dir string) *uniqueNames {
+	names, found := exp.names[dir]
+	if !found {
+		names = newUniqueNames()
+		exp.names[dir] = names
+	}
+	return names
+}
+
+// record adds an entry for the document into the given report list.
+func (exp *treeExport) record(entries *[]ExportReportEntry, doc *Document, localPath string) {
+	relPath, err := filepath.Rel(exp.root, localPath)
+	if err != nil {
+		relPath = localPath
+	}
+	*entries = append(*entries, ExportReportEntry{DocumentId: doc.ID, DocumentPath: doc.Path, LocalPath: filepath.ToSlash(relPath)})
+}
+
+// ReadExportSidecar reads a sidecar file written by ExportTree.
+func ReadExportSidecar(sidecarPath string) (*ExportSidecar, error) {
+	data, err := os.ReadFile(sidecarPath)
+	if err != nil {
+		return nil, err
+	}
+	sidecar := &ExportSidecar{}
+	if err := json.Unmarshal(data, sidecar); err != nil {
+		return nil, fmt.Errorf("failed to decode sidecar %s: %w", sidecarPath, err)
+	}
+	return sidecar, nil
+}
+
+// newExportSidecar builds the sidecar of the document, reading its ACLs from the "acls" enricher.
+func newExportSidecar(doc *Document, sidecarBlobs []ExportSidecarBlob) *ExportSidecar {
+	sidecar := &ExportSidecar{
+		DocumentId:   doc.ID,
+		DocumentPath: doc.Path,
+		Type:         doc.Type,
+		Name:         doc.Name,
+		Title:        doc.Title,
+		State:        doc.State,
+		Facets:       doc.Facets,
+		Properties:   doc.Properties,
+		ACLs:         []ACL{},
+		Blobs:        sidecarBlobs,
+	}
+	if sidecarBlobs == nil {
+		sidecar.Blobs = []ExportSidecarBlob{}
+	}
+	if modified := documentModified(doc); !modified.IsZero() {
+		isoModified := ISO8601Time(modified.UTC())
+		sidecar.Modified = &isoModified
+	}
+	if field, found := doc.ContextParameter(EnricherDocumentACLs); found {
+		if err := field.ComplexList(&sidecar.ACLs); err != nil {
+			sidecar.ACLs = []ACL{}
+		}
+	}
+	return sidecar
+}
+
+// writeSidecar writes the sidecar as indented JSON with the given modification time.
+func writeSidecar(sidecarPath string, sidecar *ExportSidecar, modified time.Time) error {
+	data, err := json.MarshalIndent(sidecar, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(sidecarPath, data, 0o644); err != nil {
+		return err
+	}
+	if modified.IsZero() {
+		return nil
+	}
+	return os.Chtimes(sidecarPath, modified, modified)
+}
+
+// documentTitle returns the title of the document, falling back on its name and ID.
+func documentTitle(doc *Document) string {
+	if field, found := doc.Property(DocumentPropertyDCTitle); found {
+		if title, err := field.String(); err == nil && title != nil && *title != "" {
+			return *title
+		}
+	}
+	if doc.Title != "" {
+		return doc.Title
+	}
+	if doc.Name != "" {
+		return doc.Name
+	}
+	return doc.ID
+}
@@ -0,0 +1,75 @@
+package nuxeo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRepository_ExportTree(t *testing.T) {
+	t.Parallel()
+	localDir := t.TempDir()
+	blobRequests := &atomic.Int32{}
+	repo := newTestRepository(treeTestResponder(t, blobRequests))
+
+	report, err := repo.ExportTree(context.Background(), "/ws", localDir, nil)
+	if err != nil {
+		t.Fatalf("ExportTree() error = %v", err)
+	}
+	if len(report.Exported) != 4 || len(report.Skipped) != 0 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	wantFiles := map[string]string{
+		"report.pdf":     "content of file:content",
+		"report (1).pdf": "content of files:files/0/file",
+	}
+	modified := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	for name, want := range wantFiles {
+		filePath := filepath.Join(localDir, name)
+		content, err := os.ReadFile(filePath)
+		if err != nil || string(content) != want {
+			t.Errorf("file %s: got %q (%v), want %q", name, content, err, want)
+		}
+		if info, err := os.Stat(filePath); err != nil || !info.ModTime().Equal(modified) {
+			t.Errorf("file %s: unexpected modification time", name)
+		}
+	}
+	if info, err := os.Stat(filepath.Join(localDir, "Sub")); err != nil || !info.ModTime().Equal(modified) {
+		t.Errorf("directory Sub: unexpected modification time")
+	}
+
+	folderSidecar, err := ReadExportSidecar(filepath.Join(localDir, "Sub", ExportSidecarName))
+	if err != nil {
+		t.Fatalf("failed to read folder sidecar: %v", err)
+	}
+	if folderSidecar.DocumentId != "sub" || len(folderSidecar.ACLs) != 1 || folderSidecar.ACLs[0].ACEs[0].Username != "jdoe" {
+		t.Errorf("unexpected folder sidecar: %+v", folderSidecar)
+	}
+	if _, err := ReadExportSidecar(filepath.Join(localDir, "Sub", "My Note"+ExportSidecarName)); err != nil {
+		t.Errorf("missing sidecar of document without blobs: %v", err)
+	}
+	docSidecar, err := ReadExportSidecar(filepath.Join(localDir, "report.pdf"+ExportSidecarName))
+	if err != nil {
+		t.Fatalf("failed to read document sidecar: %v", err)
+	}
+	if len(docSidecar.Blobs) != 2 || docSidecar.Blobs[1].File != "report (1).pdf" || docSidecar.Blobs[1].XPath != "files:files/0/file" {
+		t.Errorf("unexpected document sidecar blobs: %+v", docSidecar.Blobs)
+	}
+
+	// re-export incrementally: nothing changed on the server
+	blobRequests.Store(0)
+	report, err = repo.ExportTree(context.Background(), "/ws", localDir, &ExportTreeOptions{Incremental: true})
+	if err != nil {
+		t.Fatalf("incremental ExportTree() error = %v", err)
+	}
+	if len(report.Exported) != 0 || len(report.Skipped) != 4 {
+		t.Errorf("unexpected incremental report: %+v", report)
+	}
+	if blobRequests.Load() != 0 {
+		t.Errorf("incremental export downloaded %d blobs", blobRequests.Load())
+	}
+}
@@ -0,0 +1,386 @@
+package nuxeo
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/anselm94/nuxeo-go-client/internal"
+)
+
+//////////////////////////
+//// DIRECTORY IMPORT ////
+//////////////////////////
+
+// DefaultImportConcurrency is the default number of files imported in parallel by ImportDirectory.
+const DefaultImportConcurrency = 4
+
+// ImportSkipMode selects how ImportDirectory detects files left unchanged since a previous import.
+type ImportSkipMode int
+
+const (
+	// ImportSkipNever re-imports files which already exist as documents
+	ImportSkipNever ImportSkipMode = iota
+	// ImportSkipByDigest skips files whose content digest matches the existing document's "file:content" digest
+	ImportSkipByDigest
+	// ImportSkipByModified skips files not modified since the existing document's "dc:modified" date
+	ImportSkipByModified
+)
+
+// ImportDirectoryOptions configures a directory import.
+type ImportDirectoryOptions struct {
+	// Include lists glob patterns (path.Match syntax) of the files to import; defaults to all files.
+	// Patterns containing "/" are matched against the slash separated path relative to the imported directory, others against the base name.
+	Include []string
+	// Exclude lists glob patterns of the files and directories to leave out, matched like Include
+	Exclude []string
+	// FolderType is the document type created for directories; defaults to "Workspace" under a workspace root and "Folder" elsewhere
+	FolderType string
+	// Concurrency is the maximum number of files imported in parallel; defaults to DefaultImportConcurrency
+	Concurrency int
+	// SkipUnchanged selects how files which already exist as documents are detected as unchanged
+	SkipUnchanged ImportSkipMode
+	// UploadOptions configures the blob uploads
+	UploadOptions *BlobUploadOptions
+}
+
+// ImportReport lists the outcome of each file and directory of a directory import.
+type ImportReport struct {
+	Created []ImportReportEntry
+	Updated []ImportReportEntry
+	Skipped []ImportReportEntry
+	Failed  []ImportReportEntry
+}
+
+// ImportReportEntry describes the outcome of importing a single file or directory.
+type ImportReportEntry struct {
+	// LocalPath is the slash separated path relative to the imported directory
+	LocalPath    string
+	DocumentId   string
+	DocumentPath string
+	// Err is the reason of the failure of failed entries
+	Err error
+}
+
+// Err returns the failures of the import joined into a single error, or nil if all entries were imported.
+func (r *ImportReport) Err() error {
+	errs := make([]error, 0, len(r.Failed))
+	for _, entry := range r.Failed {
+		errs = append(errs, fmt.Errorf("%s: %w", entry.LocalPath, entry.Err))
+	}
+	return errors.Join(errs...)
+}
+
+// ImportDirectory mirrors the content of the local directory under the parent document (a repository path or a document ID).
+//
+// Directories are created as folderish documents, or reused when a child with the same title already exists. Files are
+// uploaded through a batch and imported with the FileManager.Import operation, so the server picks the document type,
+// and existing documents with the same title are overwritten unless detected as unchanged by options.SkipUnchanged.
+//
+// Failures of single entries do not stop the import: they are listed in the report, see ImportReport.Err. An error is
+// returned only when the import cannot run at all, or when the context is cancelled.
+func (r *repository) ImportDirectory(ctx context.Context, localPath string, parentRef string, options *ImportDirectoryOptions) (*ImportReport, error) {
+	if options == nil {
+		options = &ImportDirectoryOptions{}
+	}
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", localPath)
+	}
+
+	parent, err := r.fetchDocument(ctx, parentRef, nil)
+	if err != nil {
+		return nil, err
+	}
+	batchUploadManager := r.client.BatchUploadManager()
+	batch, err := batchUploadManager.CreateBatchWithHandler(ctx, options.UploadOptions.batchHandler(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := batchUploadManager.CancelBatch(context.WithoutCancel(ctx), batch.BatchId, nil); err != nil {
+			r.logger.Warn("Failed to clean up import batch", slog.String("error", err.Error()), slog.String("batchId", batch.BatchId))
+		}
+	}()
+
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultImportConcurrency
+	}
+	imp := &directoryImport{
+		repo:    r,
+		root:    localPath,
+		options: options,
+		batch:   batch,
+		report:  &ImportReport{},
+		slots:   make(chan struct{}, concurrency),
+	}
+	imp.importDirectory(ctx, parent, ".")
+	imp.wg.Wait()
+
+	for _, entries := range [][]ImportReportEntry{imp.report.Created, imp.report.Updated, imp.report.Skipped, imp.report.Failed} {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].LocalPath < entries[j].LocalPath })
+	}
+	return imp.report, ctx.Err()
+}
+
+// directoryImport holds the state of a running directory import.
+type directoryImport struct {
+	repo    *repository
+	root    string
+	options *ImportDirectoryOptions
+	batch   *batchUpload
+	report  *ImportReport
+
+	mu          sync.Mutex
+	wg          sync.WaitGroup
+	slots       chan struct{}
+	nextFileIdx atomic.Int64
+}
+
+// importDirectory imports the entries of the local directory at relDir into the remote folderish document.
+func (imp *directoryImport) importDirectory(ctx context.Context, remote *Document, relDir string) {
+	existing, err := imp.existingChildren(ctx, remote)
+	if err != nil {
+		imp.fail(relDir, err)
+		return
+	}
+	entries, err := os.ReadDir(filepath.Join(imp.root, filepath.FromSlash(relDir)))
+	if err != nil {
+		imp.fail(relDir, err)
+		return
+	}
+
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return
+		}
+		relPath := path.Join(relDir, entry.Name())
+		if matchesAny(imp.options.Exclude, relPath) {
+			continue
+		}
+
+		switch {
+		case entry.IsDir():
+			folder, err := imp.ensureFolder(ctx, remote, existing[entry.Name()], entry.Name(), relPath)
+			if err != nil {
+				imp.fail(relPath, err)
+				continue
+			}
+			imp.importDirectory(ctx, folder, relPath)
+		case entry.Type().IsRegular():
+			if len(imp.options.Include) > 0 && !matchesAny(imp.options.Include, relPath) {
+				continue
+			}
+			imp.slots <- struct{}{}
+			imp.wg.Add(1)
+			go func(current *Document) {
+				defer func() {
+					<-imp.slots
+					imp.wg.Done()
+				}()
+				imp.importFile(ctx, remote, current, relPath)
+			}(existing[entry.Name()])
+		}
+	}
+}
+
+// ensureFolder returns the existing folderish document for the directory, creating it if missing.
+func (imp *directoryImport) ensureFolder(ctx context.Context, parent *Document, current *Document, name string, relPath string) (*Document, error) {
+	if current != nil && current.IsFolder() {
+		imp.record(&imp.report.Skipped, relPath, current)
+		return current, nil
+	}
+	folderType := imp.options.FolderType
+	if folderType == "" {
+		folderType = DocumentTypeFolder
+		if parent.Type == DocumentTypeWorkspaceRoot {
+			folderType = DocumentTypeWorkspace
+		}
+	}
+	folder, err := imp.repo.CreateDocumentById(ctx, parent.ID, *NewDocument(folderType, name), nil)
+	if err != nil {
+		return nil, err
+	}
+	imp.record(&imp.report.Created, relPath, folder)
+	return folder, nil
+}
+
+// importFile uploads the local file and imports it into the remote folderish document, overwriting the current document if any.
+func (imp *directoryImport) importFile(ctx context.Context, parent *Document, current *Document, relPath string) {
+	localPath := filepath.Join(imp.root, filepath.FromSlash(relPath))
+	if current != nil {
+		unchanged, err := isUnchanged(localPath, current, imp.options.SkipUnchanged)
+		if err != nil {
+			imp.fail(relPath, err)
+			return
+		}
+		if unchanged {
+			imp.record(&imp.report.Skipped, relPath, current)
+			return
+		}
+	}
+
+	blob, err := NewBlobFromFile(localPath)
+	if err != nil {
+		imp.fail(relPath, err)
+		return
+	}
+	defer blob.Close()
+
+	batchUploadManager := imp.repo.client.BatchUploadManager()
+	fileIdx := int(imp.nextFileIdx.Add(1) - 1)
+	upload, err := batchUploadManager.UploadBlob(ctx, imp.batch, fileIdx, blob, imp.options.UploadOptions, nil)
+	if err != nil {
+		imp.fail(relPath, err)
+		return
+	}
+
+	operation := NewOperation(OperationFileManagerImport).
+		SetContext("currentDocument", parent.Path).
+		SetParam("overwrite", current != nil)
+	// keep the batch for the other files, it is dropped once the import is done
+	noDropOptions := NewNuxeoRequestOptions().
+		SetRepositoryName(imp.repo.name).
+		SetHeader(internal.HeaderXBatchNoDrop, "true")
+	doc := &Document{}
+	if _, err := batchUploadManager.ExecuteBatchUpload(ctx, imp.batch.BatchId, uploadedFileIdx(upload, fileIdx), *operation, doc, noDropOptions); err != nil {
+		imp.fail(relPath, err)
+		return
+	}
+	if current != nil {
+		imp.record(&imp.report.Updated, relPath, doc)
+	} else {
+		imp.record(&imp.report.Created, relPath, doc)
+	}
+}
+
+// existingChildren returns the live children of the remote document keyed by title.
+func (imp *directoryImport) existingChildren(ctx context.Context, remote *Document) (map[string]*Document, error) {
+	options := NewNuxeoRequestOptions().
+		SetRepositoryName(imp.repo.name).
+		SetSchemas([]string{"dublincore", "file"})
+	children := map[string]*Document{}
+	for child, err := range imp.repo.allChildren(ctx, remote.ID, options) {
+		if err != nil {
+			return nil, err
+		}
+		if title := documentTitle(child); children[title] == nil {
+			children[title] = child
+		}
+	}
+	return children, nil
+}
+
+// record adds an entry for the document into the given report list.
+func (imp *directoryImport) record(entries *[]ImportReportEntry, relPath string, doc *Document) {
+	imp.mu.Lock()
+	defer imp.mu.Unlock()
+	*entries = append(*entries, ImportReportEntry{LocalPath: relPath, DocumentId: doc.ID, DocumentPath: doc.Path})
+}
+
+// fail adds a failed entry into the report.
+func (imp *directoryImport) fail(relPath string, err error) {
+	imp.repo.logger.Error("Failed to import local file", slog.String("error", err.Error()), slog.String("path", relPath))
+	imp.mu.Lock()
+	defer imp.mu.Unlock()
+	imp.report.Failed = append(imp.report.Failed, ImportReportEntry{LocalPath: relPath, Err: err})
+}
+
+// matchesAny returns true if the slash separated relative path matches one of the glob patterns.
+// Patterns without "/" are matched against the base name.
+func matchesAny(patterns []string, relPath string) bool {
+	for _, pattern := range patterns {
+		name := relPath
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(relPath)
+		}
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// isUnchanged returns true if the local file is detected as unchanged compared to the document, according to the skip mode.
+func isUnchanged(localPath string, doc *Document, mode ImportSkipMode) (bool, error) {
+	switch mode {
+	case ImportSkipByDigest:
+		content := doc.FileContent()
+		if content == nil || content.Digest == "" {
+			return false, nil
+		}
+		digest, err := fileDigest(localPath, content.DigestAlgorithm)
+		if err != nil || digest == "" {
+			return false, err
+		}
+		return strings.EqualFold(digest, content.Digest), nil
+	case ImportSkipByModified:
+		modified := documentModified(doc)
+		if modified.IsZero() {
+			return false, nil
+		}
+		info, err := os.Stat(localPath)
+		if err != nil {
+			return false, err
+		}
+		return !info.ModTime().After(modified), nil
+	}
+	return false, nil
+}
+
+// documentModified returns the "dc:modified" date of the document, falling back on its last modification date.
+func documentModified(doc *Document) time.Time {
+	if field, found := doc.Property(DocumentPropertyDCModified); found {
+		if modified, err := field.Time(); err == nil && modified != nil {
+			return time.Time(*modified)
+		}
+	}
+	if doc.LastModified != nil {
+		return time.Time(*doc.LastModified)
+	}
+	return time.Time{}
+}
+
+// fileDigest returns the hex digest of the local file with the Nuxeo digest algorithm (MD5 by default).
+// An empty digest is returned for unsupported algorithms.
+func fileDigest(localPath string, algorithm string) (string, error) {
+	var h hash.Hash
+	switch strings.ToUpper(strings.ReplaceAll(algorithm, "-", "")) {
+	case "", "MD5":
+		h = md5.New()
+	case "SHA1":
+		h = sha1.New()
+	case "SHA256":
+		h = sha256.New()
+	default:
+		return "", nil
+	}
+	file, err := os.Open(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,204 @@
+package nuxeo
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// importTestResponder serves a workspace holding "same.txt" and "changed.txt" documents, and records imported files.
+func importTestResponder(t *testing.T, modified time.Time, imported *sync.Map) func(req *http.Request) (*http.Response, error) {
+	sameDigest := md5.Sum([]byte("same"))
+	existing := func(uid string, title string, digest string) map[string]any {
+		return map[string]any{
+			"entity-type": "document",
+			"uid":         uid,
+			"path":        "/ws/" + title,
+			"title":       title,
+			"properties": map[string]any{
+				"dc:title":     title,
+				"dc:modified":  modified.Format(time.RFC3339),
+				"file:content": map[string]any{"name": title, "digestAlgorithm": "MD5", "digest": digest},
+			},
+		}
+	}
+
+	return func(req *http.Request) (*http.Response, error) {
+		call := req.Method + " " + req.URL.EscapedPath()
+		switch {
+		case call == "GET /api/v1/repo/default/path/ws":
+			return testJsonResponse(t, 200, map[string]any{"entity-type": "document", "uid": "ws", "path": "/ws", "type": "Workspace"})
+		case call == "POST /api/v1/upload/new/default":
+			return testJsonResponse(t, 200, map[string]any{"batchId": "batch1"})
+		case call == "DELETE /api/v1/upload/batch1":
+			return testJsonResponse(t, 200, map[string]any{})
+		case call == "GET /api/v1/query":
+			entries := []any{}
+			if req.URL.Query().Get("queryParams") == "ws" {
+				entries = append(entries, existing("same", "same.txt", hex.EncodeToString(sameDigest[:])), existing("changed", "changed.txt", "0000"))
+			}
+			return testJsonResponse(t, 200, map[string]any{"entity-type": "documents", "entries": entries})
+		case call == "POST /api/v1/repo/default/id/ws":
+			var doc Document
+			json.NewDecoder(req.Body).Decode(&doc)
+			if doc.Type != DocumentTypeFolder {
+				return nil, errors.New("unexpected folder type " + doc.Type)
+			}
+			return testJsonResponse(t, 200, map[string]any{"entity-type": "document", "uid": "sub", "path": "/ws/" + doc.Name, "type": doc.Type, "facets": []string{"Folderish"}})
+		case strings.HasSuffix(req.URL.EscapedPath(), "/execute/FileManager.Import"):
+			if req.Header.Get("X-Batch-No-Drop") != "true" {
+				return nil, errors.New("expected batch to be kept")
+			}
+			var payload struct {
+				Params  map[string]any    `json:"params"`
+				Context map[string]string `json:"context"`
+			}
+			json.NewDecoder(req.Body).Decode(&payload)
+			fileIdx := strings.Split(req.URL.EscapedPath(), "/")[5]
+			name, _ := imported.Load(fileIdx)
+			return testJsonResponse(t, 200, map[string]any{"entity-type": "document", "uid": "doc-" + fileIdx, "path": payload.Context["currentDocument"] + "/" + name.(string)})
+		case req.Method == http.MethodPost && strings.HasPrefix(req.URL.EscapedPath(), "/api/v1/upload/batch1/"):
+			io.Copy(io.Discard, req.Body)
+			fileIdx := strings.TrimPrefix(req.URL.EscapedPath(), "/api/v1/upload/batch1/")
+			imported.Store(fileIdx, req.Header.Get("X-File-Name"))
+			return testJsonResponse(t, 200, map[string]any{"batchId": "batch1", "fileIdx": fileIdx})
+		}
+		return nil, errors.New("unexpected request " + call)
+	}
+}
+
+func TestRepository_ImportDirectory(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	files := map[string]string{
+		"same.txt":      "same",
+		"changed.txt":   "changed",
+		"new.txt":       "new",
+		"debug.log":     "excluded",
+		"sub/inner.txt": "inner",
+		"sub/skip.tmp":  "not included",
+	}
+	for name, content := range files {
+		localPath := filepath.Join(root, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(localPath), 0o755)
+		if err := os.WriteFile(localPath, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		modified    time.Time
+		skip        ImportSkipMode
+		wantCreated []string
+		wantUpdated []string
+		wantSkipped []string
+	}{
+		{
+			name:        "skip by digest",
+			skip:        ImportSkipByDigest,
+			wantCreated: []string{"new.txt", "sub", "sub/inner.txt"},
+			wantUpdated: []string{"changed.txt"},
+			wantSkipped: []string{"same.txt"},
+		},
+		{
+			name:        "skip by modification date",
+			modified:    time.Now().Add(time.Hour),
+			skip:        ImportSkipByModified,
+			wantCreated: []string{"new.txt", "sub", "sub/inner.txt"},
+			wantSkipped: []string{"changed.txt", "same.txt"},
+		},
+		{
+			name:        "never skip",
+			wantCreated: []string{"new.txt", "sub", "sub/inner.txt"},
+			wantUpdated: []string{"changed.txt", "same.txt"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			imported := &sync.Map{}
+			repo := newTestRepository(importTestResponder(t, tc.modified, imported))
+			report, err := repo.ImportDirectory(context.Background(), root, "/ws", &ImportDirectoryOptions{
+				Include:       []string{"*.txt"},
+				Exclude:       []string{"*.log"},
+				Concurrency:   2,
+				SkipUnchanged: tc.skip,
+			})
+			if err != nil {
+				t.Fatalf("ImportDirectory() error = %v", err)
+			}
+			if err := report.Err(); err != nil {
+				t.Fatalf("unexpected failures: %v", err)
+			}
+			localPaths := func(entries []ImportReportEntry) []string {
+				paths := []string{}
+				for _, entry := range entries {
+					paths = append(paths, entry.LocalPath)
+				}
+				return paths
+			}
+			for _, check := range []struct {
+				kind string
+				got  []ImportReportEntry
+				want []string
+			}{
+				{"created", report.Created, tc.wantCreated},
+				{"updated", report.Updated, tc.wantUpdated},
+				{"skipped", report.Skipped, tc.wantSkipped},
+			} {
+				if got := strings.Join(localPaths(check.got), ","); got != strings.Join(check.want, ",") {
+					t.Errorf("%s: got %q, want %q", check.kind, got, strings.Join(check.want, ","))
+				}
+			}
+			for _, entry := range report.Created {
+				if entry.LocalPath == "sub/inner.txt" && entry.DocumentPath != "/ws/sub/inner.txt" {
+					t.Errorf("unexpected document path for %s: %s", entry.LocalPath, entry.DocumentPath)
+				}
+			}
+		})
+	}
+}
+
+func TestRepository_ImportDirectoryNotADirectory(t *testing.T) {
+	t.Parallel()
+	localPath := filepath.Join(t.TempDir(), "file.txt")
+	os.WriteFile(localPath, []byte("data"), 0o644)
+	repo := newTestRepository(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("unexpected request")
+	})
+	if _, err := repo.ImportDirectory(context.Background(), localPath, "/ws", nil); err == nil {
+		t.Error("expected error for a file path")
+	}
+}
+
+func TestMatchesAny(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		patterns []string
+		relPath  string
+		want     bool
+	}{
+		{[]string{"*.txt"}, "a/b/c.txt", true},
+		{[]string{"a/*.txt"}, "a/c.txt", true},
+		{[]string{"a/*.txt"}, "a/b/c.txt", false},
+		{[]string{".git", "*.tmp"}, "x/.git", true},
+		{nil, "c.txt", false},
+	}
+	for _, tc := range tests {
+		if got := matchesAny(tc.patterns, tc.relPath); got != tc.want {
+			t.Errorf("matchesAny(%v, %q) = %v, want %v", tc.patterns, tc.relPath, got, tc.want)
+		}
+	}
+}
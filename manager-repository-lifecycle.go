@@ -0,0 +1,192 @@
+package nuxeo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+///////////////////
+//// LIFECYCLE ////
+///////////////////
+
+// ErrUnknownLifecyclePolicy is returned when a document follows a lifecycle policy other than the one its transitions are
+// looked up in.
+var ErrUnknownLifecyclePolicy = errors.New("unknown lifecycle policy")
+
+// LifecycleTransition is a transition of a lifecycle policy, leading to its destination state.
+type LifecycleTransition struct {
+	Name        string
+	Destination string
+}
+
+// LifecyclePolicy describes the states of a lifecycle and the transitions allowed from each of them.
+type LifecyclePolicy struct {
+	Name         string
+	InitialState string
+	// Transitions maps the states to the transitions allowed from them
+	Transitions map[string][]LifecycleTransition
+}
+
+// TransitionsFrom returns the transitions allowed from the state, or nil if the state is unknown to the policy.
+func (p *LifecyclePolicy) TransitionsFrom(state string) []LifecycleTransition {
+	return p.Transitions[state]
+}
+
+// defaultLifecyclePolicy is the "default" lifecycle policy of the server, followed by most document types.
+var defaultLifecyclePolicy = LifecyclePolicy{
+	Name:         "default",
+	InitialState: "project",
+	Transitions: map[string][]LifecycleTransition{
+		"project": {
+			{Name: "approve", Destination: "approved"},
+			{Name: "obsolete", Destination: "obsolete"},
+		},
+		"approved": {
+			{Name: "backToProject", Destination: "project"},
+			{Name: "obsolete", Destination: "obsolete"},
+		},
+		"obsolete": {
+			{Name: "backToProject", Destination: "project"},
+		},
+	},
+}
+
+// DefaultLifecyclePolicy returns a copy of the "default" lifecycle policy of the server, followed by most document types.
+func DefaultLifecyclePolicy() *LifecyclePolicy {
+	policy := defaultLifecyclePolicy
+	policy.Transitions = make(map[string][]LifecycleTransition, len(defaultLifecyclePolicy.Transitions))
+	for state, transitions := range defaultLifecyclePolicy.Transitions {
+		policy.Transitions[state] = slices.Clone(transitions)
+	}
+	return &policy
+}
+
+// lifecyclePolicyQuery selects the lifecycle policy of a document, which its JSON representation does not carry.
+const lifecyclePolicyQuery = "SELECT ecm:lifeCyclePolicy FROM Document WHERE ecm:uuid = ?"
+
+// TransitionReport lists the outcome of a bulk transition.
+type TransitionReport struct {
+	Transitioned []TransitionReportEntry
+	Failed       []TransitionReportEntry
+}
+
+// TransitionReportEntry describes the outcome of the transition of a single document.
+type TransitionReportEntry struct {
+	DocumentId string
+	Path       string
+	// State is the lifecycle state of the document after the transition, or before it for failed entries
+	State string
+	// Err is the reason of the failure of failed entries
+	Err error
+}
+
+// Err returns the failures of the bulk transition joined into a single error, or nil if all documents were transitioned.
+func (r *TransitionReport) Err() error {
+	errs := make([]error, 0, len(r.Failed))
+	for _, entry := range r.Failed {
+		errs = append(errs, fmt.Errorf("%s: %w", entry.Path, entry.Err))
+	}
+	return errors.Join(errs...)
+}
+
+// FetchAllowedTransitions retrieves the lifecycle state and policy of a document (a repository path or a document ID),
+// and returns the transitions the policy allows from the state. The policy must be the one the document follows; a nil
+// policy defaults to DefaultLifecyclePolicy.
+//
+// The REST API and Automation expose the state and the name of the policy of a document, but not the transitions of the
+// policy: those are looked up in the given policy, which must match its definition on the server.
+// The server may still refuse a transition, e.g. if the current user lacks the WriteLifeCycle permission.
+// Returns the allowed LifecycleTransitions, empty if the state is unknown to the policy, ErrUnknownLifecyclePolicy if
+// the document follows another policy, or error.
+func (r *repository) FetchAllowedTransitions(ctx context.Context, documentRef string, policy *LifecyclePolicy) ([]LifecycleTransition, error) {
+	if policy == nil {
+		policy = DefaultLifecyclePolicy()
+	}
+	doc, err := r.fetchDocument(ctx, documentRef, nil)
+	if err != nil {
+		return nil, err
+	}
+	documentPolicy, err := r.fetchLifecyclePolicy(ctx, doc.ID)
+	if err != nil {
+		return nil, err
+	}
+	if documentPolicy != policy.Name {
+		return nil, fmt.Errorf("%w: %s follows %q, not %q", ErrUnknownLifecyclePolicy, documentRef, documentPolicy, policy.Name)
+	}
+	return policy.TransitionsFrom(doc.State), nil
+}
+
+// FollowTransition follows a lifecycle transition (e.g. "approve") on a document (a repository path or a document ID).
+// Uses the Document.FollowLifecycleTransition operation.
+// Returns the Document in its new lifecycle state, or error.
+func (r *repository) FollowTransition(ctx context.Context, documentRef string, transition string, options *nuxeoRequestOptions) (*Document, error) {
+	operation := NewOperation(OperationDocumentFollowTransition).SetParam("value", transition)
+	return r.executeForDocument(ctx, documentRef, operation, options)
+}
+
+// BulkFollowTransition follows a lifecycle transition on every document matching the NXQL query.
+//
+// The matching documents are all listed before following the transition, so that queries filtering on the lifecycle
+// state are not paged over moving results. Failures of single documents do not stop the bulk transition: they are
+// logged and listed in the report. Returns the TransitionReport, or error if the query fails.
+func (r *repository) BulkFollowTransition(ctx context.Context, query string, queryParams []string, transition string) (*TransitionReport, error) {
+	docs := []*Document{}
+	for doc, err := range r.QueryAll(ctx, query, queryParams, 0, NewNuxeoRequestOptions().SetRepositoryName(r.name)) {
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+
+	report := &TransitionReport{}
+	for _, doc := range docs {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		entry := TransitionReportEntry{DocumentId: doc.ID, Path: doc.Path, State: doc.State}
+		transitioned, err := r.FollowTransition(ctx, doc.ID, transition, nil)
+		if err != nil {
+			r.logger.Warn("Failed to follow lifecycle transition", slog.String("error", err.Error()), slog.String("uid", doc.ID), slog.String("transition", transition))
+			entry.Err = err
+			report.Failed = append(report.Failed, entry)
+			continue
+		}
+		entry.State = transitioned.State
+		report.Transitioned = append(report.Transitioned, entry)
+	}
+	return report, nil
+}
+
+// fetchLifecyclePolicy retrieves the name of the lifecycle policy a document (a document ID) follows.
+// Uses the Repository.ResultSetQuery operation.
+func (r *repository) fetchLifecyclePolicy(ctx context.Context, documentId string) (string, error) {
+	operation := NewOperation(OperationRepositoryResultSetQuery).
+		SetParam("query", lifecyclePolicyQuery).
+		SetParam("queryParams", documentId)
+	options := NewNuxeoRequestOptions().SetRepositoryName(r.name)
+	res, err := r.client.OperationManager().Execute(ctx, *operation, options)
+	if err != nil {
+		r.logger.Error("Failed to fetch lifecycle policy", slog.String("error", err.Error()), slog.String("uid", documentId))
+		return "", err
+	}
+	if res == nil {
+		return "", fmt.Errorf("no lifecycle policy found for document %s", documentId)
+	}
+	defer res.res.Body.Close()
+	var recordSet struct {
+		Entries []map[string]any `json:"entries"`
+	}
+	if err := json.NewDecoder(res.res.Body).Decode(&recordSet); err != nil {
+		r.logger.Error("Failed to decode lifecycle policy", slog.String("error", err.Error()), slog.String("uid", documentId))
+		return "", err
+	}
+	if len(recordSet.Entries) == 0 {
+		return "", fmt.Errorf("no lifecycle policy found for document %s", documentId)
+	}
+	policy, _ := recordSet.Entries[0]["ecm:lifeCyclePolicy"].(string)
+	return policy, nil
+}
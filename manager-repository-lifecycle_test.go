@@ -0,0 +1,189 @@
+package nuxeo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// lifecycleTestServer serves the documents of states at /ws/{id}, following the transitions of DefaultLifecyclePolicy.
+// Documents follow the "default" policy unless listed in policies.
+type lifecycleTestServer struct {
+	t        *testing.T
+	mu       sync.Mutex
+	states   map[string]string
+	policies map[string]string
+}
+
+func (srv *lifecycleTestServer) respond(req *http.Request) (*http.Response, error) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	doc := func(id string) map[string]any {
+		return map[string]any{"entity-type": "document", "uid": id, "path": "/ws/" + id, "state": srv.states[id]}
+	}
+
+	switch {
+	case strings.HasPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/path/ws/"):
+		id := strings.TrimPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/path/ws/")
+		if _, found := srv.states[id]; !found {
+			return testNotFoundResponse(srv.t)
+		}
+		return testJsonResponse(srv.t, 200, doc(id))
+	case req.URL.EscapedPath() == "/api/v1/query":
+		if req.Header.Get("X-NXRepository") != "default" {
+			srv.t.Errorf("query sent to repository %q", req.Header.Get("X-NXRepository"))
+		}
+		// documents in the state given as query parameter
+		entries := []any{}
+		for _, id := range slices.Sorted(maps.Keys(srv.states)) {
+			if srv.states[id] == req.URL.Query().Get("queryParams") {
+				entries = append(entries, doc(id))
+			}
+		}
+		return testJsonResponse(srv.t, 200, map[string]any{"entity-type": "documents", "entries": entries})
+	case req.URL.EscapedPath() == "/site/automation/"+OperationRepositoryResultSetQuery:
+		var payload operationPayload
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			return nil, err
+		}
+		policy := "default"
+		if custom, found := srv.policies[payload.Params["queryParams"]]; found {
+			policy = custom
+		}
+		return testJsonResponse(srv.t, 200, map[string]any{"entity-type": "recordSet", "entries": []any{map[string]any{"ecm:lifeCyclePolicy": policy}}})
+	case strings.HasSuffix(req.URL.EscapedPath(), "/"+OperationDocumentFollowTransition):
+		var payload operationPayload
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			return nil, err
+		}
+		id := strings.TrimPrefix(strings.TrimPrefix(payload.Input, "doc:"), "/ws/")
+		for _, transition := range defaultLifecyclePolicy.TransitionsFrom(srv.states[id]) {
+			if transition.Name == payload.Params["value"] {
+				srv.states[id] = transition.Destination
+				return testJsonResponse(srv.t, 200, doc(id))
+			}
+		}
+		return testJsonResponse(srv.t, 500, map[string]any{"entity-type": "exception", "status": 500, "message": "Unable to follow transition"})
+	}
+	return nil, errors.New("unexpected request " + req.URL.String())
+}
+
+func TestRepository_FollowTransition(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name            string
+		state           string
+		transition      string
+		wantState       string
+		wantErr         bool
+		wantTransitions string
+	}{
+		{name: "approve", state: "project", transition: "approve", wantState: "approved", wantTransitions: "approve,obsolete"},
+		{name: "obsolete", state: "approved", transition: "obsolete", wantState: "obsolete", wantTransitions: "backToProject,obsolete"},
+		{name: "not allowed", state: "obsolete", transition: "approve", wantErr: true, wantTransitions: "backToProject"},
+		{name: "unknown state", state: "custom", transition: "approve", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			srv := &lifecycleTestServer{t: t, states: map[string]string{"doc": tt.state}}
+			repo := newTestRepository(srv.respond)
+
+			transitions, err := repo.FetchAllowedTransitions(context.Background(), "/ws/doc", nil)
+			if err != nil {
+				t.Fatalf("FetchAllowedTransitions() error = %v", err)
+			}
+			names := []string{}
+			for _, transition := range transitions {
+				names = append(names, transition.Name)
+			}
+			if got := strings.Join(names, ","); got != tt.wantTransitions {
+				t.Errorf("FetchAllowedTransitions() = %s, want %s", got, tt.wantTransitions)
+			}
+
+			doc, err := repo.FollowTransition(context.Background(), "/ws/doc", tt.transition, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FollowTransition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && doc.State != tt.wantState {
+				t.Errorf("FollowTransition() state = %s, want %s", doc.State, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestRepository_FetchAllowedTransitions_Policy(t *testing.T) {
+	t.Parallel()
+	srv := &lifecycleTestServer{t: t, states: map[string]string{"doc": "draft"}, policies: map[string]string{"doc": "review"}}
+	repo := newTestRepository(srv.respond)
+
+	if _, err := repo.FetchAllowedTransitions(context.Background(), "/ws/missing", nil); !hasErrorStatus(err, http.StatusNotFound) {
+		t.Errorf("FetchAllowedTransitions() of a missing document error = %v, want not found", err)
+	}
+	if _, err := repo.FetchAllowedTransitions(context.Background(), "/ws/doc", nil); !errors.Is(err, ErrUnknownLifecyclePolicy) {
+		t.Errorf("FetchAllowedTransitions() with the default policy error = %v, want ErrUnknownLifecyclePolicy", err)
+	}
+	review := &LifecyclePolicy{
+		Name:         "review",
+		InitialState: "draft",
+		Transitions:  map[string][]LifecycleTransition{"draft": {{Name: "submit", Destination: "submitted"}}},
+	}
+	transitions, err := repo.FetchAllowedTransitions(context.Background(), "/ws/doc", review)
+	if err != nil || len(transitions) != 1 || transitions[0].Name != "submit" {
+		t.Errorf("FetchAllowedTransitions() = %+v, %v, want submit", transitions, err)
+	}
+}
+
+func TestDefaultLifecyclePolicy_Copy(t *testing.T) {
+	t.Parallel()
+	policy := DefaultLifecyclePolicy()
+	policy.Transitions["project"][0].Name = "changed"
+	delete(policy.Transitions, "approved")
+
+	if got := DefaultLifecyclePolicy(); got.TransitionsFrom("project")[0].Name != "approve" || got.TransitionsFrom("approved") == nil {
+		t.Errorf("DefaultLifecyclePolicy() = %+v, changed by a previous caller", got)
+	}
+}
+
+func TestRepository_BulkFollowTransition(t *testing.T) {
+	t.Parallel()
+	srv := &lifecycleTestServer{t: t, states: map[string]string{"a": "project", "b": "approved", "c": "obsolete", "d": "approved"}}
+	repo := newTestRepository(srv.respond)
+
+	// the documents leave the queried state while being transitioned
+	report, err := repo.BulkFollowTransition(context.Background(), "SELECT * FROM Document WHERE ecm:currentLifeCycleState = ?", []string{"approved"}, "obsolete")
+	if err != nil {
+		t.Fatalf("BulkFollowTransition() error = %v", err)
+	}
+	if len(report.Transitioned) != 2 || len(report.Failed) != 0 || report.Err() != nil {
+		t.Fatalf("BulkFollowTransition() = %+v", report)
+	}
+	for _, entry := range report.Transitioned {
+		if entry.State != "obsolete" {
+			t.Errorf("%s state = %s, want obsolete", entry.DocumentId, entry.State)
+		}
+	}
+
+	srv.states["b"] = "project"
+	report, err = repo.BulkFollowTransition(context.Background(), "SELECT * FROM Document WHERE ecm:currentLifeCycleState = ?", []string{"project"}, "approve")
+	if err != nil {
+		t.Fatalf("BulkFollowTransition() error = %v", err)
+	}
+	if len(report.Transitioned) != 2 {
+		t.Errorf("transitioned %+v, want a and b", report.Transitioned)
+	}
+
+	report, err = repo.BulkFollowTransition(context.Background(), "SELECT * FROM Document WHERE ecm:currentLifeCycleState = ?", []string{"obsolete"}, "approve")
+	if err != nil {
+		t.Fatalf("BulkFollowTransition() error = %v", err)
+	}
+	if len(report.Failed) != 2 || report.Err() == nil || report.Failed[0].State != "obsolete" {
+		t.Errorf("BulkFollowTransition() = %+v, want c and d failed", report)
+	}
+}
@@ -0,0 +1,122 @@
+package nuxeo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+/////////////////
+//// LOCKING ////
+/////////////////
+
+// LockInfo describes the lock held on a document.
+type LockInfo struct {
+	// Owner is the user holding the lock, empty if the document is not locked
+	Owner string
+	// Created is the date the lock was acquired
+	Created time.Time
+}
+
+// IsLocked returns true if the document is locked.
+func (l *LockInfo) IsLocked() bool {
+	return l.Owner != ""
+}
+
+// Lock locks a document, so that only the current user can modify it. A lock the current user already holds is kept.
+// Uses the Document.Lock operation.
+// Returns the locked Document, a *DocumentLockedError if the document is locked by another user, or error.
+func (r *repository) Lock(ctx context.Context, documentId string, options *nuxeoRequestOptions) (*Document, error) {
+	doc, _, err := r.lock(ctx, documentId, options)
+	return doc, err
+}
+
+// lock locks a document, and returns whether the lock was acquired, or was already held by the current user.
+func (r *repository) lock(ctx context.Context, documentId string, options *nuxeoRequestOptions) (*Document, bool, error) {
+	doc, err := r.executeForDocument(ctx, documentId, NewOperation(OperationDocumentLock), options)
+	if !hasErrorStatus(err, http.StatusConflict) {
+		return doc, err == nil, err
+	}
+	lockedErr := &DocumentLockedError{DocumentId: documentId, Err: err}
+	locked, infoErr := r.fetchLockedDocument(ctx, documentId)
+	if infoErr != nil {
+		return nil, false, lockedErr
+	}
+	lockInfo, infoErr := newLockInfo(locked)
+	if infoErr == nil {
+		lockedErr.Owner, lockedErr.Created = lockInfo.Owner, lockInfo.Created
+	}
+	currentUser, userErr := r.client.UserManager().FetchCurrentUser(ctx)
+	if userErr != nil || currentUser.IdOrUsername() != locked.LockOwner {
+		return nil, false, lockedErr
+	}
+	return locked, false, nil
+}
+
+// Unlock releases the lock of a document.
+// Uses the Document.Unlock operation.
+// Returns the unlocked Document or error.
+func (r *repository) Unlock(ctx context.Context, documentId string, options *nuxeoRequestOptions) (*Document, error) {
+	return r.executeForDocument(ctx, documentId, NewOperation(OperationDocumentUnlock), options)
+}
+
+// FetchLockInfo retrieves the lock owner and creation date of a document.
+// Maps to GET /api/v1/repo/{repo}/id/{id} with the "lock" fetch property.
+// Returns the LockInfo, whose Owner is empty if the document is not locked, or error.
+func (r *repository) FetchLockInfo(ctx context.Context, documentId string) (*LockInfo, error) {
+	doc, err := r.fetchLockedDocument(ctx, documentId)
+	if err != nil {
+		return nil, err
+	}
+	return newLockInfo(doc)
+}
+
+// fetchLockedDocument retrieves a document by ID along with its lock owner and creation date.
+func (r *repository) fetchLockedDocument(ctx context.Context, documentId string) (*Document, error) {
+	options := NewNuxeoRequestOptions().
+		SetRepositoryName(r.name).
+		SetFetchPropertiesForDocument([]string{FetchPropertyDocumentLock})
+	return r.FetchDocumentById(ctx, documentId, options)
+}
+
+// newLockInfo returns the LockInfo of a document fetched with its lock.
+func newLockInfo(doc *Document) (*LockInfo, error) {
+	lockInfo := &LockInfo{Owner: doc.LockOwner}
+	if doc.LockCreated != "" {
+		created, err := time.Parse(time.RFC3339, doc.LockCreated)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse lock creation date %q: %w", doc.LockCreated, err)
+		}
+		lockInfo.Created = created
+	}
+	return lockInfo, nil
+}
+
+// WithLock locks the document (a repository path or a document ID), runs fn with the locked document, and releases the
+// lock once fn returns, panics or the context is cancelled. A lock the current user already held is left in place.
+// Returns a *DocumentLockedError if the document is locked by another user, otherwise the error of fn joined with any
+// unlock error.
+func (r *repository) WithLock(ctx context.Context, documentRef string, fn func(doc *Document) error) (err error) {
+	doc, err := r.fetchDocument(ctx, documentRef, nil)
+	if err != nil {
+		return err
+	}
+	locked, acquired, err := r.lock(ctx, doc.ID, nil)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return fn(locked)
+	}
+	defer func() {
+		// release the lock even if the context was cancelled meanwhile
+		if _, unlockErr := r.Unlock(context.WithoutCancel(ctx), doc.ID, nil); unlockErr != nil {
+			r.logger.Error("Failed to release document lock", slog.String("error", unlockErr.Error()), slog.String("uid", doc.ID))
+			err = errors.Join(err, fmt.Errorf("failed to unlock document %s: %w", doc.ID, unlockErr))
+		}
+	}()
+	return fn(locked)
+}
@@ -0,0 +1,188 @@
+package nuxeo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockTestServer serves a single document "doc" at /ws/doc, which can be locked and unlocked by the current user,
+// "Administrator".
+type lockTestServer struct {
+	t       *testing.T
+	mu      sync.Mutex
+	owner   string
+	unlocks int
+}
+
+func (srv *lockTestServer) respond(req *http.Request) (*http.Response, error) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	doc := func() map[string]any {
+		doc := map[string]any{"entity-type": "document", "uid": "doc", "path": "/ws/doc"}
+		if srv.owner != "" {
+			doc["lockOwner"] = srv.owner
+			doc["lockCreated"] = "2024-05-01T10:30:00.000Z"
+		}
+		return doc
+	}
+
+	switch {
+	case req.URL.EscapedPath() == "/api/v1/repo/default/path/ws/doc" || req.URL.EscapedPath() == "/api/v1/repo/default/id/doc":
+		if strings.HasPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/id/") && req.Header.Get("fetch-document") != FetchPropertyDocumentLock {
+			srv.t.Errorf("lock info fetched without the lock fetch property")
+		}
+		return testJsonResponse(srv.t, 200, doc())
+	case strings.HasPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/"):
+		return testNotFoundResponse(srv.t)
+	case strings.HasSuffix(req.URL.EscapedPath(), "/"+OperationDocumentLock):
+		if srv.owner != "" {
+			return testJsonResponse(srv.t, 409, map[string]any{"entity-type": "exception", "message": "Document already locked by " + srv.owner})
+		}
+		srv.owner = "Administrator"
+		return testJsonResponse(srv.t, 200, doc())
+	case req.URL.EscapedPath() == "/site/automation/login":
+		return testJsonResponse(srv.t, 200, map[string]any{"username": "Administrator"})
+	case req.URL.EscapedPath() == "/api/v1/user/Administrator":
+		return testJsonResponse(srv.t, 200, NewUser("Administrator"))
+	case strings.HasSuffix(req.URL.EscapedPath(), "/"+OperationDocumentUnlock):
+		srv.unlocks++
+		srv.owner = ""
+		return testJsonResponse(srv.t, 200, doc())
+	}
+	return nil, errors.New("unexpected request " + req.URL.String())
+}
+
+func TestRepository_Lock(t *testing.T) {
+	t.Parallel()
+	srv := &lockTestServer{t: t, owner: "jdoe"}
+	repo := newTestRepository(srv.respond)
+
+	_, err := repo.Lock(context.Background(), "doc", nil)
+	var lockedErr *DocumentLockedError
+	if !errors.As(err, &lockedErr) {
+		t.Fatalf("Lock() error = %v, want DocumentLockedError", err)
+	}
+	if lockedErr.Owner != "jdoe" || !lockedErr.Created.Equal(time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)) {
+		t.Errorf("unexpected lock conflict: %+v", lockedErr)
+	}
+	if !hasErrorStatus(err, http.StatusConflict) {
+		t.Errorf("DocumentLockedError does not wrap the server error: %v", err)
+	}
+
+	if _, err := repo.Unlock(context.Background(), "doc", nil); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	lockInfo, err := repo.FetchLockInfo(context.Background(), "doc")
+	if err != nil || lockInfo.IsLocked() {
+		t.Fatalf("FetchLockInfo() = %+v, %v, want unlocked", lockInfo, err)
+	}
+	doc, err := repo.Lock(context.Background(), "doc", nil)
+	if err != nil || doc.LockOwner != "Administrator" {
+		t.Errorf("Lock() = %+v, %v", doc, err)
+	}
+	if err := repo.WithLock(context.Background(), "/ws/missing", func(doc *Document) error { return nil }); !hasErrorStatus(err, http.StatusNotFound) {
+		t.Errorf("WithLock() of a missing document error = %v, want not found", err)
+	}
+	doc, err = repo.Lock(context.Background(), "doc", nil)
+	if err != nil || doc.LockOwner != "Administrator" {
+		t.Errorf("Lock() of a document already locked by the current user = %+v, %v", doc, err)
+	}
+}
+
+func TestRepository_WithLock(t *testing.T) {
+	t.Parallel()
+	errCallback := errors.New("callback failed")
+	tests := []struct {
+		name        string
+		lockedBy    string
+		fn          func(ctx context.Context, cancel context.CancelFunc) error
+		panics      bool
+		wantErr     error
+		wantUnlocks int
+	}{
+		{
+			name:        "success",
+			fn:          func(ctx context.Context, cancel context.CancelFunc) error { return nil },
+			wantUnlocks: 1,
+		},
+		{
+			name:        "callback error",
+			fn:          func(ctx context.Context, cancel context.CancelFunc) error { return errCallback },
+			wantErr:     errCallback,
+			wantUnlocks: 1,
+		},
+		{
+			name:        "callback panic",
+			fn:          func(ctx context.Context, cancel context.CancelFunc) error { panic("boom") },
+			panics:      true,
+			wantUnlocks: 1,
+		},
+		{
+			name: "context cancelled",
+			fn: func(ctx context.Context, cancel context.CancelFunc) error {
+				cancel()
+				return ctx.Err()
+			},
+			wantErr:     context.Canceled,
+			wantUnlocks: 1,
+		},
+		{
+			name:        "locked by the current user",
+			lockedBy:    "Administrator",
+			fn:          func(ctx context.Context, cancel context.CancelFunc) error { return nil },
+			wantUnlocks: 0,
+		},
+		{
+			name:     "locked by someone else",
+			lockedBy: "jdoe",
+			fn: func(ctx context.Context, cancel context.CancelFunc) error {
+				t.Error("callback called without the lock")
+				return nil
+			},
+			wantErr: &DocumentLockedError{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			srv := &lockTestServer{t: t, owner: tt.lockedBy}
+			repo := newTestRepository(srv.respond)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var err error
+			func() {
+				defer func() {
+					if recovered := recover(); (recovered != nil) != tt.panics {
+						t.Errorf("recovered %v, want panic %v", recovered, tt.panics)
+					}
+				}()
+				err = repo.WithLock(ctx, "/ws/doc", func(doc *Document) error {
+					if doc.LockOwner != "Administrator" {
+						t.Errorf("callback got document locked by %q", doc.LockOwner)
+					}
+					return tt.fn(ctx, cancel)
+				})
+			}()
+
+			var lockedErr *DocumentLockedError
+			switch {
+			case errors.As(tt.wantErr, &lockedErr):
+				if !errors.As(err, &lockedErr) {
+					t.Errorf("WithLock() error = %v, want DocumentLockedError", err)
+				}
+			case !errors.Is(err, tt.wantErr):
+				t.Errorf("WithLock() error = %v, want %v", err, tt.wantErr)
+			}
+			if srv.unlocks != tt.wantUnlocks {
+				t.Errorf("unlocked %d times, want %d", srv.unlocks, tt.wantUnlocks)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package nuxeo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"slices"
+)
+
+////////////////////////////
+//// MOVE, COPY, RENAME ////
+////////////////////////////
+
+var (
+	// ErrNameCollision is returned when the target parent already holds a document with the same name
+	ErrNameCollision = errors.New("a document with the same name already exists")
+	// ErrForbidden is returned when the current user lacks the permissions to read the source or write into the target
+	ErrForbidden = errors.New("permission denied")
+	// ErrTitleNotUpdated is returned when a document was renamed, but its title could not be updated
+	ErrTitleNotUpdated = errors.New("document renamed, but its title was not updated")
+)
+
+// CopyProgress reports the progress of a CopyTree.
+type CopyProgress struct {
+	// Copied is the number of documents copied so far, including the current one
+	Copied int
+	// Total is the number of documents of the copied tree
+	Total int
+	// Source is the document just copied
+	Source *Document
+	// Copy is the resulting copy
+	Copy *Document
+}
+
+// CopyTreeOptions configures a recursive copy.
+type CopyTreeOptions struct {
+	// Progress is called after each copied document
+	Progress func(progress CopyProgress)
+}
+
+// Move moves a document (a repository path or a document ID) under the target parent, optionally renaming it.
+// An empty newName keeps the document name.
+// Uses the Document.Move operation.
+// Returns the moved Document, an error wrapping ErrNameCollision or ErrForbidden, or error.
+func (r *repository) Move(ctx context.Context, documentRef string, targetParentRef string, newName string, options *nuxeoRequestOptions) (*Document, error) {
+	operation := NewOperation(OperationDocumentMove).SetParam("target", targetParentRef)
+	if newName != "" {
+		operation.SetParam("name", newName)
+	}
+	doc, err := r.executeForDocument(ctx, documentRef, operation, options)
+	return doc, documentOperationError(err)
+}
+
+// Copy copies a document and its whole subtree (a repository path or a document ID) under the target parent, optionally
+// naming the copy. An empty newName keeps the document name, made unique by the server if needed.
+// Uses the Document.Copy operation, which copies folderish documents with all their descendants at once.
+// Returns the copied Document, an error wrapping ErrNameCollision or ErrForbidden, or error.
+func (r *repository) Copy(ctx context.Context, documentRef string, targetParentRef string, newName string, options *nuxeoRequestOptions) (*Document, error) {
+	operation := NewOperation(OperationDocumentCopy).SetParam("target", targetParentRef)
+	if newName != "" {
+		operation.SetParam("name", newName)
+	}
+	doc, err := r.executeForDocument(ctx, documentRef, operation, options)
+	return doc, documentOperationError(err)
+}
+
+// Rename changes the name of a document (a repository path or a document ID) within its parent, and its title.
+//
+// The name is changed by the Document.Move operation, and the title by a separate update, as no operation changes both.
+// If the update of the title fails, the document keeps its new name: the moved Document is returned along with an
+// error wrapping ErrTitleNotUpdated.
+// Returns the renamed Document, an error wrapping ErrNameCollision or ErrForbidden, or error.
+func (r *repository) Rename(ctx context.Context, documentRef string, newName string, options *nuxeoRequestOptions) (*Document, error) {
+	doc, err := r.fetchDocument(ctx, documentRef, nil)
+	if err != nil {
+		return nil, documentOperationError(err)
+	}
+	moved, err := r.Move(ctx, doc.ID, doc.ParentRef, newName, options)
+	if err != nil {
+		return nil, err
+	}
+	renamed, err := r.updateProperties(ctx, doc.ID, map[string]Field{DocumentPropertyDCTitle: NewStringField(newName)}, options)
+	if err != nil {
+		return moved, fmt.Errorf("%w: %w", ErrTitleNotUpdated, documentOperationError(err))
+	}
+	return renamed, nil
+}
+
+// CopyTree copies a document and its subtree (a repository path or a document ID) under the target parent one document
+// at a time, calling options.Progress after each copied document. An empty newName keeps the root document name.
+//
+// Folderish documents are recreated with their type and properties, other documents are copied with the Document.Copy
+// operation, along with their blobs. The tree is listed before copying, so that progress reports the total number of
+// documents. Returns the copy of the root document, or the first error met.
+func (r *repository) CopyTree(ctx context.Context, documentRef string, targetParentRef string, newName string, options *CopyTreeOptions) (*Document, error) {
+	if options == nil {
+		options = &CopyTreeOptions{}
+	}
+	target, err := r.fetchDocument(ctx, targetParentRef, nil)
+	if err != nil {
+		return nil, documentOperationError(err)
+	}
+	docs := []*Document{}
+	requestOptions := NewNuxeoRequestOptions().SetRepositoryName(r.name).SetSchemas([]string{"*"})
+	err = r.WalkTree(ctx, documentRef, func(doc *Document, depth int) error {
+		docs = append(docs, doc)
+		return nil
+	}, requestOptions)
+	if err != nil {
+		return nil, documentOperationError(err)
+	}
+
+	// copies maps the source folderish document IDs to their copy
+	copies := map[string]string{}
+	var root *Document
+	for i, doc := range docs {
+		parentId, name := target.ID, path.Base(doc.Path)
+		if i == 0 && newName != "" {
+			name = newName
+		}
+		if i > 0 {
+			parentId = copies[doc.ParentRef]
+		}
+		copied, err := r.copyOne(ctx, doc, parentId, name)
+		if err != nil {
+			r.logger.Error("Failed to copy document", slog.String("error", err.Error()), slog.String("uid", doc.ID), slog.String("path", doc.Path))
+			return nil, err
+		}
+		if doc.IsFolder() {
+			copies[doc.ID] = copied.ID
+		}
+		if i == 0 {
+			root = copied
+		}
+		if options.Progress != nil {
+			options.Progress(CopyProgress{Copied: i + 1, Total: len(docs), Source: doc, Copy: copied})
+		}
+	}
+	return root, nil
+}
+
+// copyOne copies a single document under the parent: folderish documents are recreated without their children.
+func (r *repository) copyOne(ctx context.Context, doc *Document, parentId string, name string) (*Document, error) {
+	if !doc.IsFolder() {
+		return r.Copy(ctx, doc.ID, parentId, name, nil)
+	}
+	properties := make(map[string]Field, len(doc.Properties))
+	for key, value := range doc.Properties {
+		if !value.IsNull() && !slices.Contains(migrationIgnoredProperties, key) {
+			properties[key] = value
+		}
+	}
+	folder, err := r.CreateDocumentById(ctx, parentId, Document{
+		entity:     entity{EntityType: EntityTypeDocument},
+		Type:       doc.Type,
+		Name:       name,
+		Properties: properties,
+	}, nil)
+	return folder, documentOperationError(err)
+}
+
+// documentOperationError wraps the name collision and permission errors returned by the server into ErrNameCollision and ErrForbidden.
+func documentOperationError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case hasErrorStatus(err, http.StatusConflict):
+		return fmt.Errorf("%w: %w", ErrNameCollision, err)
+	case hasErrorStatus(err, http.StatusForbidden):
+		return fmt.Errorf("%w: %w", ErrForbidden, err)
+	}
+	return err
+}
@@ -0,0 +1,199 @@
+package nuxeo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// moveTestResponder serves the document "doc" in the folder "parent", and records the operations executed on documents
+// into executed as "operation input params". Moving or copying into "full" fails with a name collision, and into
+// "private" with a permission error. Updating the title of doc to "readonly" fails with a permission error.
+func moveTestResponder(t *testing.T, executed *[]string) func(req *http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.URL.EscapedPath() == "/api/v1/repo/default/id/doc" && req.Method == http.MethodGet:
+			return testJsonResponse(t, 200, map[string]any{"entity-type": "document", "uid": "doc", "parentRef": "parent", "title": "Old"})
+		case req.URL.EscapedPath() == "/api/v1/repo/default/id/doc" && req.Method == http.MethodPut:
+			var body Document
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				return nil, err
+			}
+			title, _ := body.Properties[DocumentPropertyDCTitle].String()
+			*executed = append(*executed, "update "+*title)
+			if *title == "readonly" {
+				return testJsonResponse(t, 403, map[string]any{"entity-type": "exception", "status": 403, "message": "forbidden"})
+			}
+			return testJsonResponse(t, 200, map[string]any{"entity-type": "document", "uid": "doc", "parentRef": "parent", "title": *title})
+		case strings.HasPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/"):
+			return testNotFoundResponse(t)
+		case strings.HasPrefix(req.URL.EscapedPath(), "/site/automation/"):
+			var payload operationPayload
+			if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+				return nil, err
+			}
+			params, _ := json.Marshal(payload.Params)
+			*executed = append(*executed, strings.TrimPrefix(req.URL.EscapedPath(), "/site/automation/")+" "+payload.Input+" "+string(params))
+			switch payload.Params["target"] {
+			case "full":
+				return testJsonResponse(t, 409, map[string]any{"entity-type": "exception", "status": 409, "message": "name already used"})
+			case "private":
+				return testJsonResponse(t, 403, map[string]any{"entity-type": "exception", "status": 403, "message": "forbidden"})
+			}
+			return testJsonResponse(t, 200, map[string]any{"entity-type": "document", "uid": "result", "parentRef": payload.Params["target"]})
+		}
+		return nil, errors.New("unexpected request " + req.URL.String())
+	}
+}
+
+func TestRepository_MoveCopyRename(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		call         func(repo *repository) (*Document, error)
+		wantExecuted []string
+		wantErr      error
+	}{
+		{
+			name: "move",
+			call: func(repo *repository) (*Document, error) {
+				return repo.Move(context.Background(), "doc", "target", "", nil)
+			},
+			wantExecuted: []string{`Document.Move doc:doc {"target":"target"}`},
+		},
+		{
+			name: "move and rename",
+			call: func(repo *repository) (*Document, error) {
+				return repo.Move(context.Background(), "/ws/doc", "/ws/target", "new", nil)
+			},
+			wantExecuted: []string{`Document.Move doc:/ws/doc {"name":"new","target":"/ws/target"}`},
+		},
+		{
+			name: "copy",
+			call: func(repo *repository) (*Document, error) {
+				return repo.Copy(context.Background(), "doc", "target", "new", nil)
+			},
+			wantExecuted: []string{`Document.Copy doc:doc {"name":"new","target":"target"}`},
+		},
+		{
+			name: "rename",
+			call: func(repo *repository) (*Document, error) {
+				return repo.Rename(context.Background(), "doc", "new", nil)
+			},
+			wantExecuted: []string{`Document.Move doc:doc {"name":"new","target":"parent"}`, "update new"},
+		},
+		{
+			name: "name collision",
+			call: func(repo *repository) (*Document, error) {
+				return repo.Copy(context.Background(), "doc", "full", "", nil)
+			},
+			wantExecuted: []string{`Document.Copy doc:doc {"target":"full"}`},
+			wantErr:      ErrNameCollision,
+		},
+		{
+			name: "forbidden",
+			call: func(repo *repository) (*Document, error) {
+				return repo.Move(context.Background(), "doc", "private", "", nil)
+			},
+			wantExecuted: []string{`Document.Move doc:doc {"target":"private"}`},
+			wantErr:      ErrForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var executed []string
+			repo := newTestRepository(moveTestResponder(t, &executed))
+			got, err := tt.call(repo)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				var nuxeoErr *NuxeoError
+				if !errors.As(err, &nuxeoErr) {
+					t.Errorf("error %v does not wrap the server error", err)
+				}
+			} else if got == nil {
+				t.Errorf("got no document")
+			}
+			if strings.Join(executed, "\n") != strings.Join(tt.wantExecuted, "\n") {
+				t.Errorf("executed %q, want %q", executed, tt.wantExecuted)
+			}
+		})
+	}
+}
+
+func TestRepository_Rename_TitleNotUpdated(t *testing.T) {
+	t.Parallel()
+	var executed []string
+	repo := newTestRepository(moveTestResponder(t, &executed))
+
+	if _, err := repo.Rename(context.Background(), "missing", "new", nil); !hasErrorStatus(err, http.StatusNotFound) {
+		t.Errorf("Rename() of a missing document error = %v, want not found", err)
+	}
+	moved, err := repo.Rename(context.Background(), "doc", "readonly", nil)
+	if !errors.Is(err, ErrTitleNotUpdated) || !errors.Is(err, ErrForbidden) {
+		t.Fatalf("Rename() error = %v, want ErrTitleNotUpdated and ErrForbidden", err)
+	}
+	if moved == nil || moved.ID != "result" {
+		t.Errorf("Rename() = %+v, want the moved document", moved)
+	}
+	if want := []string{`Document.Move doc:doc {"name":"readonly","target":"parent"}`, "update readonly"}; strings.Join(executed, "\n") != strings.Join(want, "\n") {
+		t.Errorf("executed %q, want %q", executed, want)
+	}
+}
+
+func TestRepository_CopyTree(t *testing.T) {
+	t.Parallel()
+	tree := treeTestResponder(t, &atomic.Int32{})
+	created := []string{}
+	repo := newTestRepository(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.URL.EscapedPath() == "/api/v1/repo/default/path/target":
+			return testJsonResponse(t, 200, map[string]any{"entity-type": "document", "uid": "target", "path": "/target"})
+		case req.Method == http.MethodPost && strings.HasPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/id/"):
+			var body Document
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				return nil, err
+			}
+			parentId := strings.TrimPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/id/")
+			created = append(created, "create "+body.Type+" "+body.Name+" in "+parentId)
+			return testJsonResponse(t, 200, map[string]any{"entity-type": "document", "uid": "copy-" + body.Name, "type": body.Type})
+		case req.URL.EscapedPath() == "/site/automation/"+OperationDocumentCopy:
+			var payload operationPayload
+			if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+				return nil, err
+			}
+			created = append(created, "copy "+payload.Input+" in "+payload.Params["target"])
+			return testJsonResponse(t, 200, map[string]any{"entity-type": "document", "uid": "copy-" + strings.TrimPrefix(payload.Input, "doc:")})
+		}
+		return tree(req)
+	})
+
+	progress := []string{}
+	root, err := repo.CopyTree(context.Background(), "/ws", "/target", "ws-copy", &CopyTreeOptions{
+		Progress: func(p CopyProgress) {
+			progress = append(progress, p.Source.ID+"->"+p.Copy.ID)
+			if p.Copied != len(progress) || p.Total != 4 {
+				t.Errorf("progress %d/%d, want %d/4", p.Copied, p.Total, len(progress))
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("CopyTree() error = %v", err)
+	}
+	if root.ID != "copy-ws-copy" {
+		t.Errorf("CopyTree() = %s, want copy-ws-copy", root.ID)
+	}
+	wantCreated := "create Folder ws-copy in target,create Folder sub in copy-ws-copy,copy doc:note in copy-sub,copy doc:report in copy-ws-copy"
+	if got := strings.Join(created, ","); got != wantCreated {
+		t.Errorf("created %s, want %s", got, wantCreated)
+	}
+	if got := strings.Join(progress, ","); got != "ws->copy-ws-copy,sub->copy-sub,note->copy-note,report->copy-report" {
+		t.Errorf("progress %s", got)
+	}
+}
@@ -0,0 +1,176 @@
+package nuxeo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+)
+
+///////////////////////
+//// NOTIFICATIONS ////
+///////////////////////
+
+// notificationSubscriberPrefix prefixes the user names among the subscribers of a notification.
+const notificationSubscriberPrefix = "user:"
+
+// userSubscriptionsQuery lists the documents a user subscribed to notifications of.
+const userSubscriptionsQuery = "SELECT * FROM Document WHERE notif:notifications/*/subscribers/* = ? AND ecm:isVersion = 0 AND ecm:isTrashed = 0"
+
+// UserSubscriptions are the notifications a user subscribed to on a document.
+type UserSubscriptions struct {
+	Document      *Document
+	Notifications []string
+}
+
+// documentNotification is a notification of a document, along with its subscribers, as stored in notif:notifications.
+type documentNotification struct {
+	Name        string   `json:"name"`
+	Subscribers []string `json:"subscribers"`
+}
+
+// ListSubscribedNotifications retrieves the names of the notifications the current user subscribed to on a document (a
+// repository path or a document ID).
+// Uses the "subscribedNotifications" enricher.
+// Returns the notification names or error.
+func (r *repository) ListSubscribedNotifications(ctx context.Context, documentRef string) ([]string, error) {
+	options := NewNuxeoRequestOptions().SetRepositoryName(r.name).SetEnricherForDocument([]string{EnricherDocumentSubscribedNotifications})
+	doc, err := r.fetchDocument(ctx, documentRef, options)
+	if err != nil {
+		return nil, err
+	}
+	notifications := []string{}
+	if field, found := doc.ContextParameter(EnricherDocumentSubscribedNotifications); found && !field.IsNull() {
+		if err := field.ComplexList(&notifications); err != nil {
+			return nil, fmt.Errorf("failed to decode notifications of %s: %w", documentRef, err)
+		}
+	}
+	return notifications, nil
+}
+
+// Subscribe subscribes the current user to notifications of a document (a repository path or a document ID), or to all
+// of them when none is given.
+// Uses the Document.Subscribe operation.
+// Returns the Document or error.
+func (r *repository) Subscribe(ctx context.Context, documentRef string, notifications ...string) (*Document, error) {
+	operation := NewOperation(OperationDocumentSubscribe)
+	if len(notifications) > 0 {
+		operation.SetParam("notifications", strings.Join(notifications, ","))
+	}
+	return r.executeForDocument(ctx, documentRef, operation, nil)
+}
+
+// Unsubscribe unsubscribes the current user from notifications of a document (a repository path or a document ID), or
+// from all of them when none is given.
+// Uses the Document.Unsubscribe operation.
+// Returns the Document or error.
+func (r *repository) Unsubscribe(ctx context.Context, documentRef string, notifications ...string) (*Document, error) {
+	operation := NewOperation(OperationDocumentUnsubscribe)
+	if len(notifications) > 0 {
+		operation.SetParam("notifications", strings.Join(notifications, ","))
+	}
+	return r.executeForDocument(ctx, documentRef, operation, nil)
+}
+
+// SubscribeUser subscribes a user to notifications of a document (a repository path or a document ID). The notifications
+// must be named, as the server does not expose the notifications available for a document. The current user needs the
+// permission to write the document, as the subscribers are stored in its notif:notifications property.
+// Returns the updated Document or error.
+func (r *repository) SubscribeUser(ctx context.Context, documentRef string, username string, notifications ...string) (*Document, error) {
+	if len(notifications) == 0 {
+		return nil, errors.New("missing notification names")
+	}
+	return r.updateSubscribers(ctx, documentRef, func(documentNotifications []documentNotification) []documentNotification {
+		subscriber := notificationSubscriberPrefix + username
+		for _, name := range notifications {
+			i := slices.IndexFunc(documentNotifications, func(n documentNotification) bool { return n.Name == name })
+			if i < 0 {
+				documentNotifications = append(documentNotifications, documentNotification{Name: name})
+				i = len(documentNotifications) - 1
+			}
+			if !slices.Contains(documentNotifications[i].Subscribers, subscriber) {
+				documentNotifications[i].Subscribers = append(documentNotifications[i].Subscribers, subscriber)
+			}
+		}
+		return documentNotifications
+	})
+}
+
+// UnsubscribeUser unsubscribes a user from notifications of a document (a repository path or a document ID), or from
+// all of them when none is given. The current user needs the permission to write the document.
+// Returns the updated Document or error.
+func (r *repository) UnsubscribeUser(ctx context.Context, documentRef string, username string, notifications ...string) (*Document, error) {
+	return r.updateSubscribers(ctx, documentRef, func(documentNotifications []documentNotification) []documentNotification {
+		subscriber := notificationSubscriberPrefix + username
+		for i, notification := range documentNotifications {
+			if len(notifications) == 0 || slices.Contains(notifications, notification.Name) {
+				documentNotifications[i].Subscribers = slices.DeleteFunc(notification.Subscribers, func(s string) bool { return s == subscriber })
+			}
+		}
+		return slices.DeleteFunc(documentNotifications, func(n documentNotification) bool { return len(n.Subscribers) == 0 })
+	})
+}
+
+// updateSubscribers updates the notif:notifications property of a document, adding the Notifiable facet it belongs to
+// when missing.
+//
+// The property is fetched, updated and saved by separate requests without locking the document, so that a concurrent
+// change of the subscribers of the document between them is overwritten.
+func (r *repository) updateSubscribers(ctx context.Context, documentRef string, update func([]documentNotification) []documentNotification) (*Document, error) {
+	options := NewNuxeoRequestOptions().SetRepositoryName(r.name).SetSchemas([]string{DocumentSchemaNotification})
+	doc, err := r.fetchDocument(ctx, documentRef, options)
+	if err != nil {
+		return nil, err
+	}
+	documentNotifications := []documentNotification{}
+	if field, found := doc.Property(DocumentPropertyNotifNotifications); found && !field.IsNull() {
+		if err := field.ComplexList(&documentNotifications); err != nil {
+			return nil, fmt.Errorf("failed to decode notification subscribers of %s: %w", documentRef, err)
+		}
+	}
+	if !doc.HasFacet(DocumentFacetNotifiable) {
+		if err := r.executeOnDocument(ctx, doc.ID, NewOperation(OperationDocumentAddFacet).SetParam("facet", DocumentFacetNotifiable)); err != nil {
+			return nil, err
+		}
+	}
+
+	field, err := NewComplexField(update(documentNotifications))
+	if err != nil {
+		return nil, err
+	}
+	return r.updateProperties(ctx, doc.ID, map[string]Field{DocumentPropertyNotifNotifications: field}, options)
+}
+
+// ListUserSubscriptions iterates over the documents a user subscribed to notifications of, along with the names of
+// these notifications, fetching pages of pageSize documents lazily.
+// Iteration stops at the first error, which is yielded with nil subscriptions.
+func (r *repository) ListUserSubscriptions(ctx context.Context, username string, pageSize int) iter.Seq2[*UserSubscriptions, error] {
+	return func(yield func(*UserSubscriptions, error) bool) {
+		subscriber := notificationSubscriberPrefix + username
+		options := NewNuxeoRequestOptions().SetRepositoryName(r.name).SetSchemas([]string{DocumentSchemaNotification})
+		for doc, err := range r.QueryAll(ctx, userSubscriptionsQuery, []string{subscriber}, pageSize, options) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			documentNotifications := []documentNotification{}
+			if field, found := doc.Property(DocumentPropertyNotifNotifications); found && !field.IsNull() {
+				if err := field.ComplexList(&documentNotifications); err != nil {
+					yield(nil, fmt.Errorf("failed to decode notification subscribers of %s: %w", doc.ID, err))
+					return
+				}
+			}
+			subscriptions := &UserSubscriptions{Document: doc, Notifications: []string{}}
+			for _, notification := range documentNotifications {
+				if slices.Contains(notification.Subscribers, subscriber) {
+					subscriptions.Notifications = append(subscriptions.Notifications, notification.Name)
+				}
+			}
+			if !yield(subscriptions, nil) {
+				return
+			}
+		}
+	}
+}
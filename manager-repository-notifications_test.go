@@ -0,0 +1,179 @@
+package nuxeo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// notificationsTestServer serves the documents of notifications at /ws/{id}, each notification holding its
+// subscribers, and subscribes "Administrator" as the current user.
+type notificationsTestServer struct {
+	t             *testing.T
+	mu            sync.Mutex
+	notifications map[string][]documentNotification
+	notifiable    map[string]bool
+}
+
+func (srv *notificationsTestServer) respond(req *http.Request) (*http.Response, error) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	doc := func(id string) map[string]any {
+		facets := []string{}
+		if srv.notifiable[id] {
+			facets = append(facets, DocumentFacetNotifiable)
+		}
+		// like the server, the enricher lists the names of the notifications the current user subscribed to
+		subscribed := []any{}
+		for _, notification := range srv.notifications[id] {
+			if slices.Contains(notification.Subscribers, "user:Administrator") {
+				subscribed = append(subscribed, notification.Name)
+			}
+		}
+		return map[string]any{
+			"entity-type":       "document",
+			"uid":               id,
+			"path":              "/ws/" + id,
+			"facets":            facets,
+			"properties":        map[string]any{DocumentPropertyNotifNotifications: srv.notifications[id]},
+			"contextParameters": map[string]any{EnricherDocumentSubscribedNotifications: subscribed},
+		}
+	}
+	subscribe := func(id string, username string, notifications []string, subscribe bool) {
+		updated := []documentNotification{}
+		for _, name := range []string{NotificationCreation, NotificationModification} {
+			i := slices.IndexFunc(srv.notifications[id], func(n documentNotification) bool { return n.Name == name })
+			notification := documentNotification{Name: name}
+			if i >= 0 {
+				notification = srv.notifications[id][i]
+			}
+			if len(notifications) == 0 || slices.Contains(notifications, name) {
+				notification.Subscribers = slices.DeleteFunc(notification.Subscribers, func(s string) bool { return s == "user:"+username })
+				if subscribe {
+					notification.Subscribers = append(notification.Subscribers, "user:"+username)
+				}
+			}
+			if len(notification.Subscribers) > 0 {
+				updated = append(updated, notification)
+			}
+		}
+		srv.notifications[id] = updated
+	}
+
+	switch {
+	case strings.HasPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/path/ws/"):
+		return testJsonResponse(srv.t, 200, doc(strings.TrimPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/path/ws/")))
+	case strings.HasPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/id/") && req.Method == http.MethodPut:
+		id := strings.TrimPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/id/")
+		if !srv.notifiable[id] {
+			srv.t.Errorf("notifications of %s updated without the Notifiable facet", id)
+		}
+		var body struct {
+			Properties map[string][]documentNotification `json:"properties"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		srv.notifications[id] = body.Properties[DocumentPropertyNotifNotifications]
+		return testJsonResponse(srv.t, 200, doc(id))
+	case req.URL.EscapedPath() == "/api/v1/query":
+		subscriber := req.URL.Query().Get("queryParams")
+		entries := []any{}
+		for _, id := range slices.Sorted(maps.Keys(srv.notifications)) {
+			for _, notification := range srv.notifications[id] {
+				if slices.Contains(notification.Subscribers, subscriber) {
+					entries = append(entries, doc(id))
+					break
+				}
+			}
+		}
+		return testJsonResponse(srv.t, 200, map[string]any{"entity-type": "documents", "entries": entries})
+	case strings.HasPrefix(req.URL.EscapedPath(), "/site/automation/"):
+		var payload operationPayload
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			return nil, err
+		}
+		id := strings.TrimPrefix(payload.Input[strings.Index(payload.Input, ":")+1:], "/ws/")
+		notifications := []string{}
+		if payload.Params["notifications"] != "" {
+			notifications = strings.Split(payload.Params["notifications"], ",")
+		}
+		switch strings.TrimPrefix(req.URL.EscapedPath(), "/site/automation/") {
+		case OperationDocumentAddFacet:
+			srv.notifiable[id] = payload.Params["facet"] == DocumentFacetNotifiable
+			return &http.Response{StatusCode: 204, Body: http.NoBody}, nil
+		case OperationDocumentSubscribe:
+			subscribe(id, "Administrator", notifications, true)
+		case OperationDocumentUnsubscribe:
+			subscribe(id, "Administrator", notifications, false)
+		}
+		return testJsonResponse(srv.t, 200, doc(id))
+	}
+	return nil, errors.New("unexpected request " + req.URL.String())
+}
+
+func TestRepository_Subscribe(t *testing.T) {
+	t.Parallel()
+	srv := &notificationsTestServer{t: t, notifications: map[string][]documentNotification{}, notifiable: map[string]bool{}}
+	repo := newTestRepository(srv.respond)
+	ctx := context.Background()
+
+	if _, err := repo.Subscribe(ctx, "/ws/a"); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if _, err := repo.Unsubscribe(ctx, "/ws/a", NotificationCreation); err != nil {
+		t.Fatalf("Unsubscribe() error = %v", err)
+	}
+	notifications, err := repo.ListSubscribedNotifications(ctx, "/ws/a")
+	if err != nil {
+		t.Fatalf("ListSubscribedNotifications() error = %v", err)
+	}
+	if want := []string{NotificationModification}; !slices.Equal(notifications, want) {
+		t.Errorf("ListSubscribedNotifications() = %v, want %v", notifications, want)
+	}
+}
+
+func TestRepository_SubscribeUser(t *testing.T) {
+	t.Parallel()
+	srv := &notificationsTestServer{t: t, notifications: map[string][]documentNotification{
+		"b": {{Name: NotificationModification, Subscribers: []string{"user:bob"}}},
+	}, notifiable: map[string]bool{"b": true}}
+	repo := newTestRepository(srv.respond)
+	ctx := context.Background()
+
+	if _, err := repo.SubscribeUser(ctx, "/ws/a", "alice"); err == nil {
+		t.Error("SubscribeUser() without notification names succeeded")
+	}
+	if _, err := repo.SubscribeUser(ctx, "/ws/a", "alice", NotificationCreation, NotificationModification); err != nil {
+		t.Fatalf("SubscribeUser() error = %v", err)
+	}
+	if _, err := repo.SubscribeUser(ctx, "/ws/b", "alice", NotificationModification); err != nil {
+		t.Fatalf("SubscribeUser() error = %v", err)
+	}
+	if _, err := repo.UnsubscribeUser(ctx, "/ws/a", "alice", NotificationCreation); err != nil {
+		t.Fatalf("UnsubscribeUser() error = %v", err)
+	}
+	if _, err := repo.UnsubscribeUser(ctx, "/ws/b", "bob"); err != nil {
+		t.Fatalf("UnsubscribeUser() error = %v", err)
+	}
+
+	got := []string{}
+	for subscriptions, err := range repo.ListUserSubscriptions(ctx, "alice", 0) {
+		if err != nil {
+			t.Fatalf("ListUserSubscriptions() error = %v", err)
+		}
+		got = append(got, subscriptions.Document.ID+":"+strings.Join(subscriptions.Notifications, ","))
+	}
+	if strings.Join(got, " ") != "a:Modification b:Modification" {
+		t.Errorf("ListUserSubscriptions() = %v", got)
+	}
+	for subscriptions, err := range repo.ListUserSubscriptions(ctx, "bob", 0) {
+		t.Errorf("ListUserSubscriptions(bob) = %+v, %v, want none", subscriptions, err)
+	}
+}
@@ -0,0 +1,714 @@
+package nuxeo
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+///////////////////////////
+//// NUXEO IO ARCHIVES ////
+///////////////////////////
+
+// IOArchiveDocumentFile is the name of the XML file describing each document of a Nuxeo IO archive.
+const IOArchiveDocumentFile = "document.xml"
+
+// IOArchiveMarkerFile is the name of the empty entry marking a zip file as a Nuxeo IO archive.
+const IOArchiveMarkerFile = ".nuxeo-archive"
+
+// ioSchemaNamespace is the namespace URI prefix of schemas in document.xml files.
+const ioSchemaNamespace = "http://www.nuxeo.org/ecm/schemas/"
+
+// ExportIOArchive writes the document tree rooted at rootRef (a repository path or a document ID) as a Nuxeo IO archive
+// streamed to w, as produced by the server-side core IO export.
+//
+// Each document is written as a directory named after the document, holding a document.xml file with its type, lifecycle
+// state and policy, facets, local ACLs and properties, and one "<digest>.blob" entry per blob. Blobs are streamed one at a time.
+func (r *repository) ExportIOArchive(ctx context.Context, rootRef string, w io.Writer) (*ExportReport, error) {
+	schemaNames := r.schemaNamesByPrefix(ctx)
+	archive := zip.NewWriter(w)
+	if _, err := archive.Create(IOArchiveMarkerFile); err != nil {
+		return nil, fmt.Errorf("failed to create archive marker: %w", err)
+	}
+
+	report := &ExportReport{}
+	dirs := map[string]string{}
+	requestOptions := NewNuxeoRequestOptions().
+		SetRepositoryName(r.name).
+		SetSchemas([]string{"*"}).
+		SetEnricherForDocument([]string{EnricherDocumentACLs})
+	err := r.WalkTree(ctx, rootRef, func(doc *Document, depth int) error {
+		dir := ioDocumentName(doc)
+		if depth > 0 {
+			dir = path.Join(dirs[doc.ParentRef], dir)
+		}
+		dirs[doc.ID] = dir
+
+		// the lifecycle policy is not part of the document JSON, and is only exported when it can be resolved
+		lifecyclePolicy, err := r.fetchLifecyclePolicy(ctx, doc.ID)
+		if err != nil {
+			r.logger.Warn("Failed to fetch lifecycle policy, exporting without it", slog.String("error", err.Error()), slog.String("uid", doc.ID))
+		}
+		documentXml, blobs, err := newIODocumentXml(doc, dir, lifecyclePolicy, schemaNames)
+		if err != nil {
+			return err
+		}
+		entryWriter, err := archive.CreateHeader(ioEntryHeader(path.Join(dir, IOArchiveDocumentFile), doc))
+		if err != nil {
+			return fmt.Errorf("failed to create archive entry: %w", err)
+		}
+		if _, err := entryWriter.Write(documentXml); err != nil {
+			return err
+		}
+		for _, ioBlob := range blobs {
+			if err := r.writeIOBlob(ctx, archive, doc, ioBlob, path.Join(dir, ioBlob.entry)); err != nil {
+				r.logger.Error("Failed to export blob into archive", slog.String("error", err.Error()), slog.String("uid", doc.ID), slog.String("xpath", ioBlob.xpath))
+				return err
+			}
+		}
+		report.Exported = append(report.Exported, ExportReportEntry{DocumentId: doc.ID, DocumentPath: doc.Path, LocalPath: dir})
+		return nil
+	}, requestOptions)
+	if err != nil {
+		return nil, err
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close archive: %w", err)
+	}
+	return report, nil
+}
+
+// ImportIOArchive recreates the documents of a Nuxeo IO archive under the parent document (a repository path or a document ID).
+//
+// Documents are created by parent path in archive order, parents first, with their type, properties and
+// blobs, uploaded through a batch. Granted entries of local ACLs, and blocked inheritance, are then applied.
+// Failures of single documents do not stop the import: they are listed in the report along with their descendants.
+func (r *repository) ImportIOArchive(ctx context.Context, archive io.ReaderAt, size int64, parentRef string) (*ImportReport, error) {
+	zr, err := zip.NewReader(archive, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	entries := map[string]*zip.File{}
+	dirs := []string{}
+	for _, f := range zr.File {
+		entries[f.Name] = f
+		if path.Base(f.Name) == IOArchiveDocumentFile {
+			dirs = append(dirs, path.Dir(f.Name))
+		}
+	}
+	// parents first, then by name
+	sort.Slice(dirs, func(i, j int) bool {
+		di, dj := strings.Count(dirs[i], "/"), strings.Count(dirs[j], "/")
+		if di != dj {
+			return di < dj
+		}
+		return dirs[i] < dirs[j]
+	})
+
+	parent, err := r.fetchDocument(ctx, parentRef, nil)
+	if err != nil {
+		return nil, err
+	}
+	batchUploadManager := r.client.BatchUploadManager()
+	batch, err := batchUploadManager.CreateBatch(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := batchUploadManager.CancelBatch(context.WithoutCancel(ctx), batch.BatchId, nil); err != nil {
+			r.logger.Warn("Failed to clean up import batch", slog.String("error", err.Error()), slog.String("batchId", batch.BatchId))
+		}
+	}()
+
+	imp := &ioArchiveImport{repo: r, entries: entries, batch: batch}
+	report := &ImportReport{}
+	isDocumentDir := map[string]bool{}
+	for _, dir := range dirs {
+		isDocumentDir[dir] = true
+	}
+	remotePaths := map[string]string{}
+	for _, dir := range dirs {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		// top level documents are created under the parent
+		parentPath := parent.Path
+		if parentDir := path.Dir(dir); isDocumentDir[parentDir] {
+			var found bool
+			if parentPath, found = remotePaths[parentDir]; !found {
+				report.Failed = append(report.Failed, ImportReportEntry{LocalPath: dir, Err: fmt.Errorf("parent %s was not imported", parentDir)})
+				continue
+			}
+		}
+		created, err := imp.importDocument(ctx, dir, parentPath)
+		if err != nil {
+			r.logger.Error("Failed to import archive document", slog.String("error", err.Error()), slog.String("path", dir))
+			report.Failed = append(report.Failed, ImportReportEntry{LocalPath: dir, Err: err})
+			continue
+		}
+		remotePaths[dir] = created.Path
+		report.Created = append(report.Created, ImportReportEntry{LocalPath: dir, DocumentId: created.ID, DocumentPath: created.Path})
+	}
+	return report, nil
+}
+
+// ioArchiveImport holds the state of a running archive import.
+type ioArchiveImport struct {
+	repo        *repository
+	entries     map[string]*zip.File
+	batch       *batchUpload
+	nextFileIdx int
+}
+
+// importDocument creates the document described by dir/document.xml under the parent path, then applies its ACLs.
+func (imp *ioArchiveImport) importDocument(ctx context.Context, dir string, parentPath string) (*Document, error) {
+	ioDoc, err := imp.readDocument(dir)
+	if err != nil {
+		return nil, err
+	}
+	doc := Document{
+		entity:     entity{EntityType: EntityTypeDocument},
+		Type:       ioDoc.Type,
+		Name:       path.Base(dir),
+		Properties: map[string]Field{},
+	}
+	for key, value := range ioDoc.Properties {
+		value, err := imp.uploadBlobs(ctx, dir, value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload blobs of %s: %w", key, err)
+		}
+		field, err := NewField(value)
+		if err != nil {
+			return nil, err
+		}
+		doc.SetProperty(key, field)
+	}
+
+	created, err := imp.repo.createDocument(ctx, parentPath, doc, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := imp.repo.applyACLs(ctx, created.ID, ioDoc.ACLs); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// readDocument parses the document.xml file of dir.
+func (imp *ioArchiveImport) readDocument(dir string) (*ioDocument, error) {
+	f, found := imp.entries[path.Join(dir, IOArchiveDocumentFile)]
+	if !found {
+		return nil, fmt.Errorf("missing %s in %s", IOArchiveDocumentFile, dir)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return parseIODocument(rc)
+}
+
+// uploadBlobs uploads the archive blobs referenced in the property value, replacing them with their upload information.
+func (imp *ioArchiveImport) uploadBlobs(ctx context.Context, dir string, value any) (any, error) {
+	switch v := value.(type) {
+	case ioBlobRef:
+		f, found := imp.entries[path.Join(dir, v.Data)]
+		if !found {
+			return nil, fmt.Errorf("missing blob entry %s", v.Data)
+		}
+		stream, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		blob := NewBlob(v.Filename, v.MimeType, int64(f.UncompressedSize64), stream)
+		blob.open = f.Open
+		defer blob.Close()
+
+		fileIdx := imp.nextFileIdx
+		imp.nextFileIdx++
+		upload, err := imp.repo.client.BatchUploadManager().UploadBlob(ctx, imp.batch, fileIdx, blob, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		return UploadInfo{Batch: imp.batch.BatchId, FileId: uploadedFileIdx(upload, fileIdx)}, nil
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			uploaded, err := imp.uploadBlobs(ctx, dir, item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = uploaded
+		}
+		return items, nil
+	case map[string]any:
+		fields := make(map[string]any, len(v))
+		for key, item := range v {
+			uploaded, err := imp.uploadBlobs(ctx, dir, item)
+			if err != nil {
+				return nil, err
+			}
+			fields[key] = uploaded
+		}
+		return fields, nil
+	}
+	return value, nil
+}
+
+// writeIOBlob streams a document blob into the archive entry.
+func (r *repository) writeIOBlob(ctx context.Context, archive *zip.Writer, doc *Document, ioBlob ioBlobEntry, entryName string) error {
+	stream, err := r.StreamBlobById(ctx, doc.ID, ioBlob.xpath, nil)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	entryWriter, err := archive.CreateHeader(ioEntryHeader(entryName, doc))
+	if err != nil {
+		return fmt.Errorf("failed to create archive entry: %w", err)
+	}
+	_, err = io.Copy(entryWriter, stream)
+	return err
+}
+
+// applyACLs grants the entries of the ACLs on the document, blocking inheritance for "Everyone"/"Everything" denials.
+// Inherited ACLs and other denials, which cannot be set through Automation, are left out.
+func (r *repository) applyACLs(ctx context.Context, documentId string, acls []ACL) error {
+	for _, acl := range acls {
+		if acl.Name == AclInherit {
+			continue
+		}
+		for _, ace := range acl.ACEs {
+			var operation *operation
+			switch {
+			case ace.Granted:
+				operation = grantPermissionOperation(acl.Name, ace, nil)
+			case ace.Username == GroupEveryone && ace.Permission == PermissionEverything:
+				operation = NewOperation(OperationDocumentBlockInheritance).SetParam("acl", acl.Name)
+			default:
+				r.logger.Warn("Skipping denied permission", slog.String("uid", documentId), slog.String("username", ace.Username), slog.String("permission", ace.Permission))
+				continue
+			}
+			if err := r.executeOnDocument(ctx, documentId, operation); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// schemaNamesByPrefix returns the schema names keyed by prefix, or an empty map if the data model is not readable.
+func (r *repository) schemaNamesByPrefix(ctx context.Context) map[string]string {
+	names := map[string]string{}
+	schemas, err := r.client.DataModelManager().FetchSchemas(ctx)
+	if err != nil {
+		r.logger.Warn("Failed to fetch schemas, using prefixes as schema names", slog.String("error", err.Error()))
+		return names
+	}
+	for _, schema := range *schemas {
+		if prefix := schema.GetPrefix(); prefix != "" {
+			names[prefix] = schema.Name
+		}
+	}
+	return names
+}
+
+// ioEntryHeader returns the archive entry header of a document file, dated with the document's modification date.
+func ioEntryHeader(name string, doc *Document) *zip.FileHeader {
+	header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+	if modified := documentModified(doc); !modified.IsZero() {
+		header.Modified = modified
+	}
+	return header
+}
+
+// ioDocumentName returns the name of the document, from its path.
+func ioDocumentName(doc *Document) string {
+	if doc.Path != "" && doc.Path != "/" {
+		return sanitizeFilename(path.Base(doc.Path))
+	}
+	if doc.Name != "" {
+		return sanitizeFilename(doc.Name)
+	}
+	return doc.ID
+}
+
+/////////////////////////
+//// DOCUMENT.XML IO ////
+/////////////////////////
+
+// ioDocument is a document read from a document.xml file.
+type ioDocument struct {
+	Type  string
+	Path  string
+	State string
+	// LifecyclePolicy is the name of the lifecycle policy the state belongs to
+	LifecyclePolicy string
+	Facets          []string
+	ACLs            []ACL
+	Properties      map[string]any
+}
+
+// ioBlobRef is a blob property value of a document.xml file, stored in the archive entry named Data.
+type ioBlobRef struct {
+	Filename string
+	MimeType string
+	Encoding string
+	Digest   string
+	Data     string
+}
+
+// ioBlobEntry is a blob to write into the archive entry along with its document.xml.
+type ioBlobEntry struct {
+	xpath string
+	entry string
+}
+
+// xmlNode is a generic XML element, used to read and write document.xml files.
+type xmlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Content string     `xml:",chardata"`
+	Nodes   []xmlNode  `xml:",any"`
+}
+
+// child returns the first child element with the local name.
+func (n *xmlNode) child(local string) *xmlNode {
+	for i := range n.Nodes {
+		if n.Nodes[i].XMLName.Local == local {
+			return &n.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// attr returns the value of the attribute with the local name.
+func (n *xmlNode) attr(local string) string {
+	for _, attr := range n.Attrs {
+		if attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// text returns the trimmed text content of the element.
+func (n *xmlNode) text() string {
+	return strings.TrimSpace(n.Content)
+}
+
+// newXmlNode returns an element with the given local name, text content and children.
+func newXmlNode(local string, content string, nodes ...xmlNode) xmlNode {
+	return xmlNode{XMLName: xml.Name{Local: local}, Content: content, Nodes: nodes}
+}
+
+// newIODocumentXml renders the document.xml file of the document, returning the blobs to write along with it.
+func newIODocumentXml(doc *Document, dir string, lifecyclePolicy string, schemaNames map[string]string) ([]byte, []ioBlobEntry, error) {
+	system := newXmlNode("system", "",
+		newXmlNode("type", doc.Type),
+		newXmlNode("path", dir),
+		newXmlNode("lifecycleState", doc.State),
+	)
+	if lifecyclePolicy != "" {
+		system.Nodes = append(system.Nodes, newXmlNode("lifecyclePolicy", lifecyclePolicy))
+	}
+	for _, facet := range doc.Facets {
+		system.Nodes = append(system.Nodes, newXmlNode("facet", facet))
+	}
+	if field, found := doc.ContextParameter(EnricherDocumentACLs); found {
+		var acls []ACL
+		if err := field.ComplexList(&acls); err == nil {
+			system.Nodes = append(system.Nodes, ioAccessControlXml(acls))
+		}
+	}
+
+	root := newXmlNode("document", "", system)
+	root.Attrs = []xml.Attr{
+		{Name: xml.Name{Local: "repository"}, Value: doc.Repository},
+		{Name: xml.Name{Local: "id"}, Value: doc.ID},
+	}
+
+	// group the properties per schema prefix
+	keysByPrefix := map[string][]string{}
+	for key := range doc.Properties {
+		prefix, _, _ := strings.Cut(key, ":")
+		keysByPrefix[prefix] = append(keysByPrefix[prefix], key)
+	}
+	prefixes := make([]string, 0, len(keysByPrefix))
+	for prefix := range keysByPrefix {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	writer := &ioDocumentXmlWriter{entries: map[string]bool{}}
+	for _, prefix := range prefixes {
+		schemaName := prefix
+		if name, found := schemaNames[prefix]; found {
+			schemaName = name
+		}
+		schema := newXmlNode("schema", "")
+		schema.Attrs = []xml.Attr{
+			{Name: xml.Name{Local: "name"}, Value: schemaName},
+			{Name: xml.Name{Local: "xmlns:" + prefix}, Value: ioSchemaNamespace + schemaName + "/"},
+		}
+		keys := keysByPrefix[prefix]
+		sort.Strings(keys)
+		for _, key := range keys {
+			var value any
+			decoder := json.NewDecoder(bytes.NewReader(doc.Properties[key]))
+			decoder.UseNumber()
+			if err := decoder.Decode(&value); err != nil {
+				return nil, nil, fmt.Errorf("failed to decode property %s: %w", key, err)
+			}
+			schema.Nodes = append(schema.Nodes, writer.valueXml(key, value, key))
+		}
+		root.Nodes = append(root.Nodes, schema)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(root); err != nil {
+		return nil, nil, fmt.Errorf("failed to write %s: %w", IOArchiveDocumentFile, err)
+	}
+	return buf.Bytes(), writer.blobs, nil
+}
+
+// ioAccessControlXml renders the non-inherited ACLs as an access-control element.
+func ioAccessControlXml(acls []ACL) xmlNode {
+	accessControl := newXmlNode("access-control", "")
+	for _, acl := range acls {
+		if acl.Name == AclInherit {
+			continue
+		}
+		aclNode := newXmlNode("acl", "")
+		aclNode.Attrs = []xml.Attr{{Name: xml.Name{Local: "name"}, Value: acl.Name}}
+		for _, ace := range acl.ACEs {
+			entry := newXmlNode("entry", "")
+			entry.Attrs = []xml.Attr{
+				{Name: xml.Name{Local: "principal"}, Value: ace.Username},
+				{Name: xml.Name{Local: "permission"}, Value: ace.Permission},
+				{Name: xml.Name{Local: "grant"}, Value: strconv.FormatBool(ace.Granted)},
+			}
+			if ace.Begin != nil {
+				entry.Attrs = append(entry.Attrs, xml.Attr{Name: xml.Name{Local: "begin"}, Value: time.Time(*ace.Begin).UTC().Format(ISO8601TimeLayout)})
+			}
+			if ace.End != nil {
+				entry.Attrs = append(entry.Attrs, xml.Attr{Name: xml.Name{Local: "end"}, Value: time.Time(*ace.End).UTC().Format(ISO8601TimeLayout)})
+			}
+			aclNode.Nodes = append(aclNode.Nodes, entry)
+		}
+		accessControl.Nodes = append(accessControl.Nodes, aclNode)
+	}
+	return accessControl
+}
+
+// ioDocumentXmlWriter renders property values, collecting the blobs they hold.
+type ioDocumentXmlWriter struct {
+	blobs   []ioBlobEntry
+	entries map[string]bool
+}
+
+// valueXml renders a JSON property value as an element: lists as "item" children, complex values as child elements,
+// and blobs as their metadata along with the archive entry holding their content.
+func (w *ioDocumentXmlWriter) valueXml(name string, value any, xpath string) xmlNode {
+	switch v := value.(type) {
+	case nil:
+		return newXmlNode(name, "")
+	case []any:
+		node := newXmlNode(name, "")
+		for i, item := range v {
+			node.Nodes = append(node.Nodes, w.valueXml("item", item, xpath+"/"+strconv.Itoa(i)))
+		}
+		return node
+	case map[string]any:
+		if isBlobMap(v) {
+			return w.blobXml(name, v, xpath)
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		node := newXmlNode(name, "")
+		for _, key := range keys {
+			node.Nodes = append(node.Nodes, w.valueXml(key, v[key], xpath+"/"+key))
+		}
+		return node
+	}
+	return newXmlNode(name, fmt.Sprint(value))
+}
+
+// blobXml renders a blob value, naming its archive entry after its digest.
+func (w *ioDocumentXmlWriter) blobXml(name string, value map[string]any, xpath string) xmlNode {
+	str := func(key string) string {
+		s, _ := value[key].(string)
+		return s
+	}
+	entry := str("digest")
+	if entry == "" {
+		entry = strings.ReplaceAll(xpath, "/", "-")
+	}
+	entry = sanitizeFilename(entry) + ".blob"
+	if !w.entries[entry] {
+		w.entries[entry] = true
+		w.blobs = append(w.blobs, ioBlobEntry{xpath: xpath, entry: entry})
+	}
+	return newXmlNode(name, "",
+		newXmlNode("encoding", str("encoding")),
+		newXmlNode("mime-type", str("mime-type")),
+		newXmlNode("filename", str("name")),
+		newXmlNode("digest", str("digest")),
+		newXmlNode("data", entry),
+	)
+}
+
+// isBlobMap returns true if the decoded JSON object is a blob.
+func isBlobMap(value map[string]any) bool {
+	_, hasName := value["name"]
+	_, hasMimeType := value["mime-type"]
+	_, hasDigest := value["digest"]
+	_, hasData := value["data"]
+	return (hasName || hasMimeType) && (hasDigest || hasData)
+}
+
+// parseIODocument reads a document.xml file.
+func parseIODocument(r io.Reader) (*ioDocument, error) {
+	var root xmlNode
+	if err := xml.NewDecoder(r).Decode(&root); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", IOArchiveDocumentFile, err)
+	}
+	doc := &ioDocument{Properties: map[string]any{}}
+	if system := root.child("system"); system != nil {
+		for _, node := range system.Nodes {
+			switch node.XMLName.Local {
+			case "type":
+				doc.Type = node.text()
+			case "path":
+				doc.Path = node.text()
+			case "lifecycleState":
+				doc.State = node.text()
+			case "lifecyclePolicy":
+				doc.LifecyclePolicy = node.text()
+			case "facet":
+				doc.Facets = append(doc.Facets, node.text())
+			case "access-control":
+				doc.ACLs = parseIOAccessControl(node)
+			}
+		}
+	}
+	if doc.Type == "" {
+		return nil, fmt.Errorf("missing document type in %s", IOArchiveDocumentFile)
+	}
+
+	for _, schema := range root.Nodes {
+		if schema.XMLName.Local != "schema" {
+			continue
+		}
+		// properties are keyed by the prefix bound to their namespace, or the schema name
+		prefixes := map[string]string{}
+		for _, attr := range schema.Attrs {
+			if attr.Name.Space == "xmlns" {
+				prefixes[attr.Value] = attr.Name.Local
+			}
+		}
+		for _, property := range schema.Nodes {
+			prefix, found := prefixes[property.XMLName.Space]
+			if !found {
+				prefix = schema.attr("name")
+			}
+			doc.Properties[prefix+":"+property.XMLName.Local] = parseIOValue(property)
+		}
+	}
+	return doc, nil
+}
+
+// parseIOAccessControl reads the ACLs of an access-control element.
+func parseIOAccessControl(node xmlNode) []ACL {
+	acls := []ACL{}
+	for _, aclNode := range node.Nodes {
+		if aclNode.XMLName.Local != "acl" {
+			continue
+		}
+		acl := ACL{Name: aclNode.attr("name")}
+		for _, entry := range aclNode.Nodes {
+			ace := ACE{
+				Username:   entry.attr("principal"),
+				Permission: entry.attr("permission"),
+				Granted:    entry.attr("grant") == "true",
+			}
+			ace.Begin = parseIOTime(entry.attr("begin"))
+			ace.End = parseIOTime(entry.attr("end"))
+			acl.ACEs = append(acl.ACEs, ace)
+		}
+		acls = append(acls, acl)
+	}
+	return acls
+}
+
+// parseIOTime parses an ACE date attribute, returning nil if missing or invalid.
+func parseIOTime(value string) *ISO8601Time {
+	parsed, err := time.Parse(ISO8601TimeLayout, value)
+	if err != nil {
+		return nil
+	}
+	isoTime := ISO8601Time(parsed)
+	return &isoTime
+}
+
+// parseIOValue reads a property value: "item" children as lists, blob metadata as ioBlobRef, other children as complex
+// values and text as strings, which the server converts to the property type.
+func parseIOValue(node xmlNode) any {
+	if len(node.Nodes) == 0 {
+		if text := node.text(); text != "" {
+			return text
+		}
+		return nil
+	}
+	if data := node.child("data"); data != nil && (node.child("filename") != nil || node.child("mime-type") != nil) {
+		blobRef := ioBlobRef{Data: data.text()}
+		if filename := node.child("filename"); filename != nil {
+			blobRef.Filename = filename.text()
+		}
+		if mimeType := node.child("mime-type"); mimeType != nil {
+			blobRef.MimeType = mimeType.text()
+		}
+		if encoding := node.child("encoding"); encoding != nil {
+			blobRef.Encoding = encoding.text()
+		}
+		if digest := node.child("digest"); digest != nil {
+			blobRef.Digest = digest.text()
+		}
+		return blobRef
+	}
+
+	isList := true
+	for _, child := range node.Nodes {
+		if child.XMLName.Local != "item" {
+			isList = false
+			break
+		}
+	}
+	if isList {
+		items := make([]any, len(node.Nodes))
+		for i, child := range node.Nodes {
+			items[i] = parseIOValue(child)
+		}
+		return items
+	}
+	fields := map[string]any{}
+	for _, child := range node.Nodes {
+		fields[child.XMLName.Local] = parseIOValue(child)
+	}
+	return fields
+}
@@ -0,0 +1,184 @@
+package nuxeo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRepository_ExportIOArchive(t *testing.T) {
+	t.Parallel()
+	treeResponder := treeTestResponder(t, &atomic.Int32{})
+	repo := newTestRepository(func(req *http.Request) (*http.Response, error) {
+		if req.URL.EscapedPath() == "/api/v1/config/schemas" {
+			return testJsonResponse(t, 200, []any{map[string]any{"name": "dublincore", "prefix": "dc"}})
+		}
+		if req.URL.EscapedPath() == "/site/automation/"+OperationRepositoryResultSetQuery {
+			return testJsonResponse(t, 200, map[string]any{"entity-type": "recordSet", "entries": []any{map[string]any{"ecm:lifeCyclePolicy": "default"}}})
+		}
+		return treeResponder(req)
+	})
+
+	var buf bytes.Buffer
+	report, err := repo.ExportIOArchive(context.Background(), "/ws", &buf)
+	if err != nil {
+		t.Fatalf("ExportIOArchive() error = %v", err)
+	}
+	if len(report.Exported) != 4 {
+		t.Errorf("exported %d documents, want 4", len(report.Exported))
+	}
+
+	entries := readTestZip(t, buf.Bytes())
+	for _, name := range []string{IOArchiveMarkerFile, "ws/document.xml", "ws/sub/document.xml", "ws/sub/note/document.xml", "ws/report/document.xml"} {
+		if _, found := entries[name]; !found {
+			t.Errorf("missing archive entry %s", name)
+		}
+	}
+	if entries["ws/report/d1.blob"] != "content of file:content" || entries["ws/report/d2.blob"] != "content of files:files/0/file" {
+		t.Errorf("unexpected blob entries: %q, %q", entries["ws/report/d1.blob"], entries["ws/report/d2.blob"])
+	}
+	for _, want := range []string{`<schema name="dublincore" xmlns:dc="http://www.nuxeo.org/ecm/schemas/dublincore/">`, "<dc:title>Report</dc:title>", "<data>d1.blob</data>", "<lifecyclePolicy>default</lifecyclePolicy>"} {
+		if !strings.Contains(entries["ws/report/document.xml"], want) {
+			t.Errorf("document.xml misses %q:\n%s", want, entries["ws/report/document.xml"])
+		}
+	}
+
+	doc, err := parseIODocument(strings.NewReader(entries["ws/sub/document.xml"]))
+	if err != nil {
+		t.Fatalf("failed to parse exported document.xml: %v", err)
+	}
+	if doc.Type != DocumentTypeFolder || len(doc.ACLs) != 1 || doc.ACLs[0].ACEs[0].Username != "jdoe" || !doc.ACLs[0].ACEs[0].Granted {
+		t.Errorf("unexpected parsed document: %+v", doc)
+	}
+}
+
+func TestRepository_ImportIOArchive(t *testing.T) {
+	t.Parallel()
+	var archive bytes.Buffer
+	exporter := newTestRepository(treeTestResponder(t, &atomic.Int32{}))
+	if _, err := exporter.ExportIOArchive(context.Background(), "/ws", &archive); err != nil {
+		t.Fatalf("ExportIOArchive() error = %v", err)
+	}
+
+	var mu sync.Mutex
+	created := map[string]map[string]any{}
+	permissions := 0
+	repo := newTestRepository(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case req.Method == http.MethodGet && req.URL.EscapedPath() == "/api/v1/repo/default/path/target":
+			return testJsonResponse(t, 200, map[string]any{"entity-type": "document", "uid": "target", "path": "/target"})
+		case req.URL.EscapedPath() == "/api/v1/upload/new/default":
+			return testJsonResponse(t, 200, map[string]any{"batchId": "batch1"})
+		case req.Method == http.MethodDelete:
+			return testJsonResponse(t, 200, map[string]any{})
+		case strings.HasPrefix(req.URL.EscapedPath(), "/api/v1/upload/batch1/"):
+			io.Copy(io.Discard, req.Body)
+			return testJsonResponse(t, 200, map[string]any{"batchId": "batch1", "fileIdx": path.Base(req.URL.EscapedPath())})
+		case strings.HasSuffix(req.URL.EscapedPath(), "/"+OperationDocumentAddPermission):
+			permissions++
+			return testNoContentResponse()
+		case req.Method == http.MethodPost && strings.HasPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/path/"):
+			var body map[string]any
+			json.NewDecoder(req.Body).Decode(&body)
+			docPath := strings.TrimPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/path") + "/" + body["name"].(string)
+			created[docPath] = body
+			return testJsonResponse(t, 200, map[string]any{"entity-type": "document", "uid": body["name"], "path": docPath})
+		}
+		return nil, errors.New("unexpected request " + req.Method + " " + req.URL.EscapedPath())
+	})
+
+	report, err := repo.ImportIOArchive(context.Background(), bytes.NewReader(archive.Bytes()), int64(archive.Len()), "/target")
+	if err != nil {
+		t.Fatalf("ImportIOArchive() error = %v", err)
+	}
+	if err := report.Err(); err != nil {
+		t.Fatalf("unexpected failures: %v", err)
+	}
+	wantCreated := []string{"ws", "ws/report", "ws/sub", "ws/sub/note"}
+	if len(report.Created) != len(wantCreated) {
+		t.Fatalf("created %d documents, want %d", len(report.Created), len(wantCreated))
+	}
+	for i, entry := range report.Created {
+		if entry.LocalPath != wantCreated[i] || entry.DocumentPath != "/target/"+wantCreated[i] {
+			t.Errorf("unexpected created entry: %+v", entry)
+		}
+	}
+	if permissions != 2 {
+		t.Errorf("applied %d permissions, want 2", permissions)
+	}
+
+	reportDoc := created["/target/ws/report"]
+	if reportDoc["type"] != DocumentTypeFile {
+		t.Errorf("unexpected type: %v", reportDoc["type"])
+	}
+	properties := reportDoc["properties"].(map[string]any)
+	if properties["dc:title"] != "Report" {
+		t.Errorf("unexpected title: %v", properties["dc:title"])
+	}
+	if content := properties["file:content"].(map[string]any); content["upload-batch"] != "batch1" {
+		t.Errorf("unexpected file:content: %v", content)
+	}
+	files := properties["files:files"].([]any)
+	if file := files[0].(map[string]any)["file"].(map[string]any); file["upload-batch"] != "batch1" {
+		t.Errorf("unexpected files:files: %v", files)
+	}
+}
+
+func TestParseIODocument(t *testing.T) {
+	t.Parallel()
+	documentXml := `<?xml version="1.0" encoding="UTF-8"?>
+<document repository="default" id="1234">
+  <system>
+    <type>File</type>
+    <path>ws/doc</path>
+    <lifecycleState>project</lifecycleState>
+    <lifecyclePolicy>default</lifecyclePolicy>
+    <facet>Versionable</facet>
+    <access-control>
+      <acl name="local">
+        <entry principal="Everyone" permission="Everything" grant="false"/>
+      </acl>
+    </access-control>
+  </system>
+  <schema name="dublincore" xmlns:dc="http://www.nuxeo.org/ecm/schemas/dublincore/">
+    <dc:title><![CDATA[My doc]]></dc:title>
+    <dc:subjects><item>art</item><item>music</item></dc:subjects>
+    <dc:description></dc:description>
+  </schema>
+  <schema name="common">
+    <size>42</size>
+  </schema>
+</document>`
+
+	doc, err := parseIODocument(strings.NewReader(documentXml))
+	if err != nil {
+		t.Fatalf("parseIODocument() error = %v", err)
+	}
+	if doc.Type != "File" || doc.State != "project" || doc.LifecyclePolicy != "default" || len(doc.Facets) != 1 {
+		t.Errorf("unexpected system properties: %+v", doc)
+	}
+	if len(doc.ACLs) != 1 || doc.ACLs[0].ACEs[0].Granted {
+		t.Errorf("unexpected ACLs: %+v", doc.ACLs)
+	}
+	want := map[string]any{
+		"dc:title":       "My doc",
+		"dc:subjects":    []any{"art", "music"},
+		"dc:description": nil,
+		"common:size":    "42",
+	}
+	gotJson, _ := json.Marshal(doc.Properties)
+	wantJson, _ := json.Marshal(want)
+	if string(gotJson) != string(wantJson) {
+		t.Errorf("properties: got %s, want %s", gotJson, wantJson)
+	}
+}
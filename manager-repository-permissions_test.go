@@ -0,0 +1,267 @@
+package nuxeo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// permissionsTestServer serves the ACLs of the document "doc", at /ws/doc, and applies the permission operations,
+// recording the parameters of the last one. Granting a permission to failUsername fails.
+type permissionsTestServer struct {
+	t            *testing.T
+	mu           sync.Mutex
+	acls         []ACL
+	lastParams   map[string]string
+	failUsername string
+}
+
+func (srv *permissionsTestServer) respond(req *http.Request) (*http.Response, error) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	aclIndex := func(name string) int {
+		i := slices.IndexFunc(srv.acls, func(acl ACL) bool { return acl.Name == name })
+		if i < 0 {
+			srv.acls = append(srv.acls, ACL{Name: name})
+			i = len(srv.acls) - 1
+		}
+		return i
+	}
+
+	switch req.URL.EscapedPath() {
+	case "/api/v1/repo/default/path/ws/doc/@acl", "/api/v1/repo/default/id/doc/@acl":
+		return testJsonResponse(srv.t, 200, map[string]any{"entity-type": "acls", "acl": srv.acls})
+	}
+	operationId, found := strings.CutPrefix(req.URL.EscapedPath(), "/site/automation/")
+	if !found {
+		return nil, errors.New("unexpected request " + req.URL.String())
+	}
+	var payload operationPayload
+	if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+		return nil, err
+	}
+	srv.lastParams = payload.Params
+	params := payload.Params
+	switch operationId {
+	case OperationDocumentAddPermission:
+		if params["username"] == srv.failUsername {
+			return testJsonResponse(srv.t, 500, map[string]any{"entity-type": "exception", "status": 500, "message": "boom"})
+		}
+		i := aclIndex(params["acl"])
+		id := params["username"] + ":" + params["permission"] + ":true:Administrator:" + params["begin"] + ":" + params["end"]
+		srv.acls[i].ACEs = append(srv.acls[i].ACEs, ACE{ID: id, Username: params["username"], Permission: params["permission"], Granted: true})
+	case OperationDocumentRemovePermission:
+		i := aclIndex(params["acl"])
+		srv.acls[i].ACEs = slices.DeleteFunc(srv.acls[i].ACEs, func(ace ACE) bool { return ace.ID == params["id"] })
+	case OperationDocumentRemoveACL:
+		srv.acls = slices.DeleteFunc(srv.acls, func(acl ACL) bool { return acl.Name == params["acl"] })
+	case OperationDocumentBlockInheritance:
+		i := aclIndex(params["acl"])
+		srv.acls[i].ACEs = append(srv.acls[i].ACEs,
+			ACE{ID: "Administrator:Everything:true", Username: "Administrator", Permission: PermissionEverything, Granted: true},
+			ACE{ID: "Everyone:Everything:false", Username: "Everyone", Permission: PermissionEverything})
+	case OperationDocumentUnblockInheritance:
+		i := aclIndex(params["acl"])
+		srv.acls[i].ACEs = slices.DeleteFunc(srv.acls[i].ACEs, func(ace ACE) bool { return ace.ID == "Everyone:Everything:false" })
+	default:
+		return nil, errors.New("unexpected operation " + operationId)
+	}
+	return &http.Response{StatusCode: 204, Body: http.NoBody}, nil
+}
+
+// aceIds returns the IDs of the ACEs of the ACL of the ACP.
+func aceIds(acp *ACP, aclName string) string {
+	ids := []string{}
+	for _, acl := range acp.ACLs {
+		if acl.Name == aclName {
+			for _, ace := range acl.ACEs {
+				ids = append(ids, ace.ID)
+			}
+		}
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestRepository_GrantPermission(t *testing.T) {
+	t.Parallel()
+	srv := &permissionsTestServer{t: t}
+	repo := newTestRepository(srv.respond)
+	ctx := context.Background()
+
+	acp, err := repo.GrantPermission(ctx, "/ws/doc", *NewACE("jdoe", PermissionRead, true), nil)
+	if err != nil {
+		t.Fatalf("GrantPermission() error = %v", err)
+	}
+	if got := aceIds(acp, AclLocal); got != "jdoe:Read:true:Administrator::" {
+		t.Errorf("GrantPermission() local ACL = %s", got)
+	}
+
+	end := ISO8601Time(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	ace := NewACE("members", PermissionReadWrite, true)
+	ace.End = &end
+	acp, err = repo.GrantPermission(ctx, "doc", *ace, &GrantPermissionOptions{ACL: "project", Notify: true, Comment: "welcome"})
+	if err != nil {
+		t.Fatalf("GrantPermission() error = %v", err)
+	}
+	if got := aceIds(acp, "project"); got != "members:ReadWrite:true:Administrator::2030-01-01T00:00:00Z" {
+		t.Errorf("GrantPermission() project ACL = %s", got)
+	}
+	if srv.lastParams["notify"] != "true" || srv.lastParams["comment"] != "welcome" {
+		t.Errorf("GrantPermission() params = %v", srv.lastParams)
+	}
+
+	if _, err := repo.GrantPermission(ctx, "/ws/doc", *NewACE("bob", PermissionWrite, false), nil); err == nil {
+		t.Error("GrantPermission() of a denied ACE succeeded")
+	}
+	if srv.lastParams["username"] == "bob" {
+		t.Errorf("GrantPermission() of a denied ACE granted it: %v", srv.lastParams)
+	}
+
+	acp, err = repo.RevokePermission(ctx, "/ws/doc", "members:ReadWrite:true:Administrator::2030-01-01T00:00:00Z")
+	if err != nil {
+		t.Fatalf("RevokePermission() error = %v", err)
+	}
+	if srv.lastParams["acl"] != "project" || aceIds(acp, "project") != "" || aceIds(acp, AclLocal) == "" {
+		t.Errorf("RevokePermission() = %+v, params %v", acp, srv.lastParams)
+	}
+}
+
+func TestRepository_Inheritance(t *testing.T) {
+	t.Parallel()
+	srv := &permissionsTestServer{t: t}
+	repo := newTestRepository(srv.respond)
+	ctx := context.Background()
+
+	acp, err := repo.BlockInheritance(ctx, "/ws/doc")
+	if err != nil {
+		t.Fatalf("BlockInheritance() error = %v", err)
+	}
+	if got := aceIds(acp, AclLocal); got != "Administrator:Everything:true,Everyone:Everything:false" {
+		t.Errorf("BlockInheritance() local ACL = %s", got)
+	}
+	acp, err = repo.UnblockInheritance(ctx, "/ws/doc")
+	if err != nil {
+		t.Fatalf("UnblockInheritance() error = %v", err)
+	}
+	if got := aceIds(acp, AclLocal); got != "Administrator:Everything:true" {
+		t.Errorf("UnblockInheritance() local ACL = %s", got)
+	}
+}
+
+func TestRepository_ReplaceACL(t *testing.T) {
+	t.Parallel()
+	srv := &permissionsTestServer{t: t, acls: []ACL{
+		{Name: AclLocal, ACEs: []ACE{{ID: "jdoe:Read:true:Administrator::", Username: "jdoe", Permission: PermissionRead, Granted: true}}},
+		{Name: AclInherit, ACEs: []ACE{{ID: "members:Read:true", Username: "members", Permission: PermissionRead, Granted: true}}},
+	}}
+	repo := newTestRepository(srv.respond)
+	ctx := context.Background()
+
+	if _, err := repo.ReplaceACL(ctx, "/ws/doc", AclLocal, []ACE{*NewACE("alice", PermissionWrite, true), *NewACE("bob", PermissionRead, false)}); err == nil {
+		t.Error("ReplaceACL() with a denied ACE succeeded")
+	}
+	if _, err := repo.ReplaceACL(ctx, "/ws/doc", "", []ACE{*NewACE("alice", PermissionWrite, true)}); err == nil {
+		t.Error("ReplaceACL() without ACL name succeeded")
+	}
+	if srv.lastParams != nil {
+		t.Errorf("ReplaceACL() changed the ACL on error: %v", srv.lastParams)
+	}
+
+	acp, err := repo.ReplaceACL(ctx, "/ws/doc", AclLocal, []ACE{
+		*NewACE("alice", PermissionWrite, true),
+		*NewACE("carol", PermissionEverything, true),
+	})
+	if err != nil {
+		t.Fatalf("ReplaceACL() error = %v", err)
+	}
+	if got := aceIds(acp, AclLocal); got != "alice:Write:true:Administrator::,carol:Everything:true:Administrator::" {
+		t.Errorf("ReplaceACL() local ACL = %s", got)
+	}
+	if got := aceIds(acp, AclInherit); got != "members:Read:true" {
+		t.Errorf("ReplaceACL() inherited ACL = %s", got)
+	}
+
+	acp, err = repo.ReplaceACL(ctx, "/ws/doc", AclLocal, nil)
+	if err != nil || len(acp.ACLs) != 1 {
+		t.Errorf("ReplaceACL() with no ACE = %+v, %v", acp, err)
+	}
+}
+
+func TestRepository_ReplaceACL_PartialFailure(t *testing.T) {
+	t.Parallel()
+	srv := &permissionsTestServer{t: t, failUsername: "carol", acls: []ACL{
+		{Name: AclLocal, ACEs: []ACE{{ID: "jdoe:Read:true:Administrator::", Username: "jdoe", Permission: PermissionRead, Granted: true}}},
+	}}
+	repo := newTestRepository(srv.respond)
+
+	_, err := repo.ReplaceACL(context.Background(), "/ws/doc", AclLocal, []ACE{
+		*NewACE("alice", PermissionWrite, true),
+		*NewACE("carol", PermissionEverything, true),
+		*NewACE("dave", PermissionRead, true),
+	})
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 entries") {
+		t.Fatalf("ReplaceACL() error = %v, want partial replacement", err)
+	}
+	acp, err := repo.fetchPermissions(context.Background(), "/ws/doc")
+	if err != nil {
+		t.Fatalf("fetchPermissions() error = %v", err)
+	}
+	if got := aceIds(acp, AclLocal); got != "alice:Write:true:Administrator::" {
+		t.Errorf("ReplaceACL() left local ACL = %s, want alice only", got)
+	}
+}
+
+func TestRepository_CheckPermission(t *testing.T) {
+	t.Parallel()
+	jdoe := NewUser("jdoe")
+	jdoe.Properties[UserPropertyGroups] = NewStringListField([]string{"editors"})
+	admin := NewUser("Administrator")
+	admin.IsAdministrator = true
+	principals := principalsTestResponder(t, map[string]*User{"jdoe": jdoe, "bob": NewUser("bob"), "Administrator": admin},
+		map[string][]string{"editors": {"staff"}, "staff": {}})
+	srv := &permissionsTestServer{t: t, acls: []ACL{
+		{Name: AclLocal, ACEs: []ACE{{ID: "staff:ReadWrite", Username: "staff", Permission: PermissionReadWrite, Granted: true}}},
+		{Name: AclInherit, ACEs: []ACE{{ID: "Everyone:Read", Username: GroupEveryone, Permission: PermissionRead, Granted: true}}},
+	}}
+	repo := newTestRepository(func(req *http.Request) (*http.Response, error) {
+		if strings.HasPrefix(req.URL.EscapedPath(), "/api/v1/user/") || strings.HasPrefix(req.URL.EscapedPath(), "/api/v1/group/") {
+			return principals(req)
+		}
+		return srv.respond(req)
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		username   string
+		permission string
+		wantGrant  bool
+		wantACE    string
+	}{
+		{username: "jdoe", permission: PermissionWrite, wantGrant: true, wantACE: "staff:ReadWrite"},
+		{username: "bob", permission: PermissionRead, wantGrant: true, wantACE: "Everyone:Read"},
+		{username: "bob", permission: PermissionWrite},
+		{username: "Administrator", permission: PermissionEverything, wantGrant: true},
+	}
+	for _, tt := range tests {
+		decision, err := repo.CheckPermission(ctx, "/ws/doc", tt.username, tt.permission)
+		if err != nil {
+			t.Fatalf("CheckPermission(%s, %s) error = %v", tt.username, tt.permission, err)
+		}
+		aceId := ""
+		if decision.ACE != nil {
+			aceId = decision.ACE.ID
+		}
+		if decision.Granted != tt.wantGrant || aceId != tt.wantACE {
+			t.Errorf("CheckPermission(%s, %s) = %+v, want ACE %q granting %t", tt.username, tt.permission, decision, tt.wantACE, tt.wantGrant)
+		}
+	}
+	if _, err := repo.CheckPermission(ctx, "/ws/doc", "unknown", PermissionRead); !hasErrorStatus(err, http.StatusNotFound) {
+		t.Errorf("CheckPermission() of an unknown user error = %v, want not found", err)
+	}
+}
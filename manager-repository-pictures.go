@@ -0,0 +1,65 @@
+package nuxeo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/anselm94/nuxeo-go-client/internal"
+)
+
+//////////////////
+//// PICTURES ////
+//////////////////
+
+// ErrPictureViewNotFound is returned when a Picture document has no view with the requested title.
+var ErrPictureViewNotFound = errors.New("picture view not found")
+
+// FetchPictureViews retrieves the views of a Picture document (a repository path or a document ID).
+// Returns the PictureViews or error.
+func (r *repository) FetchPictureViews(ctx context.Context, documentRef string) ([]PictureView, error) {
+	options := NewNuxeoRequestOptions().SetRepositoryName(r.name).SetSchemas([]string{DocumentSchemaPicture})
+	doc, err := r.fetchDocument(ctx, documentRef, options)
+	if err != nil {
+		return nil, err
+	}
+	return doc.PictureViews(), nil
+}
+
+// FetchPictureView streams the view with the given title (e.g. "Thumbnail", "Medium", "FullHD") of a Picture document
+// (a repository path or a document ID).
+// Maps to GET /api/v1/repo/{repo}/id/{id}/@blob/picture:views/{index}/content
+// Returns Blob (stream, filename, mimetype, length), ErrPictureViewNotFound or error.
+func (r *repository) FetchPictureView(ctx context.Context, documentRef string, title string, options *nuxeoRequestOptions) (*blob, error) {
+	views, err := r.FetchPictureViews(ctx, documentRef)
+	if err != nil {
+		return nil, err
+	}
+	index := -1
+	for i, view := range views {
+		if view.Title == title {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("%w: %s of %s", ErrPictureViewNotFound, title, documentRef)
+	}
+
+	xpath := DocumentPropertyPictureViews + "/" + strconv.Itoa(index) + "/content"
+	path := r.documentRefPath(documentRef) + "/@blob/" + escapePathSegments(xpath)
+	res, err := r.client.NewRequest(ctx, options).SetDoNotParseResponse(true).SetError(&NuxeoError{}).Get(path)
+
+	if err := handleNuxeoStreamError(err, res); err != nil {
+		r.logger.Error("Failed to fetch picture view", slog.String("error", err.Error()), slog.String("title", title))
+		return nil, err
+	}
+	return &blob{
+		ReadCloser: res.Body,
+		Filename:   internal.GetStreamFilenameFrom(res),
+		MimeType:   internal.GetStreamContentTypeFrom(res),
+		Length:     strconv.Itoa(internal.GetStreamContentLengthFrom(res)),
+	}, nil
+}
@@ -0,0 +1,58 @@
+package nuxeo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/anselm94/nuxeo-go-client/internal"
+)
+
+func TestRepository_FetchPictureView(t *testing.T) {
+	t.Parallel()
+	repo := newTestRepository(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.EscapedPath() {
+		case "/api/v1/repo/default/path/ws/pic":
+			if req.Header.Get(internal.HeaderProperties) != DocumentSchemaPicture {
+				t.Errorf("picture fetched with schemas %q", req.Header.Get(internal.HeaderProperties))
+			}
+			return &http.Response{
+				StatusCode: 200,
+				Body: testMarshalBody(t, map[string]any{"entity-type": "document", "uid": "pic", "properties": map[string]any{
+					DocumentPropertyPictureViews: []any{
+						map[string]any{"title": PictureViewThumbnail, "width": 350, "height": 263},
+						map[string]any{"title": PictureViewMedium, "width": 1200, "height": 900},
+					},
+				}}),
+				Header: http.Header{"Content-Type": []string{"application/json"}},
+			}, nil
+		case "/api/v1/repo/default/path/ws/pic/@blob/picture:views/1/content":
+			return &http.Response{
+				StatusCode: 200,
+				Body:       io.NopCloser(strings.NewReader("medium")),
+				Header: http.Header{
+					"Content-Type":        []string{"image/jpeg"},
+					"Content-Disposition": []string{`attachment; filename="Medium_pic.jpg"`},
+				},
+			}, nil
+		}
+		return nil, errors.New("unexpected request " + req.URL.String())
+	})
+
+	view, err := repo.FetchPictureView(context.Background(), "/ws/pic", PictureViewMedium, nil)
+	if err != nil {
+		t.Fatalf("FetchPictureView() error = %v", err)
+	}
+	defer view.Close()
+	content, _ := io.ReadAll(view)
+	if string(content) != "medium" || view.Filename != "Medium_pic.jpg" || view.MimeType != "image/jpeg" {
+		t.Errorf("FetchPictureView() = %s %s %q", view.Filename, view.MimeType, content)
+	}
+
+	if _, err := repo.FetchPictureView(context.Background(), "/ws/pic", PictureViewFullHD, nil); !errors.Is(err, ErrPictureViewNotFound) {
+		t.Errorf("FetchPictureView() of a missing view error = %v, want ErrPictureViewNotFound", err)
+	}
+}
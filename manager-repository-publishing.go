@@ -0,0 +1,88 @@
+package nuxeo
+
+import (
+	"context"
+	"fmt"
+)
+
+////////////////////
+//// PUBLISHING ////
+////////////////////
+
+// publicationsQuery lists the proxies of a live document and of its versions.
+const publicationsQuery = "SELECT * FROM Document WHERE ecm:isProxy = 1 AND ecm:proxyVersionableId = ? ORDER BY ecm:path"
+
+// Publish publishes a document (a repository path or a document ID) into a section, creating a proxy of the document in
+// the section. With overwrite, a previous publication of the document in the section is replaced.
+// Uses the Document.PublishToSection operation.
+// Returns the proxy Document or error.
+func (r *repository) Publish(ctx context.Context, documentRef string, sectionRef string, overwrite bool) (*Document, error) {
+	operation := NewOperation(OperationDocumentPublishToSection).
+		SetParam("target", sectionRef).
+		SetParam("override", overwrite)
+	return r.executeForDocument(ctx, documentRef, operation, nil)
+}
+
+// Unpublish removes the publications of a document (a repository path or a document ID) from a section, by deleting
+// its proxies in the section. An empty sectionRef removes the publications from all sections.
+// Returns the number of deleted proxies, and the first error met.
+func (r *repository) Unpublish(ctx context.Context, documentRef string, sectionRef string) (int, error) {
+	sectionId := ""
+	if sectionRef != "" {
+		section, err := r.fetchDocument(ctx, sectionRef, nil)
+		if err != nil {
+			return 0, err
+		}
+		sectionId = section.ID
+	}
+	publications, err := r.ListPublications(ctx, documentRef)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, proxy := range publications.Entries {
+		if sectionId != "" && proxy.ParentRef != sectionId {
+			continue
+		}
+		if err := r.DeleteDocument(ctx, proxy.ID); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// ListPublications retrieves the proxies publishing a document (a repository path or a document ID) or any of its
+// versions, in all sections.
+// Returns the proxy Documents, whose ParentRef is the section, or error.
+func (r *repository) ListPublications(ctx context.Context, documentRef string) (*Documents, error) {
+	doc, err := r.fetchDocument(ctx, documentRef, nil)
+	if err != nil {
+		return nil, err
+	}
+	versionableId := doc.ID
+	if doc.IsVersion || doc.IsProxy {
+		versionableId = doc.VersionableId
+	}
+	publications := &Documents{}
+	for proxy, err := range r.QueryAll(ctx, publicationsQuery, []string{versionableId}, 0, NewNuxeoRequestOptions().SetRepositoryName(r.name)) {
+		if err != nil {
+			return nil, err
+		}
+		publications.Entries = append(publications.Entries, *proxy)
+	}
+	return publications, nil
+}
+
+// ResolveProxy retrieves the document targeted by a proxy, i.e. the published live document or version.
+// A document which is not a proxy is returned as is.
+// Returns the target Document or error.
+func (r *repository) ResolveProxy(ctx context.Context, proxy *Document) (*Document, error) {
+	if !proxy.IsProxy {
+		return proxy, nil
+	}
+	if proxy.ProxyTargetId == "" {
+		return nil, fmt.Errorf("proxy %s has no target", proxy.ID)
+	}
+	return r.FetchDocumentById(ctx, proxy.ProxyTargetId, nil)
+}
@@ -0,0 +1,149 @@
+package nuxeo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// publishingTestServer serves the live document "doc" at /ws/doc, its version "v1", and the sections "s1" and "s2" at
+// /sections/{id}. Publishing creates a proxy "{section}-{document}" of the published document.
+type publishingTestServer struct {
+	t       *testing.T
+	mu      sync.Mutex
+	proxies map[string]map[string]any
+	deleted []string
+}
+
+func (srv *publishingTestServer) respond(req *http.Request) (*http.Response, error) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	docs := map[string]map[string]any{
+		"doc": {"entity-type": "document", "uid": "doc", "path": "/ws/doc"},
+		"v1":  {"entity-type": "document", "uid": "v1", "isVersion": true, "versionableId": "doc", "versionLabel": "1.0"},
+		"s1":  {"entity-type": "document", "uid": "s1", "path": "/sections/s1"},
+		"s2":  {"entity-type": "document", "uid": "s2", "path": "/sections/s2"},
+	}
+	maps.Copy(docs, srv.proxies)
+
+	switch {
+	case strings.HasPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/path/"):
+		id := req.URL.EscapedPath()[strings.LastIndex(req.URL.EscapedPath(), "/")+1:]
+		return testJsonResponse(srv.t, 200, docs[id])
+	case strings.HasPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/id/"):
+		id := strings.TrimPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/id/")
+		if _, found := docs[id]; !found {
+			return testNotFoundResponse(srv.t)
+		}
+		if req.Method == http.MethodDelete {
+			srv.deleted = append(srv.deleted, id)
+			delete(srv.proxies, id)
+			return testNoContentResponse()
+		}
+		return testJsonResponse(srv.t, 200, docs[id])
+	case req.URL.EscapedPath() == "/api/v1/query":
+		if req.Header.Get("X-NXRepository") != "default" {
+			srv.t.Errorf("query sent to repository %q", req.Header.Get("X-NXRepository"))
+		}
+		if req.URL.Query().Get("queryParams") != "doc" {
+			srv.t.Errorf("publications queried for %s, want doc", req.URL.Query().Get("queryParams"))
+		}
+		entries := []any{}
+		for _, id := range slices.Sorted(maps.Keys(srv.proxies)) {
+			entries = append(entries, srv.proxies[id])
+		}
+		return testJsonResponse(srv.t, 200, map[string]any{"entity-type": "documents", "entries": entries})
+	case strings.HasSuffix(req.URL.EscapedPath(), "/"+OperationDocumentPublishToSection):
+		var payload operationPayload
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			return nil, err
+		}
+		target := docs[payload.Input[strings.LastIndexAny(payload.Input, ":/")+1:]]
+		section := payload.Params["target"][strings.LastIndex(payload.Params["target"], "/")+1:]
+		id := section + "-" + target["uid"].(string)
+		if _, found := srv.proxies[id]; found && payload.Params["override"] != "true" {
+			return testJsonResponse(srv.t, 409, map[string]any{"entity-type": "exception", "status": 409, "message": "already published"})
+		}
+		srv.proxies[id] = map[string]any{"entity-type": "document", "uid": id, "parentRef": section, "isProxy": true, "proxyTargetId": target["uid"], "versionableId": "doc"}
+		return testJsonResponse(srv.t, 200, srv.proxies[id])
+	}
+	return nil, errors.New("unexpected request " + req.URL.String())
+}
+
+func TestRepository_Publish(t *testing.T) {
+	t.Parallel()
+	srv := &publishingTestServer{t: t, proxies: map[string]map[string]any{}}
+	repo := newTestRepository(srv.respond)
+
+	proxy, err := repo.Publish(context.Background(), "/ws/doc", "/sections/s1", false)
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if !proxy.IsProxy || proxy.ParentRef != "s1" {
+		t.Errorf("Publish() = %+v, want a proxy in s1", proxy)
+	}
+	if _, err := repo.Publish(context.Background(), "/ws/doc", "/sections/s1", false); err == nil {
+		t.Errorf("Publish() twice without overwrite succeeded")
+	}
+	if _, err := repo.Publish(context.Background(), "/ws/doc", "/sections/s1", true); err != nil {
+		t.Errorf("Publish() with overwrite error = %v", err)
+	}
+	if _, err := repo.Publish(context.Background(), "v1", "s2", false); err != nil {
+		t.Fatalf("Publish() version error = %v", err)
+	}
+
+	var publications *Documents
+	for _, ref := range []string{"/ws/doc", "v1", "s2-v1"} {
+		publications, err = repo.ListPublications(context.Background(), ref)
+		if err != nil {
+			t.Fatalf("ListPublications(%s) error = %v", ref, err)
+		}
+		if len(publications.Entries) != 2 || publications.Entries[0].ID != "s1-doc" || publications.Entries[1].ID != "s2-v1" {
+			t.Errorf("ListPublications(%s) = %+v", ref, publications.Entries)
+		}
+	}
+
+	target, err := repo.ResolveProxy(context.Background(), &publications.Entries[1])
+	if err != nil || target.ID != "v1" || !target.IsVersion {
+		t.Errorf("ResolveProxy() = %+v, %v, want v1", target, err)
+	}
+	live := &Document{ID: "doc"}
+	if target, err := repo.ResolveProxy(context.Background(), live); err != nil || target != live {
+		t.Errorf("ResolveProxy() of a live document = %+v, %v", target, err)
+	}
+}
+
+func TestRepository_Unpublish(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		sectionRef  string
+		wantDeleted string
+	}{
+		{name: "from section", sectionRef: "/sections/s2", wantDeleted: "s2-v1"},
+		{name: "from all sections", wantDeleted: "s1-doc,s2-v1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			srv := &publishingTestServer{t: t, proxies: map[string]map[string]any{
+				"s1-doc": {"entity-type": "document", "uid": "s1-doc", "parentRef": "s1", "isProxy": true, "proxyTargetId": "doc"},
+				"s2-v1":  {"entity-type": "document", "uid": "s2-v1", "parentRef": "s2", "isProxy": true, "proxyTargetId": "v1"},
+			}}
+			repo := newTestRepository(srv.respond)
+			deleted, err := repo.Unpublish(context.Background(), "doc", tt.sectionRef)
+			if err != nil {
+				t.Fatalf("Unpublish() error = %v", err)
+			}
+			if got := strings.Join(srv.deleted, ","); got != tt.wantDeleted || deleted != len(srv.deleted) {
+				t.Errorf("Unpublish() deleted %d: %s, want %s", deleted, got, tt.wantDeleted)
+			}
+		})
+	}
+}
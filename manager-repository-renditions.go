@@ -0,0 +1,179 @@
+package nuxeo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/anselm94/nuxeo-go-client/internal"
+)
+
+////////////////////
+//// RENDITIONS ////
+////////////////////
+
+// DefaultConversionPollInterval is the default interval between two polls of the status of an asynchronous conversion.
+const DefaultConversionPollInterval = time.Second
+
+// RenditionDefinition describes a rendition available for a document, as listed by the "renditions" enricher.
+type RenditionDefinition struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	Icon string `json:"icon"`
+	URL  string `json:"url"`
+}
+
+// ConvertOptions selects the conversion to run: exactly one of Format, MimeType or Converter must be set.
+type ConvertOptions struct {
+	// Format is the target file extension, e.g. "pdf"
+	Format string
+	// MimeType is the target MIME type, e.g. "application/pdf"
+	MimeType string
+	// Converter is the name of the converter to run, e.g. "any2pdf"
+	Converter string
+	// Async runs the conversion on the server in the background, polling its status until the result is ready
+	Async bool
+	// PollInterval is the interval between two polls of an asynchronous conversion; defaults to DefaultConversionPollInterval
+	PollInterval time.Duration
+}
+
+// queryParams returns the conversion target as URL query parameters.
+func (o *ConvertOptions) queryParams() (url.Values, error) {
+	params := url.Values{}
+	if o.Format != "" {
+		params.Set("format", o.Format)
+	}
+	if o.MimeType != "" {
+		params.Set("type", o.MimeType)
+	}
+	if o.Converter != "" {
+		params.Set("converter", o.Converter)
+	}
+	if len(params) != 1 {
+		return nil, errors.New("exactly one of the conversion format, MIME type or converter must be set")
+	}
+	return params, nil
+}
+
+// conversionStatus is the status of an asynchronous conversion, as returned when scheduling and polling it.
+type conversionStatus struct {
+	ConversionId string `json:"conversionId"`
+	Status       string `json:"status"`
+}
+
+// FetchRendition streams a rendition (e.g. "pdf", "thumbnail") of a document (a repository path or a document ID).
+// Maps to GET /api/v1/repo/{repo}/id/{id}/@rendition/{name}
+// Returns Blob (stream, filename, mimetype, length) or error.
+func (r *repository) FetchRendition(ctx context.Context, documentRef string, renditionName string, options *nuxeoRequestOptions) (*blob, error) {
+	path := r.documentRefPath(documentRef) + "/@rendition/" + url.PathEscape(renditionName)
+	res, err := r.client.NewRequest(ctx, options).SetDoNotParseResponse(true).SetError(&NuxeoError{}).Get(path)
+
+	if err := handleNuxeoStreamError(err, res); err != nil {
+		r.logger.Error("Failed to fetch rendition", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return &blob{
+		ReadCloser: res.Body,
+		Filename:   internal.GetStreamFilenameFrom(res),
+		MimeType:   internal.GetStreamContentTypeFrom(res),
+		Length:     strconv.Itoa(internal.GetStreamContentLengthFrom(res)),
+	}, nil
+}
+
+// ListRenditions retrieves the renditions available for a document (a repository path or a document ID).
+// Uses the "renditions" enricher.
+// Returns the RenditionDefinitions or error.
+func (r *repository) ListRenditions(ctx context.Context, documentRef string) ([]RenditionDefinition, error) {
+	options := NewNuxeoRequestOptions().SetRepositoryName(r.name).SetEnricherForDocument([]string{EnricherDocumentRenditions})
+	doc, err := r.fetchDocument(ctx, documentRef, options)
+	if err != nil {
+		return nil, err
+	}
+	renditions := []RenditionDefinition{}
+	if field, found := doc.ContextParameter(EnricherDocumentRenditions); found && !field.IsNull() {
+		if err := field.ComplexList(&renditions); err != nil {
+			return nil, fmt.Errorf("failed to decode renditions of %s: %w", documentRef, err)
+		}
+	}
+	return renditions, nil
+}
+
+// Convert converts a blob of a document (a repository path or a document ID) to another format, and streams the result.
+// An empty xpath converts the main blob of the document.
+// Maps to GET /api/v1/repo/{repo}/id/{id}/@blob/{xpath}/@convert, or with options.Async to
+// POST /api/v1/repo/{repo}/id/{id}/@blob/{xpath}/@convert, polling GET /api/v1/conversions/{conversionId}/poll until the
+// result is ready at GET /api/v1/conversions/{conversionId}/result.
+// Returns Blob (stream, filename, mimetype, length) or error.
+func (r *repository) Convert(ctx context.Context, documentRef string, xpath string, options *ConvertOptions) (*blob, error) {
+	if options == nil {
+		options = &ConvertOptions{}
+	}
+	params, err := options.queryParams()
+	if err != nil {
+		return nil, err
+	}
+	path := r.documentRefPath(documentRef)
+	if xpath != "" {
+		path += "/@blob/" + escapePathSegments(xpath)
+	}
+	path += "/@convert"
+	if !options.Async {
+		return r.streamConversion(ctx, path+"?"+params.Encode())
+	}
+
+	// the server reads the parameters of an asynchronous conversion from the form body
+	params.Set("async", "true")
+	res, err := r.client.NewRequest(ctx, nil).SetFormDataFromValues(params).SetResult(&conversionStatus{}).SetError(&NuxeoError{}).Post(path)
+	if err := handleNuxeoError(err, res); err != nil {
+		r.logger.Error("Failed to schedule conversion", slog.String("error", err.Error()))
+		return nil, err
+	}
+	conversionId := res.Result().(*conversionStatus).ConversionId
+	if err := r.waitForConversion(ctx, conversionId, options.PollInterval); err != nil {
+		return nil, err
+	}
+	return r.streamConversion(ctx, internal.PathApiV1+"/conversions/"+url.PathEscape(conversionId)+"/result")
+}
+
+// waitForConversion polls the status of an asynchronous conversion until it is completed.
+func (r *repository) waitForConversion(ctx context.Context, conversionId string, pollInterval time.Duration) error {
+	if pollInterval <= 0 {
+		pollInterval = DefaultConversionPollInterval
+	}
+	path := internal.PathApiV1 + "/conversions/" + url.PathEscape(conversionId) + "/poll"
+	for {
+		res, err := r.client.NewRequest(ctx, nil).SetResult(&conversionStatus{}).SetError(&NuxeoError{}).Get(path)
+		if err := handleNuxeoError(err, res); err != nil {
+			r.logger.Error("Failed to poll conversion status", slog.String("error", err.Error()), slog.String("conversionId", conversionId))
+			return err
+		}
+		if res.Result().(*conversionStatus).Status == "completed" {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// streamConversion streams the result of a conversion.
+func (r *repository) streamConversion(ctx context.Context, path string) (*blob, error) {
+	res, err := r.client.NewRequest(ctx, nil).SetDoNotParseResponse(true).SetError(&NuxeoError{}).Get(path)
+
+	if err := handleNuxeoStreamError(err, res); err != nil {
+		r.logger.Error("Failed to fetch conversion result", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return &blob{
+		ReadCloser: res.Body,
+		Filename:   internal.GetStreamFilenameFrom(res),
+		MimeType:   internal.GetStreamContentTypeFrom(res),
+		Length:     strconv.Itoa(internal.GetStreamContentLengthFrom(res)),
+	}, nil
+}
@@ -0,0 +1,142 @@
+package nuxeo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// renditionsTestServer serves the document "doc" at /ws/doc, with a "pdf" rendition, and converts its blobs to the
+// requested format, asynchronously completing after polls polls.
+type renditionsTestServer struct {
+	t     *testing.T
+	mu    sync.Mutex
+	polls int
+}
+
+func (srv *renditionsTestServer) respond(req *http.Request) (*http.Response, error) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	streamResponse := func(filename string, content string) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(content)),
+			Header: http.Header{
+				"Content-Type":        []string{"application/pdf"},
+				"Content-Disposition": []string{`attachment; filename="` + filename + `"`},
+			},
+		}, nil
+	}
+
+	query := req.URL.Query()
+	if req.Method == http.MethodPost {
+		// asynchronous conversions are posted as a form
+		if req.URL.RawQuery != "" {
+			srv.t.Errorf("conversion posted with query %s", req.URL.RawQuery)
+		}
+		if err := req.ParseForm(); err != nil {
+			return nil, err
+		}
+		query = req.PostForm
+	}
+	switch req.URL.EscapedPath() {
+	case "/api/v1/repo/default/path/ws/doc":
+		return testJsonResponse(srv.t, 200, map[string]any{"entity-type": "document", "uid": "doc", "contextParameters": map[string]any{
+			"renditions": []any{
+				map[string]any{"name": "pdf", "kind": "nuxeo:rendition:pdf", "icon": "/icons/pdf.png", "url": "http://localhost/doc/@rendition/pdf"},
+				map[string]any{"name": "thumbnail", "kind": "nuxeo:rendition:thumbnail", "icon": "/icons/image.png", "url": "http://localhost/doc/@rendition/thumbnail"},
+			},
+		}})
+	case "/api/v1/repo/default/path/ws/doc/@rendition/pdf":
+		return streamResponse("doc.pdf", "pdf rendition")
+	case "/api/v1/repo/default/path/ws/doc/@rendition/unknown":
+		return testJsonResponse(srv.t, 404, map[string]any{"entity-type": "exception", "status": 404, "message": "unknown rendition"})
+	case "/api/v1/repo/default/id/doc/@convert", "/api/v1/repo/default/id/doc/@blob/files:files/0/file/@convert":
+		target := query.Get("format") + query.Get("type") + query.Get("converter")
+		if req.Method == http.MethodGet {
+			return streamResponse("doc.pdf", "converted to "+target)
+		}
+		if query.Get("async") != "true" {
+			srv.t.Errorf("conversion posted without async")
+		}
+		return testJsonResponse(srv.t, 202, map[string]any{"entity-type": "conversionScheduled", "conversionId": target})
+	case "/api/v1/conversions/application%2Fpdf/poll":
+		srv.polls--
+		if srv.polls > 0 {
+			return testJsonResponse(srv.t, 200, map[string]any{"entity-type": "conversionStatus", "conversionId": "application/pdf", "status": "running"})
+		}
+		return testJsonResponse(srv.t, 200, map[string]any{"entity-type": "conversionStatus", "conversionId": "application/pdf", "status": "completed"})
+	case "/api/v1/conversions/application%2Fpdf/result":
+		if srv.polls > 0 {
+			srv.t.Errorf("conversion result fetched before completion")
+		}
+		return streamResponse("doc.pdf", "converted asynchronously")
+	}
+	return nil, errors.New("unexpected request " + req.URL.String())
+}
+
+func TestRepository_Renditions(t *testing.T) {
+	t.Parallel()
+	repo := newTestRepository((&renditionsTestServer{t: t}).respond)
+
+	renditions, err := repo.ListRenditions(context.Background(), "/ws/doc")
+	if err != nil {
+		t.Fatalf("ListRenditions() error = %v", err)
+	}
+	if len(renditions) != 2 || renditions[0].Name != "pdf" || renditions[1].Kind != "nuxeo:rendition:thumbnail" {
+		t.Errorf("ListRenditions() = %+v", renditions)
+	}
+
+	rendition, err := repo.FetchRendition(context.Background(), "/ws/doc", "pdf", nil)
+	if err != nil {
+		t.Fatalf("FetchRendition() error = %v", err)
+	}
+	defer rendition.Close()
+	content, _ := io.ReadAll(rendition)
+	if string(content) != "pdf rendition" || rendition.Filename != "doc.pdf" {
+		t.Errorf("FetchRendition() = %s %q", rendition.Filename, content)
+	}
+	if _, err := repo.FetchRendition(context.Background(), "/ws/doc", "unknown", nil); !hasErrorStatus(err, http.StatusNotFound) {
+		t.Errorf("FetchRendition() of an unknown rendition error = %v, want not found", err)
+	}
+}
+
+func TestRepository_Convert(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		xpath       string
+		options     *ConvertOptions
+		wantContent string
+		wantErr     bool
+	}{
+		{name: "format", options: &ConvertOptions{Format: "pdf"}, wantContent: "converted to pdf"},
+		{name: "converter of blob", xpath: "files:files/0/file", options: &ConvertOptions{Converter: "any2pdf"}, wantContent: "converted to any2pdf"},
+		{name: "async", options: &ConvertOptions{MimeType: "application/pdf", Async: true, PollInterval: 1}, wantContent: "converted asynchronously"},
+		{name: "no target", options: nil, wantErr: true},
+		{name: "several targets", options: &ConvertOptions{Format: "pdf", MimeType: "application/pdf"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			srv := &renditionsTestServer{t: t, polls: 3}
+			repo := newTestRepository(srv.respond)
+			converted, err := repo.Convert(context.Background(), "doc", tt.xpath, tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Convert() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer converted.Close()
+			content, _ := io.ReadAll(converted)
+			if string(content) != tt.wantContent {
+				t.Errorf("Convert() = %q, want %q", content, tt.wantContent)
+			}
+		})
+	}
+}
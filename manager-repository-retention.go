@@ -0,0 +1,101 @@
+package nuxeo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+)
+
+///////////////////
+//// RETENTION ////
+///////////////////
+
+// ErrRetentionShortened is returned when extending the retention of a document to a date before its current one.
+var ErrRetentionShortened = errors.New("retention can only be extended")
+
+// retentionExpiringQuery lists the records whose retention expires in a window; the TIMESTAMP literals are formatted in.
+const retentionExpiringQuery = "SELECT * FROM Document WHERE ecm:isRecord = 1 AND ecm:retainUntil >= TIMESTAMP '%s' AND ecm:retainUntil < TIMESTAMP '%s' ORDER BY ecm:retainUntil"
+
+// MakeRecord turns a document (a repository path or a document ID) into a record, which can then be retained.
+// Uses the Document.MakeRecord operation.
+// Returns the record Document or error.
+func (r *repository) MakeRecord(ctx context.Context, documentRef string) (*Document, error) {
+	return r.executeForDocument(ctx, documentRef, NewOperation(OperationDocumentMakeRecord), nil)
+}
+
+// SetRetention retains a record (a repository path or a document ID) until the given date.
+// The server refuses to shorten an existing retention.
+// Uses the Document.SetRetention operation.
+// Returns the retained Document or error.
+func (r *repository) SetRetention(ctx context.Context, documentRef string, until time.Time) (*Document, error) {
+	operation := NewOperation(OperationDocumentSetRetention).SetParam("until", until.UTC())
+	return r.executeForDocument(ctx, documentRef, operation, nil)
+}
+
+// ExtendRetention extends the retention of a document (a repository path or a document ID) until the given date, making
+// it a record first when needed.
+// Returns the retained Document, ErrRetentionShortened when it is already retained beyond the date, or error.
+func (r *repository) ExtendRetention(ctx context.Context, documentRef string, until time.Time) (*Document, error) {
+	doc, err := r.fetchDocument(ctx, documentRef, nil)
+	if err != nil {
+		return nil, err
+	}
+	if retainUntil, found := documentRetainUntil(doc); found && until.Before(retainUntil) {
+		return nil, fmt.Errorf("%w: %s is retained until %s", ErrRetentionShortened, documentRef, retainUntil.Format(time.RFC3339))
+	}
+	if !doc.IsRecord {
+		if _, err := r.MakeRecord(ctx, doc.ID); err != nil {
+			return nil, err
+		}
+	}
+	return r.SetRetention(ctx, doc.ID, until)
+}
+
+// SetLegalHold applies a legal hold with a description to a document (a repository path or a document ID), which
+// cannot be deleted nor modified until the hold is removed.
+// Uses the Document.SetLegalHold operation.
+// Returns the held Document or error.
+func (r *repository) SetLegalHold(ctx context.Context, documentRef string, description string) (*Document, error) {
+	operation := NewOperation(OperationDocumentSetLegalHold).SetParam("value", true).SetParam("description", description)
+	return r.executeForDocument(ctx, documentRef, operation, nil)
+}
+
+// RemoveLegalHold removes the legal hold of a document (a repository path or a document ID).
+// Uses the Document.SetLegalHold operation.
+// Returns the released Document or error.
+func (r *repository) RemoveLegalHold(ctx context.Context, documentRef string) (*Document, error) {
+	operation := NewOperation(OperationDocumentSetLegalHold).SetParam("value", false)
+	return r.executeForDocument(ctx, documentRef, operation, nil)
+}
+
+// AttachRetentionRule attaches a retention rule (a repository path or a document ID of a RetentionRule document) to a
+// document (a repository path or a document ID), making it a record retained as the rule defines.
+// Requires the Nuxeo Retention addon.
+// Uses the Retention.AttachRule operation.
+// Returns the retained Document or error.
+func (r *repository) AttachRetentionRule(ctx context.Context, documentRef string, ruleRef string) (*Document, error) {
+	operation := NewOperation(OperationRetentionAttachRule).SetParam("rule", ruleRef)
+	return r.executeForDocument(ctx, documentRef, operation, nil)
+}
+
+// ListRetentionExpiring iterates over the records whose retention expires from the from date, included, to the to date,
+// excluded, in the order of expiry, fetching pages of pageSize documents lazily.
+// Iteration stops at the first error, which is yielded with a nil document.
+func (r *repository) ListRetentionExpiring(ctx context.Context, from time.Time, to time.Time, pageSize int, options *nuxeoRequestOptions) iter.Seq2[*Document, error] {
+	query := fmt.Sprintf(retentionExpiringQuery, from.UTC().Format(ISO8601TimeLayout), to.UTC().Format(ISO8601TimeLayout))
+	return r.QueryAll(ctx, query, nil, pageSize, r.requestOptions(options))
+}
+
+// documentRetainUntil returns the date until which a document is retained, if any.
+func documentRetainUntil(doc *Document) (time.Time, bool) {
+	if doc.RetainUntil == "" {
+		return time.Time{}, false
+	}
+	retainUntil, err := time.Parse(time.RFC3339, doc.RetainUntil)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return retainUntil, true
+}
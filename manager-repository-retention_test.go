@@ -0,0 +1,154 @@
+package nuxeo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// retentionTestServer serves the documents of retainUntil at /ws/{id}, and applies the retention operations, refusing to
+// shorten a retention like the server does. Queries match the records retained until the month the window starts in.
+type retentionTestServer struct {
+	t           *testing.T
+	mu          sync.Mutex
+	retainUntil map[string]string
+	records     map[string]bool
+	legalHolds  map[string]string
+	rules       map[string]string
+}
+
+func (srv *retentionTestServer) respond(req *http.Request) (*http.Response, error) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	doc := func(id string) map[string]any {
+		_, hasLegalHold := srv.legalHolds[id]
+		return map[string]any{
+			"entity-type":  "document",
+			"uid":          id,
+			"path":         "/ws/" + id,
+			"isRecord":     srv.records[id],
+			"retainUntil":  srv.retainUntil[id],
+			"hasLegalHold": hasLegalHold,
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/path/ws/"):
+		id := strings.TrimPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/path/ws/")
+		if _, found := srv.retainUntil[id]; !found {
+			return testNotFoundResponse(srv.t)
+		}
+		return testJsonResponse(srv.t, 200, doc(id))
+	case req.URL.EscapedPath() == "/api/v1/query":
+		if req.Header.Get("X-NXRepository") != "default" {
+			srv.t.Errorf("query sent to repository %q", req.Header.Get("X-NXRepository"))
+		}
+		query := req.URL.Query().Get("query")
+		entries := []any{}
+		for _, id := range slices.Sorted(maps.Keys(srv.retainUntil)) {
+			if srv.records[id] && strings.Contains(query, ">= TIMESTAMP '"+srv.retainUntil[id][:7]) {
+				entries = append(entries, doc(id))
+			}
+		}
+		return testJsonResponse(srv.t, 200, map[string]any{"entity-type": "documents", "entries": entries})
+	case strings.HasPrefix(req.URL.EscapedPath(), "/site/automation/"):
+		var payload operationPayload
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			return nil, err
+		}
+		id := strings.TrimPrefix(payload.Input[strings.Index(payload.Input, ":")+1:], "/ws/")
+		switch strings.TrimPrefix(req.URL.EscapedPath(), "/site/automation/") {
+		case OperationDocumentMakeRecord:
+			srv.records[id] = true
+		case OperationDocumentSetRetention:
+			if !srv.records[id] {
+				return testJsonResponse(srv.t, 400, map[string]any{"entity-type": "exception", "status": 400, "message": "not a record"})
+			}
+			if payload.Params["until"] < srv.retainUntil[id] {
+				return testJsonResponse(srv.t, 400, map[string]any{"entity-type": "exception", "status": 400, "message": "cannot shorten retention"})
+			}
+			srv.retainUntil[id] = payload.Params["until"]
+		case OperationDocumentSetLegalHold:
+			// like the server, the hold flag defaults to true and unknown parameters are ignored
+			if payload.Params["value"] != "false" {
+				srv.legalHolds[id] = payload.Params["description"]
+			} else {
+				delete(srv.legalHolds, id)
+			}
+		case OperationRetentionAttachRule:
+			srv.records[id] = true
+			srv.rules[id] = payload.Params["rule"]
+		}
+		return testJsonResponse(srv.t, 200, doc(id))
+	}
+	return nil, errors.New("unexpected request " + req.URL.String())
+}
+
+func TestRepository_Retention(t *testing.T) {
+	t.Parallel()
+	srv := &retentionTestServer{
+		t:           t,
+		retainUntil: map[string]string{"a": "", "b": "2030-06-01T00:00:00Z", "c": "2031-01-01T00:00:00Z"},
+		records:     map[string]bool{"b": true, "c": true},
+		legalHolds:  map[string]string{},
+		rules:       map[string]string{},
+	}
+	repo := newTestRepository(srv.respond)
+	ctx := context.Background()
+
+	retained, err := repo.ExtendRetention(ctx, "/ws/a", time.Date(2030, 6, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("ExtendRetention() error = %v", err)
+	}
+	if !retained.IsRecord || retained.RetainUntil != "2030-06-15T00:00:00Z" {
+		t.Errorf("ExtendRetention() = record %t until %s", retained.IsRecord, retained.RetainUntil)
+	}
+	if _, err := repo.ExtendRetention(ctx, "/ws/c", time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)); !errors.Is(err, ErrRetentionShortened) {
+		t.Errorf("ExtendRetention() to an earlier date error = %v, want ErrRetentionShortened", err)
+	}
+	if _, err := repo.ExtendRetention(ctx, "/ws/missing", time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)); !hasErrorStatus(err, http.StatusNotFound) {
+		t.Errorf("ExtendRetention() of a missing document error = %v, want not found", err)
+	}
+	if _, err := repo.SetRetention(ctx, "/ws/b", time.Date(2029, 1, 1, 0, 0, 0, 0, time.UTC)); !hasErrorStatus(err, http.StatusBadRequest) {
+		t.Errorf("SetRetention() to an earlier date error = %v, want bad request", err)
+	}
+
+	expiring := []string{}
+	for doc, err := range repo.ListRetentionExpiring(ctx, time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2030, 7, 1, 0, 0, 0, 0, time.UTC), 0, nil) {
+		if err != nil {
+			t.Fatalf("ListRetentionExpiring() error = %v", err)
+		}
+		expiring = append(expiring, doc.ID)
+	}
+	if got := strings.Join(expiring, ","); got != "a,b" {
+		t.Errorf("ListRetentionExpiring() = %s, want a,b", got)
+	}
+}
+
+func TestRepository_LegalHold(t *testing.T) {
+	t.Parallel()
+	srv := &retentionTestServer{t: t, retainUntil: map[string]string{}, records: map[string]bool{}, legalHolds: map[string]string{}, rules: map[string]string{}}
+	repo := newTestRepository(srv.respond)
+	ctx := context.Background()
+
+	held, err := repo.SetLegalHold(ctx, "/ws/a", "litigation 42")
+	if err != nil || !held.HasLegalHold || srv.legalHolds["a"] != "litigation 42" {
+		t.Fatalf("SetLegalHold() = %+v, %v", held, err)
+	}
+	released, err := repo.RemoveLegalHold(ctx, "/ws/a")
+	if err != nil || released.HasLegalHold {
+		t.Fatalf("RemoveLegalHold() = %+v, %v", released, err)
+	}
+
+	record, err := repo.AttachRetentionRule(ctx, "/ws/a", "/rules/contracts")
+	if err != nil || !record.IsRecord || srv.rules["a"] != "/rules/contracts" {
+		t.Errorf("AttachRetentionRule() = %+v, %v", record, err)
+	}
+}
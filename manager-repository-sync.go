@@ -0,0 +1,1022 @@
+package nuxeo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+////////////////////////
+//// DIRECTORY SYNC ////
+////////////////////////
+
+// SyncStateFileName is the default name of the state file kept in the synchronised directory.
+const SyncStateFileName = ".nuxeo-sync.json"
+
+// syncDeletionEvents are the audit events confirming that a document left the synchronised tree.
+var syncDeletionEvents = []string{"documentRemoved", "documentTrashed", "documentMoved"}
+
+// SyncConflictPolicy selects how SyncDirectory resolves files changed on both sides since the previous sync.
+type SyncConflictPolicy int
+
+const (
+	// SyncConflictKeepBoth renames the local file aside, downloads the remote one, and uploads the renamed copy as a new document
+	SyncConflictKeepBoth SyncConflictPolicy = iota
+	// SyncConflictPreferLocal overwrites the remote changes with the local ones
+	SyncConflictPreferLocal
+	// SyncConflictPreferRemote overwrites the local changes with the remote ones
+	SyncConflictPreferRemote
+	// SyncConflictSkip leaves both sides untouched and reports the conflict, which shows up again on the next sync
+	SyncConflictSkip
+)
+
+// SyncAction is the change applied to a file or directory by a sync.
+type SyncAction string
+
+const (
+	SyncActionUpload       SyncAction = "upload"
+	SyncActionDownload     SyncAction = "download"
+	SyncActionDeleteLocal  SyncAction = "deleteLocal"
+	SyncActionDeleteRemote SyncAction = "deleteRemote"
+	SyncActionMoveLocal    SyncAction = "moveLocal"
+	SyncActionMoveRemote   SyncAction = "moveRemote"
+	SyncActionConflict     SyncAction = "conflict"
+)
+
+// SyncOptions configures a directory sync.
+type SyncOptions struct {
+	// StateFile is the path of the sync state file; defaults to SyncStateFileName inside the synchronised directory
+	StateFile string
+	// ConflictPolicy selects how files changed on both sides are resolved; defaults to SyncConflictKeepBoth
+	ConflictPolicy SyncConflictPolicy
+	// Exclude lists glob patterns of the local files and directories, and of the remote documents, left out of the sync.
+	// Patterns are matched like ImportDirectoryOptions.Exclude.
+	Exclude []string
+	// UploadOptions configures the blob uploads
+	UploadOptions *BlobUploadOptions
+	// PermanentDelete deletes the documents of the files and directories deleted locally permanently, instead of putting
+	// them in the trash
+	PermanentDelete bool
+}
+
+// SyncReport lists the changes applied by a sync.
+type SyncReport struct {
+	Applied   []SyncReportEntry
+	Conflicts []SyncReportEntry
+	// Skipped lists the documents missing from the remote tree whose deletion could not be confirmed from the audit
+	Skipped []SyncReportEntry
+	Failed  []SyncReportEntry
+}
+
+// SyncReportEntry describes a single change of a sync.
+type SyncReportEntry struct {
+	Action SyncAction
+	// LocalPath is the slash separated path relative to the synchronised directory
+	LocalPath string
+	// PreviousPath is the former local path of moved entries
+	PreviousPath string
+	DocumentId   string
+	// Err is the reason of the failure of failed entries
+	Err error
+}
+
+// Err returns the failures of the sync joined into a single error, or nil if all changes were applied.
+func (r *SyncReport) Err() error {
+	errs := make([]error, 0, len(r.Failed))
+	for _, entry := range r.Failed {
+		errs = append(errs, fmt.Errorf("%s: %w", entry.LocalPath, entry.Err))
+	}
+	return errors.Join(errs...)
+}
+
+// SyncDirectory synchronises the local directory with the folderish document rootRef (a repository path or a document
+// ID) in both directions.
+//
+// Directories map to folderish documents and files to documents holding a "file:content" blob, named after their title.
+// The state of both sides after each sync is kept in a state file (see SyncOptions.StateFile). Local changes are detected
+// by size, modification time and MD5 hash, remote changes by "dc:modified" date and blob digest. Creations, updates,
+// deletions and moves are propagated both ways: a file moved locally is recognised by its content, a document moved
+// remotely by its ID. Documents of files deleted locally are put in the trash, unless SyncOptions.PermanentDelete is set.
+// Documents missing from the remote tree are deleted locally only once their audit confirms they were removed, trashed
+// or moved away. Files changed on both sides are resolved with options.ConflictPolicy.
+//
+// Directories renamed locally are propagated as a new folder, into which their files are moved.
+// Failures of single entries do not stop the sync: they are listed in the report, see SyncReport.Err.
+func (r *repository) SyncDirectory(ctx context.Context, localDir string, rootRef string, options *SyncOptions) (*SyncReport, error) {
+	if options == nil {
+		options = &SyncOptions{}
+	}
+	if err := os.MkdirAll(localDir, 0o755); err != nil {
+		return nil, err
+	}
+	stateFile := options.StateFile
+	if stateFile == "" {
+		stateFile = filepath.Join(localDir, SyncStateFileName)
+	}
+	root, err := r.fetchDocument(ctx, rootRef, nil)
+	if err != nil {
+		return nil, err
+	}
+	state, err := readSyncState(stateFile, root.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &directorySync{
+		repo:      r,
+		root:      localDir,
+		stateFile: stateFile,
+		options:   options,
+		state:     state,
+		report:    &SyncReport{},
+		startedAt: time.Now().UTC(),
+		requestOptions: NewNuxeoRequestOptions().
+			SetRepositoryName(r.name).
+			SetSchemas([]string{"dublincore", "file"}),
+		deletions: map[string]bool{},
+	}
+	if err := s.scanRemote(ctx, root); err != nil {
+		return nil, err
+	}
+	if err := s.scanLocal(); err != nil {
+		return nil, err
+	}
+	s.syncDirectories(ctx)
+	// directory moves changed the local paths
+	if err := s.scanLocal(); err != nil {
+		return nil, err
+	}
+	s.syncTrackedFiles(ctx)
+	s.syncUntrackedFiles(ctx)
+	s.syncDeletedDirectories(ctx)
+	if ctx.Err() != nil {
+		return s.report, ctx.Err()
+	}
+
+	syncedAt := ISO8601Time(s.startedAt)
+	s.state.LastSync = &syncedAt
+	if err := writeSyncState(stateFile, s.state); err != nil {
+		return s.report, err
+	}
+	return s.report, nil
+}
+
+// syncState is the content of the sync state file.
+type syncState struct {
+	RootId   string       `json:"rootId"`
+	LastSync *ISO8601Time `json:"lastSync,omitempty"`
+	// Entries maps the synchronised document IDs to their state at the end of the last sync
+	Entries map[string]*syncStateEntry `json:"entries"`
+}
+
+// syncStateEntry records a synchronised file or directory.
+type syncStateEntry struct {
+	LocalPath string    `json:"localPath"`
+	Folder    bool      `json:"folder,omitempty"`
+	Size      int64     `json:"size,omitempty"`
+	ModTime   time.Time `json:"modTime"`
+	// Digest is the MD5 digest of the local file
+	Digest         string    `json:"digest,omitempty"`
+	RemoteModified time.Time `json:"remoteModified"`
+	RemoteDigest   string    `json:"remoteDigest,omitempty"`
+}
+
+// readSyncState reads the state file, returning an empty state if it does not exist yet.
+func readSyncState(stateFile string, rootId string) (*syncState, error) {
+	state := &syncState{RootId: rootId, Entries: map[string]*syncStateEntry{}}
+	data, err := os.ReadFile(stateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to decode sync state %s: %w", stateFile, err)
+	}
+	if state.RootId != rootId {
+		return nil, fmt.Errorf("sync state %s belongs to the document %s", stateFile, state.RootId)
+	}
+	if state.Entries == nil {
+		state.Entries = map[string]*syncStateEntry{}
+	}
+	return state, nil
+}
+
+// writeSyncState atomically writes the state file.
+func writeSyncState(stateFile string, state *syncState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(stateFile, data)
+}
+
+// writeFileAtomically writes the data into a temporary file next to filePath, then moves it to filePath.
+func writeFileAtomically(filePath string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filePath)
+}
+
+// syncLocalEntry is a file or directory found in the synchronised directory.
+type syncLocalEntry struct {
+	folder  bool
+	size    int64
+	modTime time.Time
+}
+
+// syncRemoteEntry is a document found in the synchronised tree.
+type syncRemoteEntry struct {
+	doc      *Document
+	relPath  string
+	folder   bool
+	modified time.Time
+	digest   string
+	// deleted is set once the document is deleted by the sync
+	deleted bool
+}
+
+// directorySync holds the state of a running directory sync.
+type directorySync struct {
+	repo           *repository
+	root           string
+	stateFile      string
+	options        *SyncOptions
+	state          *syncState
+	report         *SyncReport
+	startedAt      time.Time
+	requestOptions *nuxeoRequestOptions
+
+	// remotes maps the document IDs of the remote tree to their entry
+	remotes map[string]*syncRemoteEntry
+	// remoteDirs maps the local paths of the remote folderish documents to their ID
+	remoteDirs map[string]string
+	// locals maps the local paths to their entry
+	locals map[string]*syncLocalEntry
+	// claimed lists the local paths already matched with a document
+	claimed map[string]bool
+	// deletions caches whether the documents missing from the remote tree were deleted
+	deletions map[string]bool
+}
+
+// scanRemote lists the folderish documents and the documents holding a blob under the root document.
+func (s *directorySync) scanRemote(ctx context.Context, root *Document) error {
+	s.remotes = map[string]*syncRemoteEntry{}
+	s.remoteDirs = map[string]string{".": root.ID}
+	dirNames := map[string]*uniqueNames{}
+	return s.repo.WalkTree(ctx, root.ID, func(doc *Document, depth int) error {
+		if depth == 0 {
+			return nil
+		}
+		parent, found := s.remotes[doc.ParentRef]
+		parentPath := "."
+		if found {
+			parentPath = parent.relPath
+		} else if doc.ParentRef != root.ID {
+			return fmt.Errorf("no local path for parent %s of %s", doc.ParentRef, doc.ID)
+		}
+		isFile := len(documentBlobs(doc, DocumentPropertyFileContent)) > 0
+		if !doc.IsFolder() && !isFile {
+			return nil
+		}
+		names, found := dirNames[parentPath]
+		if !found {
+			names = newUniqueNames()
+			names.reserve(SyncStateFileName)
+			dirNames[parentPath] = names
+		}
+		relPath := path.Join(parentPath, names.next(documentTitle(doc)))
+		if matchesAny(s.options.Exclude, relPath) {
+			return ErrSkipChildren
+		}
+		entry := &syncRemoteEntry{doc: doc, relPath: relPath, folder: doc.IsFolder(), modified: documentModified(doc)}
+		if content := doc.FileContent(); isFile && content != nil {
+			entry.digest = content.Digest
+		}
+		s.remotes[doc.ID] = entry
+		if entry.folder {
+			s.remoteDirs[relPath] = doc.ID
+		}
+		return nil
+	}, s.requestOptions)
+}
+
+// scanLocal lists the files and directories of the synchronised directory.
+func (s *directorySync) scanLocal() error {
+	s.locals = map[string]*syncLocalEntry{}
+	s.claimed = map[string]bool{}
+	return filepath.WalkDir(s.root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(s.root, filePath)
+		if err != nil || rel == "." {
+			return err
+		}
+		relPath := filepath.ToSlash(rel)
+		if filePath == s.stateFile || strings.HasPrefix(entry.Name(), ".nuxeo-sync") || matchesAny(s.options.Exclude, relPath) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.IsDir() && !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		s.locals[relPath] = &syncLocalEntry{folder: entry.IsDir(), size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+}
+
+// syncDirectories creates the new directories on both sides and moves the local directories of moved folderish documents, top down.
+func (s *directorySync) syncDirectories(ctx context.Context) {
+	tracked := map[string]bool{}
+	for _, id := range s.sortedEntries(false) {
+		entry := s.state.Entries[id]
+		if !entry.Folder {
+			continue
+		}
+		tracked[entry.LocalPath] = true
+		remote, found := s.remotes[id]
+		if !found || !remote.folder || remote.relPath == entry.LocalPath {
+			continue
+		}
+		local, localFound := s.locals[entry.LocalPath]
+		if _, taken := s.locals[remote.relPath]; !localFound || !local.folder || taken {
+			continue
+		}
+		// the folderish document was moved or renamed remotely
+		previousPath := entry.LocalPath
+		if err := s.moveLocal(previousPath, remote.relPath); err != nil {
+			s.fail(SyncActionMoveLocal, remote.relPath, id, err)
+			continue
+		}
+		s.renameTracked(previousPath, remote.relPath)
+		tracked[remote.relPath] = true
+		s.applied(SyncActionMoveLocal, remote.relPath, previousPath, id)
+	}
+
+	paths := []string{}
+	for relPath, local := range s.locals {
+		if local.folder && !tracked[relPath] {
+			paths = append(paths, relPath)
+		}
+	}
+	for id, remote := range s.remotes {
+		if _, known := s.state.Entries[id]; remote.folder && !known && !tracked[remote.relPath] {
+			paths = append(paths, remote.relPath)
+		}
+	}
+	sort.Strings(paths)
+	for _, relPath := range slices.Compact(paths) {
+		if ctx.Err() != nil {
+			return
+		}
+		local, localFound := s.locals[relPath]
+		remoteId, remoteFound := s.remoteDirs[relPath]
+		switch {
+		case remoteFound && (!localFound || !local.folder):
+			if err := os.MkdirAll(s.localPath(relPath), 0o755); err != nil {
+				s.fail(SyncActionDownload, relPath, remoteId, err)
+				continue
+			}
+			s.applied(SyncActionDownload, relPath, "", remoteId)
+		case !remoteFound:
+			folder, err := s.ensureRemoteDir(ctx, relPath)
+			if err != nil {
+				s.fail(SyncActionUpload, relPath, "", err)
+				continue
+			}
+			remoteId = folder
+			s.applied(SyncActionUpload, relPath, "", remoteId)
+		}
+		s.state.Entries[remoteId] = &syncStateEntry{LocalPath: relPath, Folder: true}
+	}
+}
+
+// syncTrackedFiles propagates the changes of the files synchronised by a previous sync.
+func (s *directorySync) syncTrackedFiles(ctx context.Context) {
+	for _, entry := range s.state.Entries {
+		if !entry.Folder {
+			s.claimed[entry.LocalPath] = true
+		}
+	}
+	for _, id := range s.sortedEntries(false) {
+		if ctx.Err() != nil {
+			return
+		}
+		entry := s.state.Entries[id]
+		if entry.Folder {
+			continue
+		}
+		if err := s.syncTrackedFile(ctx, id, entry); err != nil {
+			s.fail("", entry.LocalPath, id, err)
+		}
+	}
+}
+
+// syncTrackedFile compares both sides of a synchronised file with its state, and applies the changes.
+func (s *directorySync) syncTrackedFile(ctx context.Context, id string, entry *syncStateEntry) error {
+	localPath := entry.LocalPath
+	local, localFound := s.locals[localPath]
+	localFound = localFound && !local.folder
+	localChanged := false
+	if localFound {
+		changed, err := s.localChanged(localPath, local, entry)
+		if err != nil {
+			return err
+		}
+		localChanged = changed
+	} else if movedTo := s.findLocalMove(entry); movedTo != "" {
+		localPath, local, localFound = movedTo, s.locals[movedTo], true
+		s.claimed[movedTo] = true
+	}
+
+	remote, remoteFound := s.remotes[id]
+	remoteFound = remoteFound && !remote.folder
+	if !remoteFound {
+		deleted, err := s.remoteDeleted(ctx, id, entry)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			s.repo.logger.Warn("Document missing from the synchronised tree without deletion event", slog.String("uid", id), slog.String("path", entry.LocalPath))
+			s.report.Skipped = append(s.report.Skipped, SyncReportEntry{LocalPath: entry.LocalPath, DocumentId: id})
+			return nil
+		}
+	}
+	remoteChanged := remoteFound && !remote.modified.Equal(entry.RemoteModified) && (remote.digest == "" || remote.digest != entry.RemoteDigest)
+
+	switch {
+	case !localFound && !remoteFound:
+		delete(s.state.Entries, id)
+		return nil
+	case !remoteFound:
+		delete(s.state.Entries, id)
+		if localChanged || localPath != entry.LocalPath {
+			switch s.options.ConflictPolicy {
+			case SyncConflictPreferRemote:
+			case SyncConflictSkip:
+				s.state.Entries[id] = entry
+				s.conflict(localPath, id)
+				return nil
+			default:
+				// keep the local changes as a new document
+				s.claimed[localPath] = false
+				return nil
+			}
+		}
+		if err := os.Remove(s.localPath(localPath)); err != nil {
+			return err
+		}
+		s.applied(SyncActionDeleteLocal, localPath, "", id)
+		return nil
+	case !localFound:
+		if remoteChanged && s.options.ConflictPolicy != SyncConflictPreferLocal {
+			if s.options.ConflictPolicy == SyncConflictSkip {
+				s.conflict(entry.LocalPath, id)
+				return nil
+			}
+			return s.download(ctx, remote, remote.relPath)
+		}
+		if err := s.deleteRemote(ctx, id); err != nil {
+			return err
+		}
+		remote.deleted = true
+		delete(s.state.Entries, id)
+		s.applied(SyncActionDeleteRemote, entry.LocalPath, "", id)
+		return nil
+	}
+
+	// both sides exist, move first
+	if localPath != entry.LocalPath && (remote.relPath == entry.LocalPath || s.options.ConflictPolicy == SyncConflictPreferLocal) {
+		if err := s.moveRemote(ctx, remote, localPath); err != nil {
+			return err
+		}
+		s.applied(SyncActionMoveRemote, localPath, remote.relPath, id)
+	} else if remote.relPath != localPath {
+		if _, taken := s.locals[remote.relPath]; taken {
+			return fmt.Errorf("cannot move to %s, a local file already exists", remote.relPath)
+		}
+		if err := s.moveLocal(localPath, remote.relPath); err != nil {
+			return err
+		}
+		s.applied(SyncActionMoveLocal, remote.relPath, localPath, id)
+		delete(s.locals, localPath)
+		s.locals[remote.relPath] = local
+		localPath = remote.relPath
+	}
+	entry.LocalPath = localPath
+	s.claimed[localPath] = true
+
+	if localChanged && remoteChanged {
+		same, err := s.localMatchesRemote(localPath, remote)
+		if err != nil {
+			return err
+		}
+		if same {
+			return s.track(id, localPath, remote.doc)
+		}
+		switch s.options.ConflictPolicy {
+		case SyncConflictPreferLocal:
+			remoteChanged = false
+		case SyncConflictPreferRemote:
+			localChanged = false
+		case SyncConflictSkip:
+			s.conflict(localPath, id)
+			return nil
+		default:
+			conflictPath, err := s.moveAside(localPath)
+			if err != nil {
+				return err
+			}
+			s.conflict(conflictPath, id)
+			localChanged = false
+		}
+	}
+	switch {
+	case localChanged:
+		return s.upload(ctx, id, localPath)
+	case remoteChanged:
+		return s.download(ctx, remote, localPath)
+	}
+	return nil
+}
+
+// syncUntrackedFiles uploads the new local files and downloads the new remote documents.
+func (s *directorySync) syncUntrackedFiles(ctx context.Context) {
+	untrackedRemotes := map[string]*syncRemoteEntry{}
+	for id, remote := range s.remotes {
+		if _, known := s.state.Entries[id]; !known && !remote.folder && !remote.deleted {
+			untrackedRemotes[remote.relPath] = remote
+		}
+	}
+	paths := []string{}
+	for relPath, local := range s.locals {
+		if !local.folder && !s.claimed[relPath] {
+			paths = append(paths, relPath)
+		}
+	}
+	sort.Strings(paths)
+	for _, relPath := range paths {
+		if ctx.Err() != nil {
+			return
+		}
+		remote, found := untrackedRemotes[relPath]
+		if !found {
+			if err := s.create(ctx, relPath); err != nil {
+				s.fail(SyncActionUpload, relPath, "", err)
+			}
+			continue
+		}
+		// created on both sides
+		delete(untrackedRemotes, relPath)
+		if err := s.syncCreatedTwice(ctx, relPath, remote); err != nil {
+			s.fail("", relPath, remote.doc.ID, err)
+		}
+	}
+
+	paths = paths[:0]
+	for relPath := range untrackedRemotes {
+		paths = append(paths, relPath)
+	}
+	sort.Strings(paths)
+	for _, relPath := range paths {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.download(ctx, untrackedRemotes[relPath], relPath); err != nil {
+			s.fail(SyncActionDownload, relPath, untrackedRemotes[relPath].doc.ID, err)
+		}
+	}
+}
+
+// syncCreatedTwice resolves a new local file whose path matches a new remote document.
+func (s *directorySync) syncCreatedTwice(ctx context.Context, relPath string, remote *syncRemoteEntry) error {
+	same, err := s.localMatchesRemote(relPath, remote)
+	if err != nil {
+		return err
+	}
+	if same {
+		return s.track(remote.doc.ID, relPath, remote.doc)
+	}
+	switch s.options.ConflictPolicy {
+	case SyncConflictPreferLocal:
+		return s.upload(ctx, remote.doc.ID, relPath)
+	case SyncConflictPreferRemote:
+		return s.download(ctx, remote, relPath)
+	case SyncConflictSkip:
+		s.conflict(relPath, remote.doc.ID)
+		return nil
+	}
+	conflictPath, err := s.moveAside(relPath)
+	if err != nil {
+		return err
+	}
+	s.conflict(conflictPath, remote.doc.ID)
+	if err := s.download(ctx, remote, relPath); err != nil {
+		return err
+	}
+	return s.create(ctx, conflictPath)
+}
+
+// syncDeletedDirectories propagates the deletions of synchronised directories, bottom up.
+func (s *directorySync) syncDeletedDirectories(ctx context.Context) {
+	for _, id := range s.sortedEntries(true) {
+		if ctx.Err() != nil {
+			return
+		}
+		entry, found := s.state.Entries[id]
+		if !found || !entry.Folder {
+			continue
+		}
+		_, localErr := os.Stat(s.localPath(entry.LocalPath))
+		localFound := localErr == nil
+		remote, remoteFound := s.remotes[id]
+
+		switch {
+		case !localFound && !remoteFound:
+			delete(s.state.Entries, id)
+		case !remoteFound:
+			deleted, err := s.remoteDeleted(ctx, id, entry)
+			if err != nil {
+				s.fail(SyncActionDeleteLocal, entry.LocalPath, id, err)
+				continue
+			}
+			if !deleted {
+				s.report.Skipped = append(s.report.Skipped, SyncReportEntry{LocalPath: entry.LocalPath, DocumentId: id})
+				continue
+			}
+			if err := os.Remove(s.localPath(entry.LocalPath)); err != nil {
+				// local files were kept, recreate the folder to hold them
+				delete(s.state.Entries, id)
+				if _, err := s.ensureRemoteDir(ctx, entry.LocalPath); err != nil {
+					s.fail(SyncActionUpload, entry.LocalPath, id, err)
+				}
+				continue
+			}
+			delete(s.state.Entries, id)
+			s.applied(SyncActionDeleteLocal, entry.LocalPath, "", id)
+		case !localFound:
+			if s.hasRemoteContent(remote) {
+				// remote content was added meanwhile, it is downloaded on the next sync
+				continue
+			}
+			if err := s.deleteRemote(ctx, id); err != nil {
+				s.fail(SyncActionDeleteRemote, entry.LocalPath, id, err)
+				continue
+			}
+			remote.deleted = true
+			delete(s.state.Entries, id)
+			s.applied(SyncActionDeleteRemote, entry.LocalPath, "", id)
+		}
+	}
+}
+
+// deleteRemote puts the document of a file or directory deleted locally in the trash, or deletes it permanently with
+// SyncOptions.PermanentDelete.
+func (s *directorySync) deleteRemote(ctx context.Context, id string) error {
+	if s.options.PermanentDelete {
+		return s.repo.DeleteDocument(ctx, id)
+	}
+	_, err := s.repo.Trash(ctx, id)
+	return err
+}
+
+// remoteDeleted returns true if the document missing from the remote tree does not exist anymore, or if its audit, or
+// the audit of a missing ancestor, confirms it was removed, trashed or moved away since the last sync.
+func (s *directorySync) remoteDeleted(ctx context.Context, id string, entry *syncStateEntry) (bool, error) {
+	if deleted, checked := s.deletions[id]; checked {
+		return deleted, nil
+	}
+	for parentId, parent := range s.state.Entries {
+		if _, found := s.remotes[parentId]; found || !parent.Folder || parent.LocalPath != path.Dir(entry.LocalPath) {
+			continue
+		}
+		if deleted, err := s.remoteDeleted(ctx, parentId, parent); err != nil || deleted {
+			s.deletions[id] = deleted
+			return deleted, err
+		}
+	}
+
+	audit, err := s.repo.FetchAuditById(ctx, id, nil)
+	if hasErrorStatus(err, http.StatusNotFound) {
+		s.deletions[id] = true
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	deleted := false
+	for _, logEntry := range audit.Entries {
+		if !slices.Contains(syncDeletionEvents, logEntry.EventID) {
+			continue
+		}
+		if s.state.LastSync == nil || logEntry.EventDate == nil || time.Time(*logEntry.EventDate).After(time.Time(*s.state.LastSync)) {
+			deleted = true
+			break
+		}
+	}
+	s.deletions[id] = deleted
+	return deleted, nil
+}
+
+// hasRemoteContent returns true if the folderish document still holds documents not deleted by the sync.
+func (s *directorySync) hasRemoteContent(folder *syncRemoteEntry) bool {
+	for _, remote := range s.remotes {
+		if !remote.deleted && strings.HasPrefix(remote.relPath, folder.relPath+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// localChanged returns true if the local file content differs from the state, hashing it only if its size or modification time changed.
+func (s *directorySync) localChanged(relPath string, local *syncLocalEntry, entry *syncStateEntry) (bool, error) {
+	if local.size == entry.Size && local.modTime.Equal(entry.ModTime) {
+		return false, nil
+	}
+	digest, err := fileDigest(s.localPath(relPath), "MD5")
+	if err != nil {
+		return false, err
+	}
+	return digest != entry.Digest, nil
+}
+
+// findLocalMove returns the path of an untracked local file holding the content of the missing synchronised file, if any.
+func (s *directorySync) findLocalMove(entry *syncStateEntry) string {
+	candidates := []string{}
+	for relPath, local := range s.locals {
+		if !local.folder && !s.claimed[relPath] && local.size == entry.Size {
+			candidates = append(candidates, relPath)
+		}
+	}
+	sort.Strings(candidates)
+	for _, relPath := range candidates {
+		if digest, err := fileDigest(s.localPath(relPath), "MD5"); err == nil && digest == entry.Digest {
+			return relPath
+		}
+	}
+	return ""
+}
+
+// localMatchesRemote returns true if the local file has the digest of the remote blob.
+func (s *directorySync) localMatchesRemote(relPath string, remote *syncRemoteEntry) (bool, error) {
+	content := remote.doc.FileContent()
+	if content == nil || content.Digest == "" {
+		return false, nil
+	}
+	digest, err := fileDigest(s.localPath(relPath), content.DigestAlgorithm)
+	if err != nil || digest == "" {
+		return false, err
+	}
+	return strings.EqualFold(digest, content.Digest), nil
+}
+
+// upload replaces the blob of the document with the local file.
+func (s *directorySync) upload(ctx context.Context, id string, relPath string) error {
+	fileBlob, err := NewBlobFromFile(s.localPath(relPath))
+	if err != nil {
+		return err
+	}
+	defer fileBlob.Close()
+	doc, err := s.repo.AttachBlobsWithOptions(ctx, id, DocumentPropertyFileContent, []*blob{fileBlob}, s.options.UploadOptions, s.requestOptions)
+	if err != nil {
+		return err
+	}
+	s.applied(SyncActionUpload, relPath, "", id)
+	return s.track(id, relPath, doc)
+}
+
+// create uploads the local file as a new document into the folderish document of its directory.
+func (s *directorySync) create(ctx context.Context, relPath string) error {
+	parentId, err := s.ensureRemoteDir(ctx, path.Dir(relPath))
+	if err != nil {
+		return err
+	}
+	fileBlob, err := NewBlobFromFile(s.localPath(relPath))
+	if err != nil {
+		return err
+	}
+	defer fileBlob.Close()
+	name := path.Base(relPath)
+	doc := NewDocument(DocumentTypeFile, name)
+	created, err := s.repo.CreateDocumentWithBlobs(ctx, parentId, *doc, map[string][]*blob{DocumentPropertyFileContent: {fileBlob}}, s.options.UploadOptions, s.requestOptions)
+	if err != nil {
+		return err
+	}
+	s.applied(SyncActionUpload, relPath, "", created.ID)
+	return s.track(created.ID, relPath, created)
+}
+
+// download streams the blob of the document into the local file, replacing it atomically.
+func (s *directorySync) download(ctx context.Context, remote *syncRemoteEntry, relPath string) error {
+	filePath := s.localPath(relPath)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return err
+	}
+	stream, err := s.repo.StreamBlobById(ctx, remote.doc.ID, DocumentPropertyFileContent, nil)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".nuxeo-sync-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, stream); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return err
+	}
+	if !remote.modified.IsZero() {
+		if err := os.Chtimes(filePath, remote.modified, remote.modified); err != nil {
+			return err
+		}
+	}
+	s.applied(SyncActionDownload, relPath, "", remote.doc.ID)
+	return s.track(remote.doc.ID, relPath, remote.doc)
+}
+
+// moveRemote moves the document into the folderish document of the local directory, renaming it after the local file.
+func (s *directorySync) moveRemote(ctx context.Context, remote *syncRemoteEntry, relPath string) error {
+	name := path.Base(relPath)
+	if path.Dir(relPath) != path.Dir(remote.relPath) {
+		parentId, err := s.ensureRemoteDir(ctx, path.Dir(relPath))
+		if err != nil {
+			return err
+		}
+		doc, err := s.repo.Move(ctx, remote.doc.ID, parentId, name, s.requestOptions)
+		if err != nil {
+			return err
+		}
+		remote.doc = doc
+	}
+	if name != path.Base(remote.relPath) {
+		doc, err := s.repo.updateProperties(ctx, remote.doc.ID, map[string]Field{DocumentPropertyDCTitle: NewStringField(name)}, s.requestOptions)
+		if err != nil {
+			return err
+		}
+		remote.doc = doc
+		remote.modified = documentModified(doc)
+	}
+	remote.relPath = relPath
+	return nil
+}
+
+// moveLocal renames the local file or directory, creating the missing parent directories.
+func (s *directorySync) moveLocal(fromPath string, toPath string) error {
+	if err := os.MkdirAll(filepath.Dir(s.localPath(toPath)), 0o755); err != nil {
+		return err
+	}
+	return os.Rename(s.localPath(fromPath), s.localPath(toPath))
+}
+
+// moveAside renames a conflicting local file to a free "name (conflict).ext" path, returning the new path.
+func (s *directorySync) moveAside(relPath string) (string, error) {
+	ext := path.Ext(relPath)
+	base := strings.TrimSuffix(relPath, ext)
+	conflictPath := base + " (conflict)" + ext
+	for i := 2; ; i++ {
+		if _, err := os.Stat(s.localPath(conflictPath)); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		conflictPath = fmt.Sprintf("%s (conflict %d)%s", base, i, ext)
+	}
+	if err := s.moveLocal(relPath, conflictPath); err != nil {
+		return "", err
+	}
+	// the renamed copy is uploaded as a new document
+	s.locals[conflictPath] = &syncLocalEntry{}
+	return conflictPath, nil
+}
+
+// renameTracked rewrites the local paths of the entries under a moved directory.
+func (s *directorySync) renameTracked(fromPath string, toPath string) {
+	for _, entry := range s.state.Entries {
+		if entry.LocalPath == fromPath {
+			entry.LocalPath = toPath
+		} else if rest, found := strings.CutPrefix(entry.LocalPath, fromPath+"/"); found {
+			entry.LocalPath = toPath + "/" + rest
+		}
+	}
+}
+
+// ensureRemoteDir returns the ID of the folderish document of the local directory, creating the missing ones.
+func (s *directorySync) ensureRemoteDir(ctx context.Context, relPath string) (string, error) {
+	if id, found := s.remoteDirs[relPath]; found {
+		return id, nil
+	}
+	parentId, err := s.ensureRemoteDir(ctx, path.Dir(relPath))
+	if err != nil {
+		return "", err
+	}
+	name := path.Base(relPath)
+	doc := NewDocument(DocumentTypeFolder, name)
+	folder, err := s.repo.CreateDocumentById(ctx, parentId, *doc, s.requestOptions)
+	if err != nil {
+		return "", err
+	}
+	s.remoteDirs[relPath] = folder.ID
+	s.remotes[folder.ID] = &syncRemoteEntry{doc: folder, relPath: relPath, folder: true, modified: documentModified(folder)}
+	s.state.Entries[folder.ID] = &syncStateEntry{LocalPath: relPath, Folder: true}
+	return folder.ID, nil
+}
+
+// track records both sides of a synchronised file into the state.
+func (s *directorySync) track(id string, relPath string, doc *Document) error {
+	info, err := os.Stat(s.localPath(relPath))
+	if err != nil {
+		return err
+	}
+	digest, err := fileDigest(s.localPath(relPath), "MD5")
+	if err != nil {
+		return err
+	}
+	entry := &syncStateEntry{
+		LocalPath:      relPath,
+		Size:           info.Size(),
+		ModTime:        info.ModTime(),
+		Digest:         digest,
+		RemoteModified: documentModified(doc),
+	}
+	if content := doc.FileContent(); content != nil {
+		entry.RemoteDigest = content.Digest
+	}
+	s.state.Entries[id] = entry
+	s.claimed[relPath] = true
+	return nil
+}
+
+// sortedEntries returns the IDs of the state entries sorted by local path, deepest first if reversed.
+func (s *directorySync) sortedEntries(reversed bool) []string {
+	ids := make([]string, 0, len(s.state.Entries))
+	for id := range s.state.Entries {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		pi, pj := s.state.Entries[ids[i]].LocalPath, s.state.Entries[ids[j]].LocalPath
+		if reversed {
+			return pi > pj
+		}
+		return pi < pj
+	})
+	return ids
+}
+
+// localPath returns the file path of the slash separated path relative to the synchronised directory.
+func (s *directorySync) localPath(relPath string) string {
+	return filepath.Join(s.root, filepath.FromSlash(relPath))
+}
+
+// applied adds an applied change into the report.
+func (s *directorySync) applied(action SyncAction, relPath string, previousPath string, id string) {
+	s.repo.logger.Info("Synchronised entry", slog.String("action", string(action)), slog.String("path", relPath))
+	s.report.Applied = append(s.report.Applied, SyncReportEntry{Action: action, LocalPath: relPath, PreviousPath: previousPath, DocumentId: id})
+}
+
+// conflict adds a conflict into the report.
+func (s *directorySync) conflict(relPath string, id string) {
+	s.repo.logger.Warn("Conflicting changes on both sides", slog.String("path", relPath), slog.String("uid", id))
+	s.report.Conflicts = append(s.report.Conflicts, SyncReportEntry{Action: SyncActionConflict, LocalPath: relPath, DocumentId: id})
+}
+
+// fail adds a failed change into the report.
+func (s *directorySync) fail(action SyncAction, relPath string, id string, err error) {
+	s.repo.logger.Error("Failed to synchronise entry", slog.String("error", err.Error()), slog.String("action", string(action)), slog.String("path", relPath))
+	s.report.Failed = append(s.report.Failed, SyncReportEntry{Action: action, LocalPath: relPath, DocumentId: id, Err: err})
+}
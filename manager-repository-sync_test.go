@@ -0,0 +1,446 @@
+package nuxeo
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncTestDocument is a document of the syncTestServer.
+type syncTestDocument struct {
+	id       string
+	parent   string
+	title    string
+	folder   bool
+	content  string
+	modified time.Time
+}
+
+// syncTestServer is an in-memory Nuxeo tree rooted at the "ws" workspace, serving the requests issued by SyncDirectory.
+type syncTestServer struct {
+	t       *testing.T
+	mu      sync.Mutex
+	docs    map[string]*syncTestDocument
+	uploads map[string]string
+	clock   time.Time
+	nextId  int
+	// auditEvents are the audit events served for deleted documents
+	auditEvents map[string]string
+	// trashed and deleted record the documents put in the trash and the documents deleted permanently
+	trashed map[string]bool
+	deleted map[string]bool
+}
+
+func newSyncTestServer(t *testing.T) *syncTestServer {
+	return &syncTestServer{
+		t:           t,
+		docs:        map[string]*syncTestDocument{"ws": {id: "ws", title: "Workspace", folder: true}},
+		uploads:     map[string]string{},
+		clock:       time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		auditEvents: map[string]string{},
+		trashed:     map[string]bool{},
+		deleted:     map[string]bool{},
+	}
+}
+
+// put creates or updates a document, bumping its modification date.
+func (srv *syncTestServer) put(doc *syncTestDocument) *syncTestDocument {
+	if doc.id == "" {
+		srv.nextId++
+		doc.id = fmt.Sprintf("doc%d", srv.nextId)
+	}
+	srv.clock = srv.clock.Add(time.Minute)
+	doc.modified = srv.clock
+	srv.docs[doc.id] = doc
+	return doc
+}
+
+// find returns the document with the given title path under the workspace.
+func (srv *syncTestServer) find(titlePath string) *syncTestDocument {
+	for _, doc := range srv.docs {
+		if srv.path(doc) == "/ws/"+titlePath {
+			return doc
+		}
+	}
+	return nil
+}
+
+func (srv *syncTestServer) path(doc *syncTestDocument) string {
+	if doc.parent == "" {
+		return "/" + doc.id
+	}
+	return srv.path(srv.docs[doc.parent]) + "/" + doc.title
+}
+
+func (srv *syncTestServer) json(doc *syncTestDocument) map[string]any {
+	properties := map[string]any{"dc:title": doc.title, "dc:modified": doc.modified.Format(time.RFC3339)}
+	facets := []string{}
+	docType := DocumentTypeFile
+	if doc.folder {
+		facets = append(facets, "Folderish")
+		docType = DocumentTypeFolder
+	} else {
+		digest := md5.Sum([]byte(doc.content))
+		properties["file:content"] = map[string]any{"name": doc.title, "digestAlgorithm": "MD5", "digest": hex.EncodeToString(digest[:])}
+	}
+	return map[string]any{
+		"entity-type": "document",
+		"uid":         doc.id,
+		"path":        srv.path(doc),
+		"parentRef":   doc.parent,
+		"type":        docType,
+		"title":       doc.title,
+		"facets":      facets,
+		"properties":  properties,
+	}
+}
+
+func (srv *syncTestServer) respond(req *http.Request) (*http.Response, error) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	var body struct {
+		Name       string         `json:"name"`
+		Params     map[string]any `json:"params"`
+		Input      string         `json:"input"`
+		Properties map[string]any `json:"properties"`
+	}
+	segments := strings.Split(strings.TrimPrefix(req.URL.EscapedPath(), "/api/v1/"), "/")
+
+	switch {
+	case req.URL.EscapedPath() == "/api/v1/repo/default/path/ws":
+		return testJsonResponse(srv.t, 200, srv.json(srv.docs["ws"]))
+	case req.URL.EscapedPath() == "/api/v1/query":
+		children := []any{}
+		ids := []string{}
+		for id, doc := range srv.docs {
+			if doc.parent == req.URL.Query().Get("queryParams") {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			children = append(children, srv.json(srv.docs[id]))
+		}
+		return testJsonResponse(srv.t, 200, map[string]any{"entity-type": "documents", "entries": children})
+	case req.URL.EscapedPath() == "/api/v1/upload/new/default":
+		return testJsonResponse(srv.t, 200, map[string]any{"batchId": "batch"})
+	case segments[0] == "upload" && req.Method == http.MethodDelete:
+		return testJsonResponse(srv.t, 200, map[string]any{})
+	case segments[0] == "upload" && len(segments) == 3:
+		content, _ := io.ReadAll(req.Body)
+		srv.uploads[segments[2]] = string(content)
+		return testJsonResponse(srv.t, 200, map[string]any{"batchId": segments[1], "fileIdx": segments[2]})
+	case segments[0] == "upload" && segments[len(segments)-1] == OperationBlobAttachOnDocument:
+		json.NewDecoder(req.Body).Decode(&body)
+		doc := srv.docs[body.Params["document"].(string)]
+		doc.content = srv.uploads["0"]
+		srv.put(doc)
+		return testNoContentResponse()
+	case strings.HasSuffix(req.URL.EscapedPath(), "/"+OperationDocumentMove):
+		json.NewDecoder(req.Body).Decode(&body)
+		doc := srv.docs[strings.TrimPrefix(body.Input, "doc:")]
+		doc.parent = body.Params["target"].(string)
+		return testJsonResponse(srv.t, 200, srv.json(doc))
+	case strings.HasSuffix(req.URL.EscapedPath(), "/"+OperationDocumentTrash):
+		json.NewDecoder(req.Body).Decode(&body)
+		doc := srv.docs[strings.TrimPrefix(body.Input, "doc:")]
+		trashed := srv.json(doc)
+		trashed["isTrashed"] = true
+		delete(srv.docs, doc.id)
+		srv.trashed[doc.id] = true
+		return testJsonResponse(srv.t, 200, trashed)
+	case segments[0] == "repo" && segments[2] == "id":
+		doc, found := srv.docs[segments[3]]
+		if !found {
+			if event, found := srv.auditEvents[segments[3]]; found && len(segments) == 5 && segments[4] == "@audit" {
+				return testJsonResponse(srv.t, 200, map[string]any{"entity-type": "logEntries", "entries": []any{map[string]any{"eventId": event}}})
+			}
+			return testNotFoundResponse(srv.t)
+		}
+		switch {
+		case len(segments) > 4 && segments[4] == "@blob":
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(doc.content)), Header: http.Header{}}, nil
+		case req.Method == http.MethodGet:
+			return testJsonResponse(srv.t, 200, srv.json(doc))
+		case req.Method == http.MethodPost:
+			json.NewDecoder(req.Body).Decode(&body)
+			child := &syncTestDocument{parent: doc.id, title: body.Properties["dc:title"].(string)}
+			if content, found := body.Properties["file:content"].(map[string]any); found {
+				child.content = srv.uploads[content["upload-fileId"].(string)]
+			} else {
+				child.folder = true
+			}
+			return testJsonResponse(srv.t, 200, srv.json(srv.put(child)))
+		case req.Method == http.MethodPut:
+			json.NewDecoder(req.Body).Decode(&body)
+			doc.title = body.Properties["dc:title"].(string)
+			return testJsonResponse(srv.t, 200, srv.json(srv.put(doc)))
+		case req.Method == http.MethodDelete:
+			delete(srv.docs, doc.id)
+			srv.deleted[doc.id] = true
+			return testJsonResponse(srv.t, 200, map[string]any{})
+		}
+	}
+	return nil, errors.New("unexpected request " + req.Method + " " + req.URL.String())
+}
+
+// syncTestFiles returns the content of the local files, keyed by slash separated relative path.
+func syncTestFiles(t *testing.T, dir string) map[string]string {
+	files := map[string]string{}
+	filepath.WalkDir(dir, func(filePath string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || entry.Name() == SyncStateFileName {
+			return err
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rel, _ := filepath.Rel(dir, filePath)
+		files[filepath.ToSlash(rel)] = string(content)
+		return nil
+	})
+	return files
+}
+
+// syncTestWrite writes a local file with a modification time distinct from the previous one.
+func syncTestWrite(t *testing.T, dir string, relPath string, content string, modTime time.Time) {
+	filePath := filepath.Join(dir, filepath.FromSlash(relPath))
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filePath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filePath, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// syncTestActions returns the applied changes of the report as "action path" strings.
+func syncTestActions(report *SyncReport) []string {
+	actions := []string{}
+	for _, entry := range report.Applied {
+		actions = append(actions, string(entry.Action)+" "+entry.LocalPath)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+func TestRepository_SyncDirectory(t *testing.T) {
+	t.Parallel()
+	srv := newSyncTestServer(t)
+	docs := srv.put(&syncTestDocument{parent: "ws", title: "Docs", folder: true})
+	srv.put(&syncTestDocument{parent: docs.id, title: "a.txt", content: "A"})
+	srv.put(&syncTestDocument{parent: "ws", title: "b.txt", content: "B"})
+	dir := t.TempDir()
+	modTime := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	syncTestWrite(t, dir, "c.txt", "C", modTime)
+	repo := newTestRepository(srv.respond)
+	var deletedLocally string
+
+	steps := []struct {
+		name        string
+		change      func()
+		wantActions []string
+		wantFiles   map[string]string
+	}{
+		{
+			name:        "initial sync",
+			change:      func() {},
+			wantActions: []string{"download Docs", "download Docs/a.txt", "download b.txt", "upload c.txt"},
+			wantFiles:   map[string]string{"Docs/a.txt": "A", "b.txt": "B", "c.txt": "C"},
+		},
+		{
+			name:        "nothing changed",
+			change:      func() {},
+			wantActions: []string{},
+			wantFiles:   map[string]string{"Docs/a.txt": "A", "b.txt": "B", "c.txt": "C"},
+		},
+		{
+			name: "updates and moves",
+			change: func() {
+				syncTestWrite(t, dir, "b.txt", "B2", modTime.Add(time.Hour))
+				a := srv.find("Docs/a.txt")
+				a.content = "A2"
+				srv.put(a)
+				os.Rename(filepath.Join(dir, "c.txt"), filepath.Join(dir, "Docs", "c2.txt"))
+			},
+			wantActions: []string{"download Docs/a.txt", "moveRemote Docs/c2.txt", "upload b.txt"},
+			wantFiles:   map[string]string{"Docs/a.txt": "A2", "b.txt": "B2", "Docs/c2.txt": "C"},
+		},
+		{
+			name: "deletions",
+			change: func() {
+				delete(srv.docs, srv.find("Docs/a.txt").id)
+				deletedLocally = srv.find("b.txt").id
+				os.Remove(filepath.Join(dir, "b.txt"))
+			},
+			wantActions: []string{"deleteLocal Docs/a.txt", "deleteRemote b.txt"},
+			wantFiles:   map[string]string{"Docs/c2.txt": "C"},
+		},
+		{
+			name: "conflict keeps both",
+			change: func() {
+				syncTestWrite(t, dir, "Docs/c2.txt", "local C", modTime.Add(2*time.Hour))
+				c := srv.find("Docs/c2.txt")
+				c.content = "remote C"
+				srv.put(c)
+			},
+			wantActions: []string{"download Docs/c2.txt", "upload Docs/c2 (conflict).txt"},
+			wantFiles:   map[string]string{"Docs/c2.txt": "remote C", "Docs/c2 (conflict).txt": "local C"},
+		},
+		{
+			name: "remote folder rename",
+			change: func() {
+				folder := srv.find("Docs")
+				folder.title = "Papers"
+				srv.put(folder)
+			},
+			wantActions: []string{"moveLocal Papers"},
+			wantFiles:   map[string]string{"Papers/c2.txt": "remote C", "Papers/c2 (conflict).txt": "local C"},
+		},
+	}
+
+	for _, step := range steps {
+		step.change()
+		report, err := repo.SyncDirectory(context.Background(), dir, "/ws", nil)
+		if err != nil {
+			t.Fatalf("%s: SyncDirectory() error = %v", step.name, err)
+		}
+		if err := report.Err(); err != nil {
+			t.Fatalf("%s: unexpected failures: %v", step.name, err)
+		}
+		if got := syncTestActions(report); !slices.Equal(got, step.wantActions) {
+			t.Errorf("%s: actions = %q, want %q", step.name, got, step.wantActions)
+		}
+		files := syncTestFiles(t, dir)
+		if len(files) != len(step.wantFiles) {
+			t.Errorf("%s: local files = %q, want %q", step.name, files, step.wantFiles)
+		}
+		for relPath, content := range step.wantFiles {
+			if files[relPath] != content {
+				t.Errorf("%s: local file %s = %q, want %q", step.name, relPath, files[relPath], content)
+			}
+			if doc := srv.find(relPath); doc == nil || doc.content != content {
+				t.Errorf("%s: remote document %s = %+v, want content %q", step.name, relPath, doc, content)
+			}
+		}
+	}
+	if !srv.trashed[deletedLocally] || len(srv.deleted) != 0 {
+		t.Errorf("trashed %v and deleted %v, want %s trashed", srv.trashed, srv.deleted, deletedLocally)
+	}
+}
+
+func TestRepository_SyncDirectoryPermanentDelete(t *testing.T) {
+	t.Parallel()
+	srv := newSyncTestServer(t)
+	doc := srv.put(&syncTestDocument{parent: "ws", title: "a.txt", content: "A"})
+	dir := t.TempDir()
+	repo := newTestRepository(srv.respond)
+	options := &SyncOptions{PermanentDelete: true}
+	if _, err := repo.SyncDirectory(context.Background(), dir, "/ws", options); err != nil {
+		t.Fatalf("SyncDirectory() error = %v", err)
+	}
+
+	os.Remove(filepath.Join(dir, "a.txt"))
+	report, err := repo.SyncDirectory(context.Background(), dir, "/ws", options)
+	if err != nil {
+		t.Fatalf("SyncDirectory() error = %v", err)
+	}
+	if got := syncTestActions(report); !slices.Equal(got, []string{"deleteRemote a.txt"}) {
+		t.Errorf("actions = %q", got)
+	}
+	if !srv.deleted[doc.id] || len(srv.trashed) != 0 {
+		t.Errorf("trashed %v and deleted %v, want %s deleted", srv.trashed, srv.deleted, doc.id)
+	}
+}
+
+func TestRepository_SyncDirectoryConflictPolicy(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		policy        SyncConflictPolicy
+		wantContent   string
+		wantConflicts int
+	}{
+		{name: "prefer local", policy: SyncConflictPreferLocal, wantContent: "local"},
+		{name: "prefer remote", policy: SyncConflictPreferRemote, wantContent: "remote"},
+		{name: "skip", policy: SyncConflictSkip, wantConflicts: 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			srv := newSyncTestServer(t)
+			doc := srv.put(&syncTestDocument{parent: "ws", title: "a.txt", content: "base"})
+			dir := t.TempDir()
+			repo := newTestRepository(srv.respond)
+			options := &SyncOptions{ConflictPolicy: tc.policy}
+			if _, err := repo.SyncDirectory(context.Background(), dir, "/ws", options); err != nil {
+				t.Fatalf("SyncDirectory() error = %v", err)
+			}
+
+			syncTestWrite(t, dir, "a.txt", "local", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+			doc.content = "remote"
+			srv.put(doc)
+			report, err := repo.SyncDirectory(context.Background(), dir, "/ws", options)
+			if err != nil {
+				t.Fatalf("SyncDirectory() error = %v", err)
+			}
+			if len(report.Conflicts) != tc.wantConflicts {
+				t.Errorf("conflicts = %+v, want %d", report.Conflicts, tc.wantConflicts)
+			}
+			files := syncTestFiles(t, dir)
+			if tc.wantContent != "" && (files["a.txt"] != tc.wantContent || doc.content != tc.wantContent) {
+				t.Errorf("local %q, remote %q, want %q", files["a.txt"], doc.content, tc.wantContent)
+			}
+			if tc.policy == SyncConflictSkip && (files["a.txt"] != "local" || doc.content != "remote") {
+				t.Errorf("skipped conflict changed local %q or remote %q", files["a.txt"], doc.content)
+			}
+		})
+	}
+}
+
+func TestRepository_SyncDirectoryUnconfirmedDeletion(t *testing.T) {
+	t.Parallel()
+	srv := newSyncTestServer(t)
+	doc := srv.put(&syncTestDocument{parent: "ws", title: "a.txt", content: "A"})
+	dir := t.TempDir()
+	repo := newTestRepository(srv.respond)
+	if _, err := repo.SyncDirectory(context.Background(), dir, "/ws", nil); err != nil {
+		t.Fatalf("SyncDirectory() error = %v", err)
+	}
+
+	// the document disappears from the tree, but its audit has no deletion event
+	delete(srv.docs, doc.id)
+	srv.auditEvents[doc.id] = "documentModified"
+	report, err := repo.SyncDirectory(context.Background(), dir, "/ws", nil)
+	if err != nil {
+		t.Fatalf("SyncDirectory() error = %v", err)
+	}
+	if len(report.Skipped) != 1 || syncTestFiles(t, dir)["a.txt"] != "A" {
+		t.Errorf("unconfirmed deletion was applied: %+v", report)
+	}
+
+	srv.auditEvents[doc.id] = "documentTrashed"
+	report, err = repo.SyncDirectory(context.Background(), dir, "/ws", nil)
+	if err != nil {
+		t.Fatalf("SyncDirectory() error = %v", err)
+	}
+	if got := syncTestActions(report); !slices.Equal(got, []string{"deleteLocal a.txt"}) {
+		t.Errorf("actions = %q", got)
+	}
+}
@@ -0,0 +1,121 @@
+package nuxeo
+
+import (
+	"context"
+	"iter"
+	"log/slog"
+	"strings"
+)
+
+//////////////
+//// TAGS ////
+//////////////
+
+// tagQuery lists the live documents carrying a tag.
+const tagQuery = "SELECT * FROM Document WHERE ecm:tag = ? AND ecm:isVersion = 0 AND ecm:isProxy = 0 AND ecm:isTrashed = 0 ORDER BY ecm:path"
+
+// subtreeQuery lists the live descendants of a document, by path.
+const subtreeQuery = "SELECT * FROM Document WHERE ecm:path STARTSWITH ? AND ecm:isVersion = 0 AND ecm:isProxy = 0 AND ecm:isTrashed = 0"
+
+// AddTags tags a document (a repository path or a document ID).
+// Uses the Services.TagDocument operation.
+// Returns the tagged Document or error.
+func (r *repository) AddTags(ctx context.Context, documentRef string, tags ...string) (*Document, error) {
+	operation := NewOperation(OperationServicesTagDocument).SetParam("tags", strings.Join(tags, ","))
+	return r.executeForDocument(ctx, documentRef, operation, nil)
+}
+
+// RemoveTags removes tags from a document (a repository path or a document ID).
+// Uses the Services.UntagDocument operation.
+// Returns the untagged Document or error.
+func (r *repository) RemoveTags(ctx context.Context, documentRef string, tags ...string) (*Document, error) {
+	operation := NewOperation(OperationServicesUntagDocument).SetParam("tags", strings.Join(tags, ","))
+	return r.executeForDocument(ctx, documentRef, operation, nil)
+}
+
+// ListTags retrieves the tags of a document (a repository path or a document ID).
+// Returns the tag labels or error.
+func (r *repository) ListTags(ctx context.Context, documentRef string) ([]string, error) {
+	options := NewNuxeoRequestOptions().SetRepositoryName(r.name).SetSchemas([]string{DocumentSchemaTags})
+	doc, err := r.fetchDocument(ctx, documentRef, options)
+	if err != nil {
+		return nil, err
+	}
+	return documentTags(doc), nil
+}
+
+// SuggestTags retrieves the existing tags of the repository starting with the prefix, for autocompletion.
+// Uses the Tag.Suggestion operation.
+// Returns the matching tag labels or error.
+func (r *repository) SuggestTags(ctx context.Context, prefix string) ([]string, error) {
+	operation := NewOperation(OperationTagSuggestion).SetParam("searchTerm", prefix)
+	options := NewNuxeoRequestOptions().SetRepositoryName(r.name)
+	res, err := r.client.OperationManager().Execute(ctx, *operation, options)
+	if err != nil {
+		r.logger.Error("Failed to suggest tags", slog.String("error", err.Error()))
+		return nil, err
+	}
+	if res == nil {
+		return []string{}, nil
+	}
+	defer res.res.Body.Close()
+	var suggestions []struct {
+		Id           string `json:"id"`
+		DisplayLabel string `json:"displayLabel"`
+	}
+	if err := res.As(&suggestions); err != nil {
+		r.logger.Error("Failed to decode tag suggestions", slog.String("error", err.Error()))
+		return nil, err
+	}
+	tags := make([]string, len(suggestions))
+	for i, suggestion := range suggestions {
+		tags[i] = suggestion.Id
+	}
+	return tags, nil
+}
+
+// TagCloud counts the documents carrying each tag, among the live descendants of a document (a repository path or a
+// document ID).
+// Returns the number of tagged documents by tag, or error.
+func (r *repository) TagCloud(ctx context.Context, rootRef string) (map[string]int, error) {
+	root, err := r.fetchDocument(ctx, rootRef, nil)
+	if err != nil {
+		return nil, err
+	}
+	options := NewNuxeoRequestOptions().SetRepositoryName(r.name).SetSchemas([]string{DocumentSchemaTags})
+	cloud := map[string]int{}
+	for doc, err := range r.QueryAll(ctx, subtreeQuery, []string{root.Path}, 0, options) {
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range documentTags(doc) {
+			cloud[tag]++
+		}
+	}
+	return cloud, nil
+}
+
+// FindByTag iterates over the live documents carrying the tag, fetching pages of pageSize lazily.
+// Iteration stops at the first error, which is yielded with a nil document.
+func (r *repository) FindByTag(ctx context.Context, tag string, pageSize int, options *nuxeoRequestOptions) iter.Seq2[*Document, error] {
+	return r.QueryAll(ctx, tagQuery, []string{tag}, pageSize, r.requestOptions(options))
+}
+
+// documentTags returns the tag labels of the document, from its nxtag:tags property.
+func documentTags(doc *Document) []string {
+	field, found := doc.Property(DocumentPropertyNxtagTags)
+	if !found || field.IsNull() {
+		return nil
+	}
+	var tags []struct {
+		Label string `json:"label"`
+	}
+	if err := field.ComplexList(&tags); err != nil {
+		return nil
+	}
+	labels := make([]string, len(tags))
+	for i, tag := range tags {
+		labels[i] = tag.Label
+	}
+	return labels
+}
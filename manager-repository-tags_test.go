@@ -0,0 +1,128 @@
+package nuxeo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/anselm94/nuxeo-go-client/internal"
+)
+
+// tagsTestServer serves the folder "ws" at /ws, holding the documents of tags, which can be tagged and untagged.
+type tagsTestServer struct {
+	t    *testing.T
+	mu   sync.Mutex
+	tags map[string][]string
+}
+
+func (srv *tagsTestServer) respond(req *http.Request) (*http.Response, error) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	doc := func(id string) map[string]any {
+		tags := []any{}
+		for _, tag := range srv.tags[id] {
+			tags = append(tags, map[string]any{"label": tag, "username": "Administrator"})
+		}
+		return map[string]any{"entity-type": "document", "uid": id, "path": "/ws/" + id, "properties": map[string]any{DocumentPropertyNxtagTags: tags}}
+	}
+	docs := func(match func(tags []string) bool) (*http.Response, error) {
+		entries := []any{}
+		for _, id := range slices.Sorted(maps.Keys(srv.tags)) {
+			if match(srv.tags[id]) {
+				entries = append(entries, doc(id))
+			}
+		}
+		return testJsonResponse(srv.t, 200, map[string]any{"entity-type": "documents", "entries": entries})
+	}
+
+	switch {
+	case req.URL.EscapedPath() == "/api/v1/repo/default/path/ws":
+		return testJsonResponse(srv.t, 200, map[string]any{"entity-type": "document", "uid": "ws", "path": "/ws"})
+	case strings.HasPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/path/ws/"):
+		if req.Header.Get(internal.HeaderProperties) != DocumentSchemaTags {
+			srv.t.Errorf("tags fetched with schemas %q", req.Header.Get(internal.HeaderProperties))
+		}
+		return testJsonResponse(srv.t, 200, doc(strings.TrimPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/path/ws/")))
+	case req.URL.EscapedPath() == "/api/v1/query":
+		if req.Header.Get("X-NXRepository") != "default" {
+			srv.t.Errorf("query sent to repository %q", req.Header.Get("X-NXRepository"))
+		}
+		query, param := req.URL.Query().Get("query"), req.URL.Query().Get("queryParams")
+		switch {
+		case strings.Contains(query, "ecm:path STARTSWITH") && param == "/ws":
+			return docs(func(tags []string) bool { return true })
+		case strings.Contains(query, "ecm:tag = "):
+			return docs(func(tags []string) bool { return slices.Contains(tags, param) })
+		}
+	case strings.HasSuffix(req.URL.EscapedPath(), "/"+OperationTagSuggestion):
+		return testJsonResponse(srv.t, 200, []any{
+			map[string]any{"id": "music", "displayLabel": "music"},
+			map[string]any{"id": "museum", "displayLabel": "museum"},
+		})
+	case strings.HasPrefix(req.URL.EscapedPath(), "/site/automation/Services."):
+		var payload operationPayload
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			return nil, err
+		}
+		id := strings.TrimPrefix(payload.Input, "doc:/ws/")
+		for _, tag := range strings.Split(payload.Params["tags"], ",") {
+			if strings.HasSuffix(req.URL.EscapedPath(), "/"+OperationServicesTagDocument) {
+				srv.tags[id] = append(srv.tags[id], tag)
+			} else {
+				srv.tags[id] = slices.DeleteFunc(srv.tags[id], func(t string) bool { return t == tag })
+			}
+		}
+		return testJsonResponse(srv.t, 200, doc(id))
+	}
+	return nil, errors.New("unexpected request " + req.URL.String())
+}
+
+func TestRepository_Tags(t *testing.T) {
+	t.Parallel()
+	srv := &tagsTestServer{t: t, tags: map[string][]string{"a": {"art"}, "b": nil, "c": {"art", "music"}}}
+	repo := newTestRepository(srv.respond)
+	ctx := context.Background()
+
+	if _, err := repo.AddTags(ctx, "/ws/b", "music", "jazz"); err != nil {
+		t.Fatalf("AddTags() error = %v", err)
+	}
+	if _, err := repo.RemoveTags(ctx, "/ws/c", "art"); err != nil {
+		t.Fatalf("RemoveTags() error = %v", err)
+	}
+	for ref, want := range map[string]string{"/ws/a": "art", "/ws/b": "music,jazz", "/ws/c": "music"} {
+		tags, err := repo.ListTags(ctx, ref)
+		if err != nil || strings.Join(tags, ",") != want {
+			t.Errorf("ListTags(%s) = %v, %v, want %s", ref, tags, err, want)
+		}
+	}
+
+	cloud, err := repo.TagCloud(ctx, "/ws")
+	if err != nil {
+		t.Fatalf("TagCloud() error = %v", err)
+	}
+	if !maps.Equal(cloud, map[string]int{"art": 1, "music": 2, "jazz": 1}) {
+		t.Errorf("TagCloud() = %v", cloud)
+	}
+
+	found := []string{}
+	for doc, err := range repo.FindByTag(ctx, "music", 0, nil) {
+		if err != nil {
+			t.Fatalf("FindByTag() error = %v", err)
+		}
+		found = append(found, doc.ID)
+	}
+	if got := strings.Join(found, ","); got != "b,c" {
+		t.Errorf("FindByTag() = %s, want b,c", got)
+	}
+
+	suggestions, err := repo.SuggestTags(ctx, "mu")
+	if err != nil || strings.Join(suggestions, ",") != "music,museum" {
+		t.Errorf("SuggestTags() = %v, %v", suggestions, err)
+	}
+}
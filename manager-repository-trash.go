@@ -0,0 +1,78 @@
+package nuxeo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+///////////////
+//// TRASH ////
+///////////////
+
+// ErrNotTrashed is returned when permanently deleting a document which is not in the trash, without forcing it.
+var ErrNotTrashed = errors.New("document is not trashed")
+
+// trashQuery lists the trashed children of a document.
+const trashQuery = "SELECT * FROM Document WHERE ecm:parentId = ? AND ecm:isVersion = 0 AND ecm:isProxy = 0 AND ecm:isTrashed = 1 ORDER BY ecm:name"
+
+// Trash moves documents (repository paths or document IDs) to the trash, along with their descendants.
+// Uses the Document.Trash operation.
+// Returns the trashed Documents or error.
+func (r *repository) Trash(ctx context.Context, documentRefs ...string) (*Documents, error) {
+	return r.executeForDocuments(ctx, NewOperation(OperationDocumentTrash), documentRefs)
+}
+
+// Untrash restores trashed documents (repository paths or document IDs), along with their descendants and trashed ancestors.
+// Uses the Document.Untrash operation.
+// Returns the restored Documents or error.
+func (r *repository) Untrash(ctx context.Context, documentRefs ...string) (*Documents, error) {
+	return r.executeForDocuments(ctx, NewOperation(OperationDocumentUntrash), documentRefs)
+}
+
+// ListTrash retrieves a page of the trashed children of a document (a repository path or a document ID).
+// Returns the trashed Documents or error.
+func (r *repository) ListTrash(ctx context.Context, parentRef string, paginationOptions *SortedPaginationOptions, options *nuxeoRequestOptions) (*Documents, error) {
+	parent, err := r.fetchDocument(ctx, parentRef, nil)
+	if err != nil {
+		return nil, err
+	}
+	return r.Query(ctx, trashQuery, []string{parent.ID}, paginationOptions, r.requestOptions(options))
+}
+
+// EmptyTrash permanently deletes the trashed children of a document (a repository path or a document ID).
+// Returns the number of deleted documents, and the first error met.
+func (r *repository) EmptyTrash(ctx context.Context, parentRef string) (int, error) {
+	parent, err := r.fetchDocument(ctx, parentRef, nil)
+	if err != nil {
+		return 0, err
+	}
+	// list the whole trash before deleting, as deletions shift the pages
+	trashedIds := []string{}
+	for doc, err := range r.QueryAll(ctx, trashQuery, []string{parent.ID}, 0, NewNuxeoRequestOptions().SetRepositoryName(r.name)) {
+		if err != nil {
+			return 0, err
+		}
+		trashedIds = append(trashedIds, doc.ID)
+	}
+	for i, id := range trashedIds {
+		if err := r.DeleteDocument(ctx, id); err != nil {
+			return i, err
+		}
+	}
+	return len(trashedIds), nil
+}
+
+// DeleteDocumentPermanently deletes a document (a repository path or a document ID) and its descendants, bypassing the
+// trash. Unless force is set, only trashed documents are deleted.
+// Returns ErrNotTrashed if the document is not trashed, or error.
+func (r *repository) DeleteDocumentPermanently(ctx context.Context, documentRef string, force bool) error {
+	doc, err := r.fetchDocument(ctx, documentRef, nil)
+	if err != nil {
+		return err
+	}
+	if !doc.IsTrashed && !force {
+		return fmt.Errorf("cannot delete %s permanently: %w", documentRef, ErrNotTrashed)
+	}
+	return r.DeleteDocument(ctx, doc.ID)
+}
@@ -0,0 +1,152 @@
+package nuxeo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// trashTestServer serves the folder "ws" at /ws, holding the documents of trashed, and records the deleted documents.
+type trashTestServer struct {
+	t       *testing.T
+	mu      sync.Mutex
+	trashed map[string]bool
+	deleted []string
+	// queriedRepository is the repository header of the last query
+	queriedRepository string
+}
+
+func (srv *trashTestServer) respond(req *http.Request) (*http.Response, error) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	doc := func(id string) map[string]any {
+		return map[string]any{"entity-type": "document", "uid": id, "path": "/ws/" + id, "parentRef": "ws", "isTrashed": srv.trashed[id]}
+	}
+
+	switch {
+	case req.URL.EscapedPath() == "/api/v1/repo/default/path/ws":
+		return testJsonResponse(srv.t, 200, map[string]any{"entity-type": "document", "uid": "ws", "path": "/ws"})
+	case req.URL.EscapedPath() == "/api/v1/query":
+		srv.queriedRepository = req.Header.Get("X-NXRepository")
+		if req.URL.Query().Get("queryParams") != "ws" || !strings.Contains(req.URL.Query().Get("query"), "ecm:isTrashed = 1") {
+			srv.t.Errorf("unexpected query %s", req.URL.RawQuery)
+		}
+		entries := []any{}
+		for _, id := range slices.Sorted(maps.Keys(srv.trashed)) {
+			if srv.trashed[id] {
+				entries = append(entries, doc(id))
+			}
+		}
+		return testJsonResponse(srv.t, 200, map[string]any{"entity-type": "documents", "entries": entries})
+	case strings.HasPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/id/"):
+		id := strings.TrimPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/id/")
+		if _, found := srv.trashed[id]; !found {
+			return testNotFoundResponse(srv.t)
+		}
+		if req.Method == http.MethodDelete {
+			srv.deleted = append(srv.deleted, id)
+			delete(srv.trashed, id)
+			return testNoContentResponse()
+		}
+		return testJsonResponse(srv.t, 200, doc(id))
+	case strings.HasPrefix(req.URL.EscapedPath(), "/site/automation/"):
+		var payload operationPayload
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			return nil, err
+		}
+		trash := strings.HasSuffix(req.URL.EscapedPath(), "/"+OperationDocumentTrash)
+		if id, found := strings.CutPrefix(payload.Input, "doc:"); found {
+			srv.trashed[id] = trash
+			return testJsonResponse(srv.t, 200, doc(id))
+		}
+		entries := []any{}
+		for _, id := range strings.Split(strings.TrimPrefix(payload.Input, "docs:"), ",") {
+			srv.trashed[id] = trash
+			entries = append(entries, doc(id))
+		}
+		return testJsonResponse(srv.t, 200, map[string]any{"entity-type": "documents", "entries": entries})
+	}
+	return nil, errors.New("unexpected request " + req.URL.String())
+}
+
+func TestRepository_TrashUntrash(t *testing.T) {
+	t.Parallel()
+	srv := &trashTestServer{t: t, trashed: map[string]bool{"a": false, "b": false, "c": false}}
+	repo := newTestRepository(srv.respond)
+
+	docs, err := repo.Trash(context.Background(), "a", "b")
+	if err != nil {
+		t.Fatalf("Trash() error = %v", err)
+	}
+	if len(docs.Entries) != 2 || !docs.Entries[0].IsTrashed || !docs.Entries[1].IsTrashed {
+		t.Errorf("Trash() = %+v", docs.Entries)
+	}
+	if docs, err := repo.Untrash(context.Background(), "b"); err != nil || len(docs.Entries) != 1 || docs.Entries[0].IsTrashed {
+		t.Fatalf("Untrash() = %+v, %v", docs, err)
+	}
+	trash, err := repo.ListTrash(context.Background(), "/ws", nil, nil)
+	if err != nil {
+		t.Fatalf("ListTrash() error = %v", err)
+	}
+	if srv.queriedRepository != "default" {
+		t.Errorf("ListTrash() queried repository %q, want default", srv.queriedRepository)
+	}
+	if len(trash.Entries) != 1 || trash.Entries[0].ID != "a" {
+		t.Errorf("ListTrash() = %+v, want a", trash.Entries)
+	}
+	if docs, err := repo.Trash(context.Background()); err != nil || len(docs.Entries) != 0 {
+		t.Errorf("Trash() without documents = %+v, %v", docs, err)
+	}
+}
+
+func TestRepository_EmptyTrash(t *testing.T) {
+	t.Parallel()
+	srv := &trashTestServer{t: t, trashed: map[string]bool{"a": true, "b": false, "c": true}}
+	repo := newTestRepository(srv.respond)
+
+	deleted, err := repo.EmptyTrash(context.Background(), "/ws")
+	if err != nil {
+		t.Fatalf("EmptyTrash() error = %v", err)
+	}
+	if deleted != 2 || strings.Join(srv.deleted, ",") != "a,c" {
+		t.Errorf("EmptyTrash() deleted %d: %v, want a,c", deleted, srv.deleted)
+	}
+	if srv.queriedRepository != "default" {
+		t.Errorf("EmptyTrash() queried repository %q, want default", srv.queriedRepository)
+	}
+}
+
+func TestRepository_DeleteDocumentPermanently(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		documentRef string
+		force       bool
+		wantErr     error
+		wantDeleted bool
+	}{
+		{name: "trashed", documentRef: "trashed", wantDeleted: true},
+		{name: "live", documentRef: "live", wantErr: ErrNotTrashed},
+		{name: "live forced", documentRef: "live", force: true, wantDeleted: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			srv := &trashTestServer{t: t, trashed: map[string]bool{"trashed": true, "live": false}}
+			repo := newTestRepository(srv.respond)
+			err := repo.DeleteDocumentPermanently(context.Background(), tt.documentRef, tt.force)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DeleteDocumentPermanently() error = %v, want %v", err, tt.wantErr)
+			}
+			if deleted := slices.Contains(srv.deleted, tt.documentRef); deleted != tt.wantDeleted {
+				t.Errorf("deleted = %v, want %v", deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
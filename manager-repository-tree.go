@@ -0,0 +1,58 @@
+package nuxeo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+//////////////
+//// TREE ////
+//////////////
+
+// ErrSkipChildren is returned by a TreeWalkFunc to skip the children of the visited document.
+var ErrSkipChildren = errors.New("skip children")
+
+// TreeWalkFunc is called by WalkTree for each visited document, along with its depth below the walked root (0 for the root).
+//
+// Returning ErrSkipChildren skips the children of the document; any other error stops the walk.
+type TreeWalkFunc func(doc *Document, depth int) error
+
+// WalkTree walks the document tree rooted at rootRef (a repository path or a document ID) depth-first, visiting parents
+// before their children. Only folderish documents are descended into; versions, proxies and trashed documents are left out.
+//
+// Children are paged lazily, so arbitrarily large trees can be walked. The options apply to every fetched document, e.g.
+// to select the schemas or enrichers. The first error returned by fn, other than ErrSkipChildren, stops the walk and is returned.
+func (r *repository) WalkTree(ctx context.Context, rootRef string, fn TreeWalkFunc, options *nuxeoRequestOptions) error {
+	root, err := r.fetchDocument(ctx, rootRef, options)
+	if err != nil {
+		return err
+	}
+	return r.walkTree(ctx, root, 0, fn, options)
+}
+
+// walkTree visits the document, then walks its children.
+func (r *repository) walkTree(ctx context.Context, doc *Document, depth int, fn TreeWalkFunc, options *nuxeoRequestOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := fn(doc, depth); err != nil {
+		if errors.Is(err, ErrSkipChildren) {
+			return nil
+		}
+		return err
+	}
+	if !doc.IsFolder() {
+		return nil
+	}
+	for child, err := range r.allChildren(ctx, doc.ID, options) {
+		if err != nil {
+			r.logger.Error("Failed to list children while walking tree", slog.String("error", err.Error()), slog.String("uid", doc.ID))
+			return err
+		}
+		if err := r.walkTree(ctx, child, depth+1, fn, options); err != nil {
+			return err
+		}
+	}
+	return nil
+}
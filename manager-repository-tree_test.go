@@ -0,0 +1,122 @@
+package nuxeo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// treeTestResponder serves the tree /ws > (/ws/sub > /ws/sub/note, /ws/report), and the blobs of /ws/report.
+// Blob downloads are counted into blobRequests.
+func treeTestResponder(t *testing.T, blobRequests *atomic.Int32) func(req *http.Request) (*http.Response, error) {
+	modified := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC).Format(time.RFC3339)
+	folder := func(uid string, path string, parentRef string, title string) map[string]any {
+		return map[string]any{
+			"entity-type": "document",
+			"uid":         uid,
+			"path":        path,
+			"parentRef":   parentRef,
+			"type":        DocumentTypeFolder,
+			"title":       title,
+			"facets":      []string{"Folderish"},
+			"properties":  map[string]any{"dc:title": title, "dc:modified": modified},
+			"contextParameters": map[string]any{
+				"acls": []any{map[string]any{"name": "local", "aces": []any{map[string]any{"username": "jdoe", "permission": "Read", "granted": true}}}},
+			},
+		}
+	}
+	docs := map[string][]any{
+		"ws": {
+			folder("sub", "/ws/sub", "ws", "Sub"),
+			map[string]any{
+				"entity-type": "document",
+				"uid":         "report",
+				"path":        "/ws/report",
+				"parentRef":   "ws",
+				"type":        DocumentTypeFile,
+				"title":       "Report",
+				"properties": map[string]any{
+					"dc:title":     "Report",
+					"dc:modified":  modified,
+					"file:content": map[string]any{"name": "report.pdf", "mime-type": "application/pdf", "digest": "d1"},
+					"files:files":  []any{map[string]any{"file": map[string]any{"name": "report.pdf", "digest": "d2"}}},
+				},
+			},
+		},
+		"sub": {
+			map[string]any{
+				"entity-type": "document",
+				"uid":         "note",
+				"path":        "/ws/sub/note",
+				"parentRef":   "sub",
+				"type":        "Note",
+				"title":       "My Note",
+				"properties":  map[string]any{"dc:title": "My Note", "dc:modified": modified},
+			},
+		},
+	}
+
+	return func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.URL.EscapedPath() == "/api/v1/repo/default/path/ws":
+			return testJsonResponse(t, 200, folder("ws", "/ws", "root", "Workspace"))
+		case req.URL.EscapedPath() == "/api/v1/query":
+			return testJsonResponse(t, 200, map[string]any{"entity-type": "documents", "entries": docs[req.URL.Query().Get("queryParams")]})
+		case strings.HasPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/id/report/@blob/"):
+			blobRequests.Add(1)
+			xpath := strings.TrimPrefix(req.URL.EscapedPath(), "/api/v1/repo/default/id/report/@blob/")
+			return &http.Response{
+				StatusCode: 200,
+				Body:       io.NopCloser(strings.NewReader("content of " + xpath)),
+				Header:     http.Header{"Content-Type": []string{"application/octet-stream"}},
+			}, nil
+		}
+		return nil, errors.New("unexpected request " + req.URL.String())
+	}
+}
+
+func TestRepository_WalkTree(t *testing.T) {
+	t.Parallel()
+	errStop := errors.New("stop")
+	tests := []struct {
+		name    string
+		stopAt  string
+		skipAt  string
+		want    string
+		wantErr error
+	}{
+		{name: "full walk", want: "0:ws,1:sub,2:note,1:report"},
+		{name: "skip children", skipAt: "sub", want: "0:ws,1:sub,1:report"},
+		{name: "stop on error", stopAt: "note", want: "0:ws,1:sub,2:note", wantErr: errStop},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			repo := newTestRepository(treeTestResponder(t, &atomic.Int32{}))
+			visited := []string{}
+			err := repo.WalkTree(context.Background(), "/ws", func(doc *Document, depth int) error {
+				visited = append(visited, fmt.Sprintf("%d:%s", depth, doc.ID))
+				switch doc.ID {
+				case tc.skipAt:
+					return ErrSkipChildren
+				case tc.stopAt:
+					return errStop
+				}
+				return nil
+			}, nil)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("WalkTree() error = %v, want %v", err, tc.wantErr)
+			}
+			if got := strings.Join(visited, ","); got != tc.want {
+				t.Errorf("visited %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package nuxeo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+
+	"github.com/anselm94/nuxeo-go-client/internal"
+)
+
+//////////////////
+//// VERSIONS ////
+//////////////////
+
+// ErrVersionNotFound is returned when a document has no version matching the request.
+var ErrVersionNotFound = errors.New("version not found")
+
+// FetchVersions retrieves the versions of a document, oldest first.
+// Maps to GET /api/v1/repo/{repo}/id/{id}/@versions
+// Returns the versions as Documents with IsVersion and VersionLabel set, or error.
+func (r *repository) FetchVersions(ctx context.Context, documentId string, options *nuxeoRequestOptions) (*Documents, error) {
+	path := internal.PathApiV1 + "/repo/" + url.PathEscape(r.name) + "/id/" + url.PathEscape(documentId) + "/@versions"
+	res, err := r.client.NewRequest(ctx, options).SetResult(&Documents{}).SetError(&NuxeoError{}).Get(path)
+
+	if err := handleNuxeoError(err, res); err != nil {
+		r.logger.Error("Failed to fetch versions", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return res.Result().(*Documents), nil
+}
+
+// FetchVersion retrieves the version of a document with the given label (e.g. "1.2").
+// Returns the version Document, or error if the document has no such version.
+func (r *repository) FetchVersion(ctx context.Context, documentId string, versionLabel string, options *nuxeoRequestOptions) (*Document, error) {
+	versions, err := r.FetchVersions(ctx, documentId, options)
+	if err != nil {
+		return nil, err
+	}
+	for i := range versions.Entries {
+		if versions.Entries[i].VersionLabel == versionLabel {
+			return &versions.Entries[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: document %s has no version %s", ErrVersionNotFound, documentId, versionLabel)
+}
+
+// FetchLatestVersion retrieves the latest version of a document.
+// Uses the Document.GetLastVersion operation.
+// Returns the version Document, ErrVersionNotFound if the document has never been versioned, or error.
+func (r *repository) FetchLatestVersion(ctx context.Context, documentId string, options *nuxeoRequestOptions) (*Document, error) {
+	doc, err := r.executeForDocument(ctx, documentId, NewOperation(OperationDocumentGetLastVersion), options)
+	if errors.Is(err, ErrNoOperationOutput) {
+		return nil, fmt.Errorf("%w: document %s has never been versioned", ErrVersionNotFound, documentId)
+	}
+	return doc, err
+}
+
+// CheckIn creates a new version of a document, incrementing its minor or major version, with an optional comment.
+// Uses the Document.CheckIn operation.
+// Returns the checked in Document, whose VersionLabel is the label of the new version, or error.
+func (r *repository) CheckIn(ctx context.Context, documentId string, increment VersioningOption, comment string, options *nuxeoRequestOptions) (*Document, error) {
+	if increment != VersioningOptionMinor && increment != VersioningOptionMajor {
+		return nil, fmt.Errorf("invalid check in increment %q, expected %q or %q", increment, VersioningOptionMinor, VersioningOptionMajor)
+	}
+	operation := NewOperation(OperationDocumentCheckIn).SetParam("version", string(increment))
+	if comment != "" {
+		operation.SetParam("comment", comment)
+	}
+	return r.executeForDocument(ctx, documentId, operation, options)
+}
+
+// CheckOut checks out a checked in document, so that its next modification starts a new version.
+// Uses the Document.CheckOut operation.
+// Returns the checked out Document or error.
+func (r *repository) CheckOut(ctx context.Context, documentId string, options *nuxeoRequestOptions) (*Document, error) {
+	return r.executeForDocument(ctx, documentId, NewOperation(OperationDocumentCheckOut), options)
+}
+
+// RestoreVersion restores the live document of a version to the content of that version, and checks it out.
+// Uses the Document.RestoreVersion operation.
+// Returns the restored live Document or error.
+func (r *repository) RestoreVersion(ctx context.Context, versionId string, options *nuxeoRequestOptions) (*Document, error) {
+	return r.executeForDocument(ctx, versionId, NewOperation(OperationDocumentRestoreVersion), options)
+}
@@ -0,0 +1,165 @@
+package nuxeo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// versionsTestResponder serves the versions 1.0 and 1.1 of doc, and records the operations executed on documents into
+// executed as "path input params", answering with the input document. The document "unversioned" has no version, for
+// which the server answers Document.GetLastVersion with no content.
+func versionsTestResponder(t *testing.T, executed *string) func(req *http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		switch req.URL.EscapedPath() {
+		case "/api/v1/repo/default/id/doc/@versions":
+			return testJsonResponse(t, 200, map[string]any{"entity-type": "documents", "entries": []any{
+				map[string]any{"entity-type": "document", "uid": "v1", "isVersion": true, "versionLabel": "1.0"},
+				map[string]any{"entity-type": "document", "uid": "v2", "isVersion": true, "versionLabel": "1.1"},
+			}})
+		case "/api/v1/repo/default/id/missing/@versions":
+			return testNotFoundResponse(t)
+		}
+		var payload operationPayload
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			return nil, err
+		}
+		if payload.Input == "" {
+			return nil, errors.New("unexpected request " + req.URL.String())
+		}
+		if payload.Input == "doc:unversioned" && strings.HasSuffix(req.URL.EscapedPath(), "/"+OperationDocumentGetLastVersion) {
+			return testNoContentResponse()
+		}
+		params, _ := json.Marshal(payload.Params)
+		*executed = req.URL.EscapedPath() + " " + payload.Input + " " + string(params)
+		return testJsonResponse(t, 200, map[string]any{"entity-type": "document", "uid": strings.TrimPrefix(payload.Input, "doc:")})
+	}
+}
+
+func TestRepository_FetchVersion(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		documentId string
+		label      string
+		wantId     string
+		wantErr    bool
+	}{
+		{name: "found", documentId: "doc", label: "1.1", wantId: "v2"},
+		{name: "unknown label", documentId: "doc", label: "2.0", wantErr: true},
+		{name: "unknown document", documentId: "missing", label: "1.0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := newTestRepository(versionsTestResponder(t, new(string)))
+			got, err := repo.FetchVersion(context.Background(), tt.documentId, tt.label, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FetchVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (got.ID != tt.wantId || !got.IsVersion) {
+				t.Errorf("FetchVersion() = %+v, want version %s", got, tt.wantId)
+			}
+		})
+	}
+}
+
+func TestRepository_VersionOperations(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		call          func(repo *repository) (*Document, error)
+		wantInput     string
+		wantOperation string
+		wantParams    string
+		wantErr       bool
+	}{
+		{
+			name: "check in major with comment",
+			call: func(repo *repository) (*Document, error) {
+				return repo.CheckIn(context.Background(), "doc", VersioningOptionMajor, "release", nil)
+			},
+			wantInput:     "doc",
+			wantOperation: OperationDocumentCheckIn,
+			wantParams:    `{"comment":"release","version":"major"}`,
+		},
+		{
+			name: "check in minor",
+			call: func(repo *repository) (*Document, error) {
+				return repo.CheckIn(context.Background(), "doc", VersioningOptionMinor, "", nil)
+			},
+			wantInput:     "doc",
+			wantOperation: OperationDocumentCheckIn,
+			wantParams:    `{"version":"minor"}`,
+		},
+		{
+			name: "check in without increment",
+			call: func(repo *repository) (*Document, error) {
+				return repo.CheckIn(context.Background(), "doc", VersioningOptionNone, "", nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "check out",
+			call: func(repo *repository) (*Document, error) {
+				return repo.CheckOut(context.Background(), "doc", nil)
+			},
+			wantInput:     "doc",
+			wantOperation: OperationDocumentCheckOut,
+			wantParams:    "null",
+		},
+		{
+			name: "latest version",
+			call: func(repo *repository) (*Document, error) {
+				return repo.FetchLatestVersion(context.Background(), "doc", nil)
+			},
+			wantInput:     "doc",
+			wantOperation: OperationDocumentGetLastVersion,
+			wantParams:    "null",
+		},
+		{
+			name: "restore version",
+			call: func(repo *repository) (*Document, error) {
+				return repo.RestoreVersion(context.Background(), "v1", nil)
+			},
+			wantInput:     "v1",
+			wantOperation: OperationDocumentRestoreVersion,
+			wantParams:    "null",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var executed string
+			repo := newTestRepository(versionsTestResponder(t, &executed))
+			got, err := tt.call(repo)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if want := "/site/automation/" + tt.wantOperation + " doc:" + tt.wantInput + " " + tt.wantParams; executed != want {
+				t.Errorf("executed %q, want %q", executed, want)
+			}
+			if got.ID != tt.wantInput {
+				t.Errorf("got document %s, want %s", got.ID, tt.wantInput)
+			}
+		})
+	}
+}
+
+func TestRepository_FetchLatestVersion_NeverVersioned(t *testing.T) {
+	t.Parallel()
+	repo := newTestRepository(versionsTestResponder(t, new(string)))
+
+	if _, err := repo.FetchLatestVersion(context.Background(), "unversioned", nil); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("FetchLatestVersion() error = %v, want ErrVersionNotFound", err)
+	}
+	if _, err := repo.FetchVersion(context.Background(), "doc", "2.0", nil); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("FetchVersion() of an unknown label error = %v, want ErrVersionNotFound", err)
+	}
+}
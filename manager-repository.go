@@ -2,10 +2,14 @@ package nuxeo
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"iter"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/anselm94/nuxeo-go-client/internal"
 )
@@ -34,6 +38,147 @@ func (r *repository) Name() string {
 //// DOCUMENTS ////
 ///////////////////
 
+// documentRefPath returns the API path of a document reference.
+// A reference starting with "/" is treated as a repository path, anything else as a document ID.
+func (r *repository) documentRefPath(documentRef string) string {
+	path := internal.PathApiV1 + "/repo/" + url.PathEscape(r.name)
+	if !strings.HasPrefix(documentRef, "/") {
+		return path + "/id/" + url.PathEscape(documentRef)
+	}
+	return path + "/path/" + escapePathSegments(strings.TrimPrefix(documentRef, "/"))
+}
+
+// escapePathSegments escapes each "/" separated segment of a path, such as a repository path or a blob xpath
+// (e.g. "files:files/0/file"), keeping the separators as is.
+func escapePathSegments(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// fetchDocument retrieves a document by reference, which is either a repository path or a document ID.
+func (r *repository) fetchDocument(ctx context.Context, documentRef string, options *nuxeoRequestOptions) (*Document, error) {
+	res, err := r.client.NewRequest(ctx, options).SetResult(&Document{}).SetError(&NuxeoError{}).Get(r.documentRefPath(documentRef))
+
+	if err := handleNuxeoError(err, res); err != nil {
+		r.logger.Error("Failed to fetch document", slog.String("error", err.Error()), slog.String("ref", documentRef))
+		return nil, err
+	}
+	return res.Result().(*Document), nil
+}
+
+// createDocument creates a document under the parent, which is either a repository path or a document ID.
+func (r *repository) createDocument(ctx context.Context, parentRef string, doc Document, options *nuxeoRequestOptions) (*Document, error) {
+	res, err := r.client.NewRequest(ctx, options).SetBody(doc).SetResult(&Document{}).SetError(&NuxeoError{}).Post(r.documentRefPath(parentRef))
+
+	if err := handleNuxeoError(err, res); err != nil {
+		r.logger.Error("Failed to create document", slog.String("error", err.Error()), slog.String("parentRef", parentRef))
+		return nil, err
+	}
+	return res.Result().(*Document), nil
+}
+
+// ErrNoOperationOutput is returned when an Automation operation expected to output documents outputs nothing, which
+// the server answers with a 204 response, e.g. for a null output.
+var ErrNoOperationOutput = errors.New("operation returned no output")
+
+// requestOptions returns the request options, defaulting to options targeting this repository, for requests such as
+// queries whose path does not name the repository.
+func (r *repository) requestOptions(options *nuxeoRequestOptions) *nuxeoRequestOptions {
+	if options == nil {
+		return NewNuxeoRequestOptions().SetRepositoryName(r.name)
+	}
+	return options
+}
+
+// executeOnDocument runs a void Automation operation with the document as input, in this repository.
+func (r *repository) executeOnDocument(ctx context.Context, documentId string, operation *operation) error {
+	operation.SetInputDocumentId(documentId).SetVoidOperation(true)
+	options := NewNuxeoRequestOptions().SetRepositoryName(r.name)
+	if _, err := r.client.OperationManager().Execute(ctx, *operation, options); err != nil {
+		r.logger.Error("Failed to execute operation on document", slog.String("error", err.Error()), slog.String("operation", operation.operationId), slog.String("uid", documentId))
+		return err
+	}
+	return nil
+}
+
+// executeForDocument runs an Automation operation with the document as input, in this repository, and returns its output document.
+func (r *repository) executeForDocument(ctx context.Context, documentId string, operation *operation, options *nuxeoRequestOptions) (*Document, error) {
+	operation.SetInputDocumentId(documentId)
+	if options == nil {
+		options = NewNuxeoRequestOptions().SetRepositoryName(r.name)
+	}
+	res, err := r.client.OperationManager().Execute(ctx, *operation, options)
+	if err != nil {
+		r.logger.Error("Failed to execute operation on document", slog.String("error", err.Error()), slog.String("operation", operation.operationId), slog.String("uid", documentId))
+		return nil, err
+	}
+	if res == nil {
+		return nil, fmt.Errorf("%w: %s on %s", ErrNoOperationOutput, operation.operationId, documentId)
+	}
+	defer res.res.Body.Close()
+	doc, err := res.AsDocument()
+	if err != nil {
+		r.logger.Error("Failed to decode operation output document", slog.String("error", err.Error()), slog.String("operation", operation.operationId))
+		return nil, err
+	}
+	return doc, nil
+}
+
+// executeWithoutInput runs an Automation operation without input, in this repository, and returns its output document.
+func (r *repository) executeWithoutInput(ctx context.Context, operation *operation) (*Document, error) {
+	options := NewNuxeoRequestOptions().SetRepositoryName(r.name)
+	res, err := r.client.OperationManager().Execute(ctx, *operation, options)
+	if err != nil {
+		r.logger.Error("Failed to execute operation", slog.String("error", err.Error()), slog.String("operation", operation.operationId))
+		return nil, err
+	}
+	if res == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoOperationOutput, operation.operationId)
+	}
+	defer res.res.Body.Close()
+	doc, err := res.AsDocument()
+	if err != nil {
+		r.logger.Error("Failed to decode operation output document", slog.String("error", err.Error()), slog.String("operation", operation.operationId))
+		return nil, err
+	}
+	return doc, nil
+}
+
+// executeForDocuments runs an Automation operation with the documents as input, in this repository, and returns its output documents.
+func (r *repository) executeForDocuments(ctx context.Context, operation *operation, documentRefs []string) (*Documents, error) {
+	switch len(documentRefs) {
+	case 0:
+		return &Documents{}, nil
+	case 1:
+		// a single input document is sent as "doc:", for which the server outputs a single document
+		doc, err := r.executeForDocument(ctx, documentRefs[0], operation, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &Documents{Entries: []Document{*doc}}, nil
+	}
+	operation.SetInputDocumentIds(documentRefs...)
+	options := NewNuxeoRequestOptions().SetRepositoryName(r.name)
+	res, err := r.client.OperationManager().Execute(ctx, *operation, options)
+	if err != nil {
+		r.logger.Error("Failed to execute operation on documents", slog.String("error", err.Error()), slog.String("operation", operation.operationId))
+		return nil, err
+	}
+	if res == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoOperationOutput, operation.operationId)
+	}
+	defer res.res.Body.Close()
+	docs, err := res.AsDocumentList()
+	if err != nil {
+		r.logger.Error("Failed to decode operation output documents", slog.String("error", err.Error()), slog.String("operation", operation.operationId))
+		return nil, err
+	}
+	return &docs, nil
+}
+
 // FetchDocumentRoot retrieves the root document of the repository.
 // Maps to GET /api/v1/repo/{repo}/path/
 // Returns the root entityDocument or error.
@@ -118,6 +263,23 @@ func (r *repository) UpdateDocument(ctx context.Context, documentId string, docu
 	return res.Result().(*Document), nil
 }
 
+// updateProperties updates the given properties of a document by its ID, leaving its other properties untouched.
+// Unlike UpdateDocument, no change token or system property is sent along.
+func (r *repository) updateProperties(ctx context.Context, documentId string, properties map[string]Field, options *nuxeoRequestOptions) (*Document, error) {
+	path := internal.PathApiV1 + "/repo/" + url.PathEscape(r.name) + "/id/" + url.PathEscape(documentId)
+	body := map[string]any{
+		"entity-type": EntityTypeDocument,
+		"properties":  properties,
+	}
+	res, err := r.client.NewRequest(ctx, options).SetBody(body).SetResult(&Document{}).SetError(&NuxeoError{}).Put(path)
+
+	if err := handleNuxeoError(err, res); err != nil {
+		r.logger.Error("Failed to update document properties", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return res.Result().(*Document), nil
+}
+
 // DeleteDocument deletes a document by its ID.
 // Maps to DELETE /api/v1/repo/{repo}/id/{id}
 // Returns error if deletion fails.
@@ -160,6 +322,31 @@ func (r *repository) Query(ctx context.Context, query string, queryParams []stri
 	return res.Result().(*Documents), nil
 }
 
+// QueryAll executes a NXQL query and iterates over all matching documents, fetching pages of pageSize lazily.
+// Iteration stops at the first error, which is yielded with a nil document.
+func (r *repository) QueryAll(ctx context.Context, query string, queryParams []string, pageSize int, options *nuxeoRequestOptions) iter.Seq2[*Document, error] {
+	return func(yield func(*Document, error) bool) {
+		for pageIndex := 0; ; pageIndex++ {
+			docs, err := r.Query(ctx, query, queryParams, &SortedPaginationOptions{
+				CurrentPageIndex: pageIndex,
+				PageSize:         pageSize,
+			}, options)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for i := range docs.Entries {
+				if !yield(&docs.Entries[i], nil) {
+					return
+				}
+			}
+			if !docs.IsNextPageAvailable || len(docs.Entries) == 0 {
+				return
+			}
+		}
+	}
+}
+
 // QueryByProvider executes a named query provider against the repository.
 // Maps to GET /api/v1/query/{providerName}
 // Accepts provider name, query parameters, named query parameters, pagination options, and request options.
@@ -285,6 +472,14 @@ func (r *repository) FetchChildrenById(ctx context.Context, parentId string, opt
 	return res.Result().(*Documents), nil
 }
 
+// childrenQuery lists the live children of a document, leaving out versions, proxies and trashed documents.
+const childrenQuery = "SELECT * FROM Document WHERE ecm:parentId = ? AND ecm:isVersion = 0 AND ecm:isProxy = 0 AND ecm:isTrashed = 0 ORDER BY ecm:name"
+
+// allChildren iterates over all live children of the parent document, paging through them lazily.
+func (r *repository) allChildren(ctx context.Context, parentId string, options *nuxeoRequestOptions) iter.Seq2[*Document, error] {
+	return r.QueryAll(ctx, childrenQuery, []string{parentId}, 0, options)
+}
+
 ///////////////
 //// BLOBS ////
 ///////////////
@@ -293,10 +488,10 @@ func (r *repository) FetchChildrenById(ctx context.Context, parentId string, opt
 // Maps to GET /api/v1/repo/{repo}/path/{path}/@blob/{xpath}
 // Returns Blob (stream, filename, mimetype, length) or error.
 func (r *repository) StreamBlobByPath(ctx context.Context, documentPath string, blobXPath string, options *nuxeoRequestOptions) (*blob, error) {
-	path := internal.PathApiV1 + "/repo/" + url.PathEscape(r.name) + "/path" + documentPath + "/@blob/" + url.PathEscape(blobXPath)
-	res, err := r.client.NewRequest(ctx, options).SetError(&NuxeoError{}).Get(path)
+	path := internal.PathApiV1 + "/repo/" + url.PathEscape(r.name) + "/path" + documentPath + "/@blob/" + escapePathSegments(blobXPath)
+	res, err := r.client.NewRequest(ctx, options).SetDoNotParseResponse(true).SetError(&NuxeoError{}).Get(path)
 
-	if err := handleNuxeoError(err, res); err != nil {
+	if err := handleNuxeoStreamError(err, res); err != nil {
 		r.logger.Error("Failed to stream blob by path", slog.String("error", err.Error()))
 		return nil, err
 	}
@@ -312,10 +507,10 @@ func (r *repository) StreamBlobByPath(ctx context.Context, documentPath string,
 // Maps to GET /api/v1/repo/{repo}/id/{id}/@blob/{xpath}
 // Returns Blob (stream, filename, mimetype, length) or error.
 func (r *repository) StreamBlobById(ctx context.Context, documentId string, blobXPath string, options *nuxeoRequestOptions) (*blob, error) {
-	path := internal.PathApiV1 + "/repo/" + url.PathEscape(r.name) + "/id/" + url.PathEscape(documentId) + "/@blob/" + url.PathEscape(blobXPath)
-	res, err := r.client.NewRequest(ctx, options).SetError(&NuxeoError{}).Get(path)
+	path := internal.PathApiV1 + "/repo/" + url.PathEscape(r.name) + "/id/" + url.PathEscape(documentId) + "/@blob/" + escapePathSegments(blobXPath)
+	res, err := r.client.NewRequest(ctx, options).SetDoNotParseResponse(true).SetError(&NuxeoError{}).Get(path)
 
-	if err := handleNuxeoError(err, res); err != nil {
+	if err := handleNuxeoStreamError(err, res); err != nil {
 		r.logger.Error("Failed to stream blob by ID", slog.String("error", err.Error()))
 		return nil, err
 	}
@@ -5,9 +5,11 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"reflect"
 	"testing"
 )
 
@@ -1727,3 +1729,102 @@ func TestRepository_DeleteForAdapter(t *testing.T) {
 		})
 	}
 }
+
+func TestRepository_DocumentRefPath(t *testing.T) {
+	t.Parallel()
+	repo := newTestRepository(nil)
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{ref: "abc-123", want: "/api/v1/repo/default/id/abc-123"},
+		{ref: "/default-domain/workspaces/my ws", want: "/api/v1/repo/default/path/default-domain/workspaces/my%20ws"},
+		{ref: "/", want: "/api/v1/repo/default/path/"},
+	}
+	for _, tt := range tests {
+		if got := repo.documentRefPath(tt.ref); got != tt.want {
+			t.Errorf("documentRefPath(%q) = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestRepository_QueryAll(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		pages   []Documents
+		failAt  int
+		wantIDs []string
+		wantErr bool
+	}{
+		{
+			name: "multiple pages",
+			pages: []Documents{
+				{IsNextPageAvailable: true, Entries: []Document{{ID: "a"}, {ID: "b"}}},
+				{IsNextPageAvailable: false, Entries: []Document{{ID: "c"}}},
+			},
+			failAt:  -1,
+			wantIDs: []string{"a", "b", "c"},
+		},
+		{
+			name: "error on second page",
+			pages: []Documents{
+				{IsNextPageAvailable: true, Entries: []Document{{ID: "a"}}},
+			},
+			failAt:  1,
+			wantIDs: []string{"a"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := newTestRepository(func(req *http.Request) (*http.Response, error) {
+				pageIndex := 0
+				fmt.Sscanf(req.URL.Query().Get("currentPageIndex"), "%d", &pageIndex)
+				if pageIndex == tt.failAt || pageIndex >= len(tt.pages) {
+					return nil, errors.New("network error")
+				}
+				b, _ := json.Marshal(tt.pages[pageIndex])
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewReader(b)),
+					Header:     http.Header{"Content-Type": []string{"application/json"}},
+				}, nil
+			})
+			var gotIDs []string
+			var gotErr error
+			for doc, err := range repo.QueryAll(context.Background(), "SELECT * FROM Document", nil, 2, nil) {
+				if err != nil {
+					gotErr = err
+					break
+				}
+				gotIDs = append(gotIDs, doc.ID)
+			}
+			if (gotErr != nil) != tt.wantErr {
+				t.Errorf("QueryAll() error = %v, wantErr %v", gotErr, tt.wantErr)
+			}
+			if !reflect.DeepEqual(gotIDs, tt.wantIDs) {
+				t.Errorf("QueryAll() ids = %v, want %v", gotIDs, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestRepository_ExecuteNoOutput(t *testing.T) {
+	t.Parallel()
+	repo := newTestRepository(func(req *http.Request) (*http.Response, error) {
+		return testNoContentResponse()
+	})
+	ctx := context.Background()
+
+	if _, err := repo.executeForDocument(ctx, "doc", NewOperation(OperationDocumentGetLastVersion), nil); !errors.Is(err, ErrNoOperationOutput) {
+		t.Errorf("executeForDocument() error = %v, want ErrNoOperationOutput", err)
+	}
+	if _, err := repo.executeWithoutInput(ctx, NewOperation(OperationRepositoryGetDocument)); !errors.Is(err, ErrNoOperationOutput) {
+		t.Errorf("executeWithoutInput() error = %v, want ErrNoOperationOutput", err)
+	}
+	if _, err := repo.executeForDocuments(ctx, NewOperation(OperationDocumentGetLastVersion), []string{"a", "b"}); !errors.Is(err, ErrNoOperationOutput) {
+		t.Errorf("executeForDocuments() error = %v, want ErrNoOperationOutput", err)
+	}
+}
@@ -3,7 +3,9 @@ package nuxeo
 import (
 	"context"
 	"log/slog"
+	"maps"
 	"net/url"
+	"slices"
 
 	"github.com/anselm94/nuxeo-go-client/internal"
 )
@@ -140,6 +142,32 @@ func (um *userManager) FetchGroupMemberGroups(ctx context.Context, idOrGroupName
 	return res.Result().(*Groups), nil
 }
 
+// ResolveGroups resolves all the groups a user belongs to, directly or through the parent groups of its groups,
+// recursively. The Everyone group, which every user implicitly belongs to, is not included.
+// Returns the group names, sorted, or error.
+func (um *userManager) ResolveGroups(ctx context.Context, user *User) ([]string, error) {
+	pending := slices.Clone(user.Groups())
+	for _, group := range user.ExtendedGroups {
+		pending = append(pending, group.Name)
+	}
+	options := NewNuxeoRequestOptions().SetFetchPropertiesForGroup([]string{FetchPropertyGroupParentGroups})
+	resolved := map[string]bool{}
+	for len(pending) > 0 {
+		name := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if resolved[name] {
+			continue
+		}
+		resolved[name] = true
+		group, err := um.FetchGroup(ctx, name, options)
+		if err != nil {
+			return nil, err
+		}
+		pending = append(pending, group.ParentGroups...)
+	}
+	return slices.Sorted(maps.Keys(resolved)), nil
+}
+
 ///////////////
 //// USERS ////
 ///////////////
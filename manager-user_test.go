@@ -8,6 +8,7 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -876,3 +877,50 @@ func TestUserManager_FetchCurrentUser(t *testing.T) {
 		})
 	}
 }
+
+// principalsTestResponder serves the users, and the groups of parents, with their parent groups.
+func principalsTestResponder(t *testing.T, users map[string]*User, parents map[string][]string) func(req *http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		var body any
+		if username, found := strings.CutPrefix(req.URL.EscapedPath(), "/api/v1/user/"); found && users[username] != nil {
+			body = users[username]
+		} else if name, found := strings.CutPrefix(req.URL.EscapedPath(), "/api/v1/group/"); found && parents[name] != nil {
+			if req.Header.Get("fetch-"+EntityTypeGroup) != FetchPropertyGroupParentGroups {
+				t.Errorf("group fetched with %q", req.Header.Get("fetch-"+EntityTypeGroup))
+			}
+			body = map[string]any{"entity-type": "group", "id": name, "parentGroups": parents[name]}
+		} else {
+			return testJsonResponse(t, 404, &NuxeoError{Status: 404, Message: "not found"})
+		}
+		return testJsonResponse(t, 200, body)
+	}
+}
+
+func TestUserManager_ResolveGroups(t *testing.T) {
+	t.Parallel()
+	user := NewUser("jdoe")
+	user.Properties[UserPropertyGroups] = NewStringListField([]string{"editors", "members"})
+	user.ExtendedGroups = []ExtendedGroup{{Name: "members"}, {Name: "europe"}}
+	parents := map[string][]string{
+		"editors": {"contributors"},
+		"members": {},
+		"europe":  {"world"},
+		// cycles are resolved once
+		"contributors": {"editors", "world"},
+		"world":        {},
+	}
+	um := newTestUserManager(principalsTestResponder(t, nil, parents))
+
+	groups, err := um.ResolveGroups(context.Background(), user)
+	if err != nil {
+		t.Fatalf("ResolveGroups() error = %v", err)
+	}
+	if got := strings.Join(groups, ","); got != "contributors,editors,europe,members,world" {
+		t.Errorf("ResolveGroups() = %s", got)
+	}
+
+	user.Properties[UserPropertyGroups] = NewStringListField([]string{"unknown"})
+	if _, err := um.ResolveGroups(context.Background(), user); !hasErrorStatus(err, http.StatusNotFound) {
+		t.Errorf("ResolveGroups() of an unknown group error = %v, want not found", err)
+	}
+}
@@ -0,0 +1,447 @@
+package nuxeo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+///////////////////
+//// MIGRATION ////
+///////////////////
+
+// defaultLifecycleTransitions maps the states of the default lifecycle to the transition reaching them from "project".
+var defaultLifecycleTransitions = map[string]string{
+	"approved": "approve",
+	"obsolete": "obsolete",
+}
+
+// DefaultLifecycleTransitions returns a copy of the map of the states of the default lifecycle to the transition reaching
+// them from "project".
+func DefaultLifecycleTransitions() map[string]string {
+	return maps.Clone(defaultLifecycleTransitions)
+}
+
+// migrationIgnoredProperties are the properties managed by the server, or migrated on their own.
+var migrationIgnoredProperties = []string{"uid:major_version", "uid:minor_version", DocumentPropertyNxtagTags}
+
+// versionsQuery lists the versions of a document, oldest first.
+const versionsQuery = "SELECT * FROM Document WHERE ecm:isVersion = 1 AND ecm:versionVersionableId = ? ORDER BY ecm:versionCreated"
+
+// MigrationOptions configures a content migration between two Nuxeo servers.
+type MigrationOptions struct {
+	// SourceRepository is the repository to migrate from; defaults to "default"
+	SourceRepository string
+	// DestinationRepository is the repository to migrate to; defaults to "default"
+	DestinationRepository string
+	// MapType returns the destination type of a source document; an empty type skips the document and its children.
+	// Defaults to the source type.
+	MapType func(doc *Document) string
+	// MapProperties returns the destination properties of a source document or version, with blobs already uploaded.
+	// Defaults to the source properties.
+	MapProperties func(doc *Document, properties map[string]Field) (map[string]Field, error)
+	// LifecycleTransitions maps lifecycle states to the transition reaching them from the initial state; defaults to DefaultLifecycleTransitions
+	LifecycleTransitions map[string]string
+	// CheckpointFile records the migrated documents, so that a new migration with the same file resumes where it stopped
+	CheckpointFile string
+	// Logger logs the outcome of each document; defaults to the destination client's logger
+	Logger *slog.Logger
+}
+
+// MigrationReport lists the outcome of each document of a migration.
+type MigrationReport struct {
+	Migrated []MigrationReportEntry
+	Skipped  []MigrationReportEntry
+	Failed   []MigrationReportEntry
+}
+
+// MigrationReportEntry describes the outcome of migrating a single document.
+type MigrationReportEntry struct {
+	SourceId        string
+	SourcePath      string
+	DestinationId   string
+	DestinationPath string
+	// Err is the reason of the failure of failed entries
+	Err error
+}
+
+// Err returns the failures of the migration joined into a single error, or nil if all documents were migrated.
+func (r *MigrationReport) Err() error {
+	errs := make([]error, 0, len(r.Failed))
+	for _, entry := range r.Failed {
+		errs = append(errs, fmt.Errorf("%s: %w", entry.SourcePath, entry.Err))
+	}
+	return errors.Join(errs...)
+}
+
+// migrationCheckpoint is the content of a migration checkpoint file.
+type migrationCheckpoint struct {
+	SourceRoot      string                              `json:"sourceRoot"`
+	DestinationRoot string                              `json:"destinationRoot"`
+	Documents       map[string]migrationCheckpointEntry `json:"documents"`
+}
+
+// migrationCheckpointEntry records the destination document of a migrated source document.
+type migrationCheckpointEntry struct {
+	DestinationId   string `json:"uid"`
+	DestinationPath string `json:"path"`
+}
+
+// Migrate copies the document tree rooted at srcRoot on the source server under the dstRoot document of the destination
+// server (both repository paths or document IDs).
+//
+// Each document is recreated with its versions, oldest first, then its live properties, blobs, tags, local ACLs and
+// lifecycle state. Types and properties can be mapped with options.MapType and options.MapProperties.
+//
+// Failures of single documents do not stop the migration: they are logged and listed in the report, and their children
+// are left out. With options.CheckpointFile, documents already migrated by a previous run are skipped; a document whose
+// migration was interrupted before being recorded is migrated again.
+func Migrate(ctx context.Context, src *NuxeoClient, dst *NuxeoClient, srcRoot string, dstRoot string, options *MigrationOptions) (*MigrationReport, error) {
+	if options == nil {
+		options = &MigrationOptions{}
+	}
+	m := &migration{
+		src:         src.RepositoryWithName(RepositoryDefault),
+		dst:         dst.RepositoryWithName(RepositoryDefault),
+		options:     options,
+		logger:      dst.logger,
+		transitions: defaultLifecycleTransitions,
+		report:      &MigrationReport{},
+		checkpoint: &migrationCheckpoint{
+			SourceRoot:      srcRoot,
+			DestinationRoot: dstRoot,
+			Documents:       map[string]migrationCheckpointEntry{},
+		},
+	}
+	if options.SourceRepository != "" {
+		m.src = src.RepositoryWithName(options.SourceRepository)
+	}
+	if options.DestinationRepository != "" {
+		m.dst = dst.RepositoryWithName(options.DestinationRepository)
+	}
+	if options.Logger != nil {
+		m.logger = options.Logger
+	}
+	if options.LifecycleTransitions != nil {
+		m.transitions = options.LifecycleTransitions
+	}
+	if err := m.loadCheckpoint(); err != nil {
+		return nil, err
+	}
+
+	dstParent, err := m.dst.fetchDocument(ctx, dstRoot, nil)
+	if err != nil {
+		return nil, err
+	}
+	requestOptions := NewNuxeoRequestOptions().
+		SetRepositoryName(m.src.name).
+		SetSchemas([]string{"*"}).
+		SetEnricherForDocument([]string{EnricherDocumentACLs})
+	err = m.src.WalkTree(ctx, srcRoot, func(doc *Document, depth int) error {
+		parentId := dstParent.ID
+		if depth > 0 {
+			// children are only visited once their parent is migrated
+			parentId = m.checkpoint.Documents[doc.ParentRef].DestinationId
+		}
+		return m.migrateEntry(ctx, doc, parentId)
+	}, requestOptions)
+	if err != nil {
+		return m.report, err
+	}
+	return m.report, nil
+}
+
+// migration holds the state of a running migration.
+type migration struct {
+	src         *repository
+	dst         *repository
+	options     *MigrationOptions
+	logger      *slog.Logger
+	transitions map[string]string
+	report      *MigrationReport
+	checkpoint  *migrationCheckpoint
+}
+
+// migrateEntry migrates the document under the destination parent, recording its outcome.
+// Returns ErrSkipChildren for skipped and failed documents.
+func (m *migration) migrateEntry(ctx context.Context, doc *Document, parentId string) error {
+	entry := MigrationReportEntry{SourceId: doc.ID, SourcePath: doc.Path}
+	if done, found := m.checkpoint.Documents[doc.ID]; found {
+		entry.DestinationId, entry.DestinationPath = done.DestinationId, done.DestinationPath
+		m.report.Skipped = append(m.report.Skipped, entry)
+		m.logger.Info("Skipping document migrated by a previous run", slog.String("source", doc.Path), slog.String("destination", done.DestinationPath))
+		return nil
+	}
+	dstType := doc.Type
+	if m.options.MapType != nil {
+		dstType = m.options.MapType(doc)
+	}
+	if dstType == "" {
+		m.report.Skipped = append(m.report.Skipped, entry)
+		m.logger.Info("Skipping unmapped document type", slog.String("source", doc.Path), slog.String("type", doc.Type))
+		return ErrSkipChildren
+	}
+
+	migrated, err := m.migrateDocument(ctx, doc, parentId, dstType)
+	if err != nil {
+		entry.Err = err
+		m.report.Failed = append(m.report.Failed, entry)
+		m.logger.Error("Failed to migrate document", slog.String("error", err.Error()), slog.String("source", doc.Path))
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return ErrSkipChildren
+	}
+	entry.DestinationId, entry.DestinationPath = migrated.ID, migrated.Path
+	m.checkpoint.Documents[doc.ID] = migrationCheckpointEntry{DestinationId: migrated.ID, DestinationPath: migrated.Path}
+	if err := m.saveCheckpoint(); err != nil {
+		return err
+	}
+	m.report.Migrated = append(m.report.Migrated, entry)
+	m.logger.Info("Migrated document", slog.String("source", doc.Path), slog.String("destination", migrated.Path))
+	return nil
+}
+
+// migrateDocument recreates the versions, then the live state of the document under the destination parent.
+func (m *migration) migrateDocument(ctx context.Context, doc *Document, parentId string, dstType string) (*Document, error) {
+	states := []*Document{}
+	versionOptions := NewNuxeoRequestOptions().SetRepositoryName(m.src.name).SetSchemas([]string{"*"})
+	for version, err := range m.src.QueryAll(ctx, versionsQuery, []string{doc.ID}, 0, versionOptions) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to list versions: %w", err)
+		}
+		states = append(states, version)
+	}
+	states = append(states, doc)
+
+	batch := &migrationBatch{repo: m.dst}
+	defer batch.cancel(ctx)
+
+	var migrated *Document
+	previousMajor := "0"
+	for _, state := range states {
+		properties, err := m.properties(ctx, state, batch)
+		if err != nil {
+			return nil, err
+		}
+		if migrated == nil {
+			migrated, err = m.dst.CreateDocumentById(ctx, parentId, Document{
+				entity:     entity{EntityType: EntityTypeDocument},
+				Type:       dstType,
+				Name:       ioDocumentName(doc),
+				Properties: properties,
+			}, nil)
+		} else {
+			migrated, err = m.dst.updateProperties(ctx, migrated.ID, properties, nil)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !state.IsVersion {
+			break
+		}
+		// a new major version is checked in whenever the major part of the label changes
+		increment := VersioningOptionMinor
+		if major, _, _ := strings.Cut(state.VersionLabel, "."); major != previousMajor {
+			increment = VersioningOptionMajor
+			previousMajor = major
+		}
+		if err := m.dst.executeOnDocument(ctx, migrated.ID, NewOperation(OperationDocumentCheckIn).SetParam("version", string(increment))); err != nil {
+			return nil, fmt.Errorf("failed to check in version %s: %w", state.VersionLabel, err)
+		}
+	}
+
+	if err := m.migrateTags(ctx, doc, migrated.ID); err != nil {
+		return nil, err
+	}
+	if field, found := doc.ContextParameter(EnricherDocumentACLs); found {
+		var acls []ACL
+		if err := field.ComplexList(&acls); err == nil {
+			if err := m.dst.applyACLs(ctx, migrated.ID, acls); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := m.migrateLifecycleState(ctx, doc, migrated); err != nil {
+		return nil, err
+	}
+	return migrated, nil
+}
+
+// properties returns the destination properties of a source document or version, uploading its blobs into the batch.
+func (m *migration) properties(ctx context.Context, state *Document, batch *migrationBatch) (map[string]Field, error) {
+	properties := make(map[string]Field, len(state.Properties))
+	for key, field := range state.Properties {
+		if slices.Contains(migrationIgnoredProperties, key) || field.IsNull() {
+			continue
+		}
+		var value any
+		decoder := json.NewDecoder(bytes.NewReader(field))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return nil, fmt.Errorf("failed to decode property %s: %w", key, err)
+		}
+		value, err := m.copyBlobs(ctx, state, value, key, batch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy blobs of %s: %w", key, err)
+		}
+		if properties[key], err = NewField(value); err != nil {
+			return nil, err
+		}
+	}
+	if m.options.MapProperties != nil {
+		return m.options.MapProperties(state, properties)
+	}
+	return properties, nil
+}
+
+// copyBlobs streams the blobs held by the property value at xpath into the destination batch, replacing them with their upload information.
+func (m *migration) copyBlobs(ctx context.Context, state *Document, value any, xpath string, batch *migrationBatch) (any, error) {
+	switch v := value.(type) {
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			copied, err := m.copyBlobs(ctx, state, item, xpath+"/"+strconv.Itoa(i), batch)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = copied
+		}
+		return items, nil
+	case map[string]any:
+		if isBlobMap(v) {
+			return m.copyBlob(ctx, state, xpath, batch)
+		}
+		fields := make(map[string]any, len(v))
+		for key, item := range v {
+			copied, err := m.copyBlobs(ctx, state, item, xpath+"/"+key, batch)
+			if err != nil {
+				return nil, err
+			}
+			fields[key] = copied
+		}
+		return fields, nil
+	}
+	return value, nil
+}
+
+// copyBlob streams a single blob from the source document into the destination batch.
+func (m *migration) copyBlob(ctx context.Context, state *Document, xpath string, batch *migrationBatch) (any, error) {
+	stream, err := m.src.StreamBlobById(ctx, state.ID, xpath, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	blob := stream
+	if stream.Size() < 0 {
+		// uploads need the blob length up front
+		if blob, err = NewBlobFromReader(stream.Filename, stream, &BlobReaderOptions{MimeType: stream.MimeType, SpoolToTempFile: true}); err != nil {
+			return nil, err
+		}
+		defer blob.Close()
+	}
+	return batch.upload(ctx, blob)
+}
+
+// migrateTags applies the tags of the source document to the destination document.
+func (m *migration) migrateTags(ctx context.Context, doc *Document, documentId string) error {
+	labels := documentTags(doc)
+	if len(labels) == 0 {
+		return nil
+	}
+	return m.dst.executeOnDocument(ctx, documentId, NewOperation(OperationServicesTagDocument).SetParam("tags", strings.Join(labels, ",")))
+}
+
+// migrateLifecycleState follows the transition reaching the lifecycle state of the source document, if any.
+func (m *migration) migrateLifecycleState(ctx context.Context, doc *Document, migrated *Document) error {
+	if doc.State == "" || doc.State == migrated.State {
+		return nil
+	}
+	transition, found := m.transitions[doc.State]
+	if !found {
+		m.logger.Warn("No lifecycle transition to migrate document state", slog.String("source", doc.Path), slog.String("state", doc.State))
+		return nil
+	}
+	return m.dst.executeOnDocument(ctx, migrated.ID, NewOperation(OperationDocumentFollowTransition).SetParam("value", transition))
+}
+
+// loadCheckpoint reads the checkpoint file, if any.
+func (m *migration) loadCheckpoint() error {
+	if m.options.CheckpointFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(m.options.CheckpointFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	checkpoint := &migrationCheckpoint{}
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return fmt.Errorf("failed to decode checkpoint %s: %w", m.options.CheckpointFile, err)
+	}
+	if checkpoint.SourceRoot != m.checkpoint.SourceRoot || checkpoint.DestinationRoot != m.checkpoint.DestinationRoot {
+		return fmt.Errorf("checkpoint %s belongs to the migration of %s to %s", m.options.CheckpointFile, checkpoint.SourceRoot, checkpoint.DestinationRoot)
+	}
+	if checkpoint.Documents != nil {
+		m.checkpoint.Documents = checkpoint.Documents
+	}
+	return nil
+}
+
+// saveCheckpoint atomically rewrites the checkpoint file, if any.
+func (m *migration) saveCheckpoint() error {
+	if m.options.CheckpointFile == "" {
+		return nil
+	}
+	data, err := json.Marshal(m.checkpoint)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(m.options.CheckpointFile, data)
+}
+
+// migrationBatch is the destination batch holding the blobs of a migrated document, created on first upload.
+type migrationBatch struct {
+	repo        *repository
+	batch       *batchUpload
+	nextFileIdx int
+}
+
+// upload uploads the blob into the batch, returning its upload information.
+func (b *migrationBatch) upload(ctx context.Context, blob *blob) (UploadInfo, error) {
+	batchUploadManager := b.repo.client.BatchUploadManager()
+	if b.batch == nil {
+		batch, err := batchUploadManager.CreateBatch(ctx, nil)
+		if err != nil {
+			return UploadInfo{}, err
+		}
+		b.batch = batch
+	}
+	fileIdx := b.nextFileIdx
+	b.nextFileIdx++
+	upload, err := batchUploadManager.UploadBlob(ctx, b.batch, fileIdx, blob, nil, nil)
+	if err != nil {
+		return UploadInfo{}, err
+	}
+	return UploadInfo{Batch: b.batch.BatchId, FileId: uploadedFileIdx(upload, fileIdx)}, nil
+}
+
+// cancel drops the batch, if created.
+func (b *migrationBatch) cancel(ctx context.Context) {
+	if b.batch == nil {
+		return
+	}
+	if err := b.repo.client.BatchUploadManager().CancelBatch(context.WithoutCancel(ctx), b.batch.BatchId, nil); err != nil {
+		b.repo.logger.Warn("Failed to clean up migration batch", slog.String("error", err.Error()), slog.String("batchId", b.batch.BatchId))
+	}
+}
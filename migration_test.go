@@ -0,0 +1,184 @@
+package nuxeo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// migrationTestSource serves the tree /src > /src/doc, where doc is an approved, tagged File with a 1.0 version.
+func migrationTestSource(t *testing.T) func(req *http.Request) (*http.Response, error) {
+	document := func(uid string, title string, isVersion bool) map[string]any {
+		return map[string]any{
+			"entity-type":  "document",
+			"uid":          uid,
+			"path":         "/src/doc",
+			"parentRef":    "src",
+			"type":         DocumentTypeFile,
+			"state":        "approved",
+			"isVersion":    isVersion,
+			"versionLabel": "1.0",
+			"properties": map[string]any{
+				"dc:title":          title,
+				"uid:major_version": 1,
+				"file:content":      map[string]any{"name": "doc.txt", "mime-type": "text/plain", "digest": uid},
+				"nxtag:tags":        []any{map[string]any{"label": "art", "username": "jdoe"}, map[string]any{"label": "music", "username": "jdoe"}},
+			},
+			"contextParameters": map[string]any{
+				"acls": []any{map[string]any{"name": "local", "aces": []any{map[string]any{"username": "jdoe", "permission": "Read", "granted": true}}}},
+			},
+		}
+	}
+
+	return func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.URL.EscapedPath() == "/api/v1/repo/default/path/src":
+			return testJsonResponse(t, 200, map[string]any{"entity-type": "document", "uid": "src", "path": "/src", "type": DocumentTypeFolder, "facets": []string{"Folderish"}})
+		case req.URL.EscapedPath() == "/api/v1/query" && strings.Contains(req.URL.Query().Get("query"), "ecm:isVersion = 1") && req.URL.Query().Get("queryParams") == "doc":
+			return testJsonResponse(t, 200, map[string]any{"entity-type": "documents", "entries": []any{document("doc-v1", "Draft", true)}})
+		case req.URL.EscapedPath() == "/api/v1/query" && !strings.Contains(req.URL.Query().Get("query"), "ecm:isVersion = 1") && req.URL.Query().Get("queryParams") == "src":
+			return testJsonResponse(t, 200, map[string]any{"entity-type": "documents", "entries": []any{document("doc", "Final", false)}})
+		case req.URL.EscapedPath() == "/api/v1/query":
+			return testJsonResponse(t, 200, map[string]any{"entity-type": "documents", "entries": []any{}})
+		case strings.Contains(req.URL.EscapedPath(), "/@blob/"):
+			return &http.Response{
+				StatusCode: 200,
+				Body:       io.NopCloser(strings.NewReader("content of " + strings.Split(req.URL.EscapedPath(), "/")[6])),
+				Header:     http.Header{"Content-Type": []string{"text/plain"}},
+			}, nil
+		}
+		return nil, errors.New("unexpected request " + req.URL.String())
+	}
+}
+
+// migrationTestDestination records the requests creating, updating and operating on documents under /dst.
+type migrationTestDestination struct {
+	t          *testing.T
+	mu         sync.Mutex
+	requests   []string
+	uploads    []string
+	operations []string
+}
+
+func (d *migrationTestDestination) respond(req *http.Request) (*http.Response, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	switch {
+	case req.Method == http.MethodGet && req.URL.EscapedPath() == "/api/v1/repo/default/path/dst":
+		return testJsonResponse(d.t, 200, map[string]any{"entity-type": "document", "uid": "dst", "path": "/dst"})
+	case req.URL.EscapedPath() == "/api/v1/upload/new/default":
+		return testJsonResponse(d.t, 200, map[string]any{"batchId": "batch1"})
+	case req.Method == http.MethodDelete:
+		return testJsonResponse(d.t, 200, map[string]any{})
+	case strings.HasPrefix(req.URL.EscapedPath(), "/api/v1/upload/batch1/"):
+		content, _ := io.ReadAll(req.Body)
+		d.uploads = append(d.uploads, string(content))
+		return testJsonResponse(d.t, 200, map[string]any{"batchId": "batch1", "fileIdx": path.Base(req.URL.EscapedPath())})
+	case strings.Contains(req.URL.EscapedPath(), "/automation/"):
+		var body struct {
+			Params map[string]any `json:"params"`
+		}
+		json.NewDecoder(req.Body).Decode(&body)
+		params, _ := json.Marshal(body.Params)
+		d.operations = append(d.operations, path.Base(req.URL.EscapedPath())+string(params))
+		return testNoContentResponse()
+	case req.Method == http.MethodPost || req.Method == http.MethodPut:
+		var body struct {
+			Name       string         `json:"name"`
+			Type       string         `json:"type"`
+			Properties map[string]any `json:"properties"`
+		}
+		json.NewDecoder(req.Body).Decode(&body)
+		content, _ := body.Properties["file:content"].(map[string]any)
+		d.requests = append(d.requests, fmt.Sprintf("%s %s %s %v %v", req.Method, body.Type, body.Name, body.Properties["dc:title"], content["upload-batch"]))
+		if _, found := body.Properties["uid:major_version"]; found {
+			d.t.Errorf("server managed property sent: %v", body.Properties)
+		}
+		return testJsonResponse(d.t, 200, map[string]any{"entity-type": "document", "uid": "new-doc", "path": "/dst/doc", "state": "project"})
+	}
+	return nil, errors.New("unexpected request " + req.Method + " " + req.URL.EscapedPath())
+}
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+	dst := &migrationTestDestination{t: t}
+	src := newMockNuxeoClient(migrationTestSource(t))
+	checkpoint := filepath.Join(t.TempDir(), "checkpoint.json")
+	options := &MigrationOptions{CheckpointFile: checkpoint}
+
+	report, err := Migrate(context.Background(), src, newMockNuxeoClient(dst.respond), "/src", "/dst", options)
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if err := report.Err(); err != nil {
+		t.Fatalf("unexpected failures: %v", err)
+	}
+	if len(report.Migrated) != 2 || report.Migrated[1].SourceId != "doc" || report.Migrated[1].DestinationId != "new-doc" {
+		t.Errorf("unexpected migrated entries: %+v", report.Migrated)
+	}
+	wantRequests := []string{
+		"POST Folder src <nil> <nil>",
+		"POST File doc Draft batch1",
+		"PUT   Final batch1",
+	}
+	if !slices.Equal(dst.requests, wantRequests) {
+		t.Errorf("requests: got %q, want %q", dst.requests, wantRequests)
+	}
+	if !slices.Equal(dst.uploads, []string{"content of doc-v1", "content of doc"}) {
+		t.Errorf("unexpected uploads: %q", dst.uploads)
+	}
+	wantOperations := []string{
+		OperationDocumentCheckIn + `{"version":"major"}`,
+		OperationServicesTagDocument + `{"tags":"art,music"}`,
+		OperationDocumentAddPermission + `{"acl":"local","permission":"Read","username":"jdoe"}`,
+		OperationDocumentFollowTransition + `{"value":"approve"}`,
+	}
+	if !slices.Equal(dst.operations, wantOperations) {
+		t.Errorf("operations: got %q, want %q", dst.operations, wantOperations)
+	}
+
+	// a second run resumes from the checkpoint
+	dst.requests = nil
+	report, err = Migrate(context.Background(), src, newMockNuxeoClient(dst.respond), "/src", "/dst", options)
+	if err != nil {
+		t.Fatalf("Migrate() resume error = %v", err)
+	}
+	if len(report.Migrated) != 0 || len(report.Skipped) != 2 || len(dst.requests) != 0 {
+		t.Errorf("unexpected resumed migration: %+v, requests %q", report, dst.requests)
+	}
+
+	if _, err := Migrate(context.Background(), src, newMockNuxeoClient(dst.respond), "/src", "/other", options); err == nil {
+		t.Error("Migrate() with a checkpoint of another migration should fail")
+	}
+}
+
+func TestMigrate_MapType(t *testing.T) {
+	t.Parallel()
+	dst := &migrationTestDestination{t: t}
+	report, err := Migrate(context.Background(), newMockNuxeoClient(migrationTestSource(t)), newMockNuxeoClient(dst.respond), "/src", "/dst", &MigrationOptions{
+		MapType: func(doc *Document) string {
+			if doc.Type == DocumentTypeFolder {
+				return DocumentTypeWorkspace
+			}
+			return ""
+		},
+	})
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if len(report.Migrated) != 1 || len(report.Skipped) != 1 || report.Skipped[0].SourceId != "doc" {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if !slices.Equal(dst.requests, []string{"POST Workspace src <nil> <nil>"}) {
+		t.Errorf("unexpected requests: %q", dst.requests)
+	}
+}
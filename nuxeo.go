@@ -203,6 +203,7 @@ func (c *NuxeoClient) RepositoryWithName(name string) *repository {
 	return &repository{
 		name:   name,
 		client: c,
+		logger: c.logger,
 	}
 }
 
@@ -82,6 +82,11 @@ func (o *nuxeoRequestOptions) SetFetchProperties(entityType string, values []str
 	return o
 }
 
+// SetFetchPropertiesForComment sets fetch properties for comment entities.
+func (o *nuxeoRequestOptions) SetFetchPropertiesForComment(values []string) *nuxeoRequestOptions {
+	return o.SetFetchProperties("comment", values)
+}
+
 // SetFetchPropertiesForDirectory sets fetch properties for directory entities.
 func (o *nuxeoRequestOptions) SetFetchPropertiesForDirectory(values []string) *nuxeoRequestOptions {
 	return o.SetFetchProperties("directory", values)
@@ -136,6 +141,11 @@ func (o *nuxeoRequestOptions) SetVersion(version string) *nuxeoRequestOptions {
 	return o
 }
 
+// SetVersioningOption sets the versioning option applied when the request saves a document.
+func (o *nuxeoRequestOptions) SetVersioningOption(option VersioningOption) *nuxeoRequestOptions {
+	return o.SetVersion(string(option))
+}
+
 ///////////////////////
 //// NUXEO REQUEST ////
 ///////////////////////
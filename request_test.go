@@ -98,6 +98,15 @@ func TestNuxeoRequestOptions_Setters(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:  "SetVersioningOption",
+			setup: func(o *nuxeoRequestOptions) { o.SetVersioningOption(VersioningOptionMinor) },
+			check: func(o *nuxeoRequestOptions, t *testing.T) {
+				if o.version != "minor" {
+					t.Errorf("Expected version='minor', got '%s'", o.version)
+				}
+			},
+		},
 		{
 			name:  "SetTransactionTimeout",
 			setup: func(o *nuxeoRequestOptions) { o.SetTransactionTimeout(42) },
@@ -42,6 +42,25 @@ func testMarshalBody(t *testing.T, v any) io.ReadCloser {
 	return io.NopCloser(bytes.NewReader(b))
 }
 
+// testJsonResponse returns a response of the status code with the value marshalled as JSON body.
+func testJsonResponse(t *testing.T, status int, v any) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: status,
+		Body:       testMarshalBody(t, v),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+	}, nil
+}
+
+// testNotFoundResponse returns the 404 response of the server for an unknown entity.
+func testNotFoundResponse(t *testing.T) (*http.Response, error) {
+	return testJsonResponse(t, http.StatusNotFound, map[string]any{"entity-type": "exception", "status": http.StatusNotFound, "message": "not found"})
+}
+
+// testNoContentResponse returns a 204 response, as sent by the server for void operations and null outputs.
+func testNoContentResponse() (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody, Header: http.Header{}}, nil
+}
+
 func mockRestyResponse(statusCode int, headers http.Header, body io.ReadCloser) *resty.Response {
 	return &resty.Response{
 		Body: body,